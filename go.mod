@@ -4,7 +4,9 @@ go 1.24.3
 
 require (
 	connectrpc.com/connect v1.18.1
+	github.com/google/uuid v1.6.0
 	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.38.0
 	golang.org/x/net v0.40.0
 	google.golang.org/grpc v1.72.2
 	google.golang.org/protobuf v1.36.6
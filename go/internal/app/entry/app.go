@@ -2,41 +2,890 @@ package entry
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"moss/go/internal/auth"
+	"moss/go/internal/diff"
+	"moss/go/internal/excerpt"
 	models "moss/go/internal/models/entry"
+	linkModels "moss/go/internal/models/link"
+	"moss/go/internal/pagetoken"
 	entryRepo "moss/go/internal/repository/entry"
+	"moss/go/internal/tagexpr"
+	"moss/go/internal/tsquery"
+	"moss/go/internal/wikilink"
 )
 
 var (
-	ErrInvalidEntry = errors.New("invalid entry")
-	ErrUnauthorized = errors.New("unauthorized access")
+	ErrInvalidEntry          = errors.New("invalid entry")
+	ErrUnauthorized          = errors.New("unauthorized access")
+	ErrEntryNotFound         = entryRepo.ErrEntryNotFound // Also returned for entries in the trash
+	ErrEntryIDTaken          = errors.New("entry ID already in use")
+	ErrInvalidIdempotencyKey = errors.New("idempotency key must be at most 255 characters")
+	ErrInvalidPageToken      = errors.New("invalid page token")
+	ErrInvalidPageSize       = errors.New("page size must not be negative")
+	ErrInvalidTagExpression  = errors.New("invalid tag expression")
+	ErrInvalidSearchQuery    = errors.New("search query has no words")
+	ErrInvalidLimit          = errors.New("limit must not be negative")
+	ErrRevisionNotFound      = errors.New("revision not found")
+	ErrNotInTrash            = errors.New("entry is not in the trash")
+	ErrInvalidDeletionPolicy = errors.New("invalid deletion policy")
+	ErrMentionNotFound       = errors.New("no unlinked mention at that position")
+	ErrInvalidGrowthStage    = errors.New("invalid growth stage")
+	ErrSameGrowthStage       = errors.New("entry is already at that growth stage")
+	ErrInvalidReason         = errors.New("reason must be at most 1000 characters")
+)
+
+// VersionConflictError is returned by UpdateEntry when the expected version is stale.
+// Current is the entry as stored, for clients to merge against.
+type VersionConflictError struct {
+	Current *models.Entry
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("entry was modified concurrently: current version is %d", e.Current.Version)
+}
+
+// NameTakenError is returned by CreateEntry, UpdateEntry and RestoreEntry when
+// the entry's title is already another entry's alias, or one of its aliases is
+// already another entry's title or alias.
+type NameTakenError struct {
+	Name    string // The title or alias as given; empty if not known
+	EntryID string // The entry that already has the name; empty if not known
+}
+
+func (e *NameTakenError) Error() string {
+	if e.Name == "" {
+		return "title or alias is already used by another entry"
+	}
+	return fmt.Sprintf("%q is already the title or an alias of entry %s", e.Name, e.EntryID)
+}
+
+// TransitionError is returned when an entry's growth stage would change in a
+// way the lifecycle does not allow. Allowed lists the stages it may move to.
+type TransitionError struct {
+	From    models.GrowthStage
+	To      models.GrowthStage
+	Allowed []models.GrowthStage
+}
+
+func (e *TransitionError) Error() string {
+	allowed := make([]string, len(e.Allowed))
+	for i, stage := range e.Allowed {
+		allowed[i] = string(stage)
+	}
+	if len(allowed) == 0 {
+		return fmt.Sprintf("entry cannot move from %s to %s: %s is final", e.From, e.To, e.From)
+	}
+	return fmt.Sprintf("entry cannot move from %s to %s, only to %s", e.From, e.To, strings.Join(allowed, " or "))
+}
+
+// BacklinksError is returned by DeleteEntry under DeletionPolicyReject when other
+// entries still link to the entry. Backlinks are the links blocking the deletion.
+type BacklinksError struct {
+	Backlinks []*linkModels.Link
+}
+
+func (e *BacklinksError) Error() string {
+	return fmt.Sprintf("entry is linked from %d other entries", len(e.Backlinks))
+}
+
+const (
+	maxIdempotencyKeyLength = 255
+	maxReasonLength         = 1000
+
+	defaultPageSize = 50
+	maxPageSize     = 200
+
+	defaultSuggestionLimit = 10
+	maxSuggestionLimit     = 50
 )
 
+// App methods act on behalf of the Principal carried in ctx (see package auth).
 type App interface {
-	CreateEntry(ctx context.Context, entry *models.Entry) (*models.Entry, error)
-	GetEntry(ctx context.Context, id string, userID string) (*models.Entry, error)
-	UpdateEntry(ctx context.Context, entry *models.Entry) (*models.Entry, error)
-	DeleteEntry(ctx context.Context, id string) error
-	ListEntries(ctx context.Context, userID string) ([]*models.Entry, error)
+	// CreateEntry creates an entry, generating its ID unless the client chose one.
+	// Retrying with the same client-chosen ID or idempotencyKey returns the
+	// original entry instead of creating a duplicate.
+	// Wiki-links in the content to titles that do not exist yet are kept as
+	// pending links, or, with createPlaceholders, get empty seed entries.
+	// Aliases resolve wiki-links like the title; a *NameTakenError is returned
+	// if the title or an alias is already another entry's name.
+	CreateEntry(ctx context.Context, entry *models.Entry, idempotencyKey string, createPlaceholders bool) (*models.Entry, error)
+	GetEntry(ctx context.Context, id string) (*models.Entry, error)
+	// UpdateEntry overwrites an entry's title, content and aliases. Its growth stage is
+	// kept whatever entry.GrowthStage says: stages change through PromoteEntry or a
+	// PatchEntry that sets one. If entry.Version is non-zero it must match the stored
+	// version, or a *VersionConflictError carrying the stored entry is returned.
+	// Names are checked as in CreateEntry.
+	UpdateEntry(ctx context.Context, entry *models.Entry, createPlaceholders bool) (*models.Entry, error)
+	// PatchEntry updates only the fields set in patch, with the same version check as UpdateEntry.
+	PatchEntry(ctx context.Context, id string, patch models.Patch, createPlaceholders bool) (*models.Entry, error)
+	// DeleteEntry moves an entry to the trash, from which it can be restored
+	// until it is purged, and returns the links removed under policy. An empty
+	// policy means DeletionPolicyKeep. Under DeletionPolicyReject a *BacklinksError
+	// is returned while other entries link to it.
+	DeleteEntry(ctx context.Context, id string, policy models.DeletionPolicy) ([]*linkModels.Link, error)
+	ListTrash(ctx context.Context) ([]*models.Entry, error)
+	// RestoreEntry takes an entry out of the trash. A *NameTakenError is returned
+	// if another entry has meanwhile taken its title or one of its aliases.
+	RestoreEntry(ctx context.Context, id string) (*models.Entry, error)
+	// PurgeEntry permanently deletes an entry that is in the trash.
+	PurgeEntry(ctx context.Context, id string) error
+	// ListEntries returns one page of the caller's entries. pageToken must be empty
+	// or a token returned by a previous call with the same filter and sort order.
+	ListEntries(ctx context.Context, q models.ListQuery, pageToken string) ([]*models.Entry, string, error)
+	// SearchEntries returns one page of the caller's entries matching a full-text
+	// search, best match first. pageToken must be empty or a token returned by a
+	// previous call with the same query and filters.
+	SearchEntries(ctx context.Context, q models.SearchQuery, pageToken string) ([]*models.SearchResult, string, error)
+	// SuggestEntries returns up to limit of the caller's entries whose titles or
+	// aliases closely match query, ranked by similarity, recency of edit and inbound links.
+	SuggestEntries(ctx context.Context, query string, limit int) ([]*models.Suggestion, error)
+	// ListBacklinks returns one page of the other entries linking to an entry,
+	// oldest first, with excerpts around each wiki-link to it. pageToken must be
+	// empty or a token returned by a previous call for the same entry.
+	ListBacklinks(ctx context.Context, id string, pageSize int, pageToken string) ([]*models.Backlink, string, error)
+	// ListUnlinkedMentions returns the plain-text mentions of an entry's title or
+	// aliases in up to pageSize other entries that do not link to it yet, most
+	// recently updated first. pageToken must be empty or a token returned by a
+	// previous call for the same entry.
+	ListUnlinkedMentions(ctx context.Context, id string, pageSize int, pageToken string) ([]*models.Mention, string, error)
+	// LinkMention turns the unlinked mention of targetID starting at byte start
+	// of sourceID's content into a wiki-link, linking the entries. If version is
+	// non-zero it must match the source's version, as in UpdateEntry.
+	LinkMention(ctx context.Context, targetID, sourceID string, start int, version int64) (*models.Entry, error)
+	// ListRevisions returns an entry's revisions, newest first.
+	ListRevisions(ctx context.Context, entryID string) ([]*models.Revision, error)
+	GetRevision(ctx context.Context, entryID string, number int32) (*models.Revision, error)
+	// DiffRevisions returns a unified diff of the content from one revision to another.
+	DiffRevisions(ctx context.Context, entryID string, from int32, to int32) (string, error)
+	// RestoreRevision writes a revision's title, content and growth stage back to
+	// the entry. This appends a new revision; later revisions are kept.
+	RestoreRevision(ctx context.Context, entryID string, number int32) (*models.Entry, error)
+
+	// PromoteEntry moves an entry to another growth stage and records who moved
+	// it, when and why. If version is non-zero it must match the entry's
+	// version, as in UpdateEntry. PatchEntry and RestoreRevision may change the
+	// stage too, under the same lifecycle rules; a move the lifecycle does not
+	// allow returns a *TransitionError.
+	PromoteEntry(ctx context.Context, id string, stage models.GrowthStage, reason string, version int64) (*models.Entry, *models.StageTransition, error)
+	// ListStageTransitions returns an entry's growth stage changes, newest first.
+	ListStageTransitions(ctx context.Context, entryID string) ([]*models.StageTransition, error)
 }
 
 type app struct {
-	repo entryRepo.Repository
+	repo       entryRepo.Repository
+	pageTokens *pagetoken.Codec
+	lifecycle  *models.Lifecycle
 }
 
-func NewApp(repo entryRepo.Repository) App {
-	return &app{repo: repo}
+// NewApp returns an App whose entries change growth stage as lifecycle allows.
+func NewApp(repo entryRepo.Repository, pageTokens *pagetoken.Codec, lifecycle *models.Lifecycle) App {
+	return &app{repo: repo, pageTokens: pageTokens, lifecycle: lifecycle}
 }
 
-func (a *app) CreateEntry(ctx context.Context, entry *models.Entry) (*models.Entry, error) {
+// pageState is what a ListEntries page token carries.
+type pageState struct {
+	Query  string         `json:"q"` // fingerprint of the user, filter and sort the token belongs to
+	Cursor *models.Cursor `json:"c"`
+}
+
+// backlinkPageState is what a ListBacklinks page token carries.
+type backlinkPageState struct {
+	Entry  string `json:"e"`
+	Offset int    `json:"o"`
+}
+
+// mentionPageState is what a ListUnlinkedMentions page token carries.
+type mentionPageState struct {
+	Entry  string `json:"e"`
+	Offset int    `json:"m"`
+}
+
+// searchPageState is what a SearchEntries page token carries.
+type searchPageState struct {
+	Query  string               `json:"q"` // fingerprint of the user, search text and filters the token belongs to
+	Cursor *models.SearchCursor `json:"s"`
+}
+
+func (a *app) CreateEntry(ctx context.Context, entry *models.Entry, idempotencyKey string, createPlaceholders bool) (*models.Entry, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.UserID = userID
+
 	if err := entry.Validate(); err != nil {
 		return nil, ErrInvalidEntry
 	}
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	if entry.ID == "" {
+		// UUIDv7 IDs sort by creation time.
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		entry.ID = id.String()
+	} else if _, err := uuid.Parse(entry.ID); err != nil {
+		return nil, ErrInvalidEntry
+	}
+
+	created, err := a.repo.Create(ctx, entry, idempotencyKey, createPlaceholders)
+	if err := nameTaken(err); err != nil {
+		return nil, err
+	}
+	if errors.Is(err, entryRepo.ErrEntryExists) {
+		// A client-chosen ID that already exists is a retry if the caller owns it.
+		existing, err := a.repo.GetByID(ctx, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing.UserID != userID {
+			return nil, ErrEntryIDTaken
+		}
+		return a.withLinkCounts(ctx, existing, nil)
+	}
+	return a.withLinkCounts(ctx, created, err)
+}
+
+func (a *app) GetEntry(ctx context.Context, id string) (*models.Entry, error) {
+	entry, err := a.getOwnedEntry(ctx, id)
+	return a.withLinkCounts(ctx, entry, err)
+}
+
+func (a *app) UpdateEntry(ctx context.Context, entry *models.Entry, createPlaceholders bool) (*models.Entry, error) {
+	aliases := entry.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return a.PatchEntry(ctx, entry.ID, models.Patch{
+		Title:   &entry.Title,
+		Content: &entry.Content,
+		Aliases: &aliases,
+		Version: entry.Version,
+	}, createPlaceholders)
+}
+
+func (a *app) PatchEntry(ctx context.Context, id string, patch models.Patch, createPlaceholders bool) (*models.Entry, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, ErrInvalidEntry
+	}
+
+	existing, err := a.getOwnedEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var transition *models.StageTransition
+	if patch.GrowthStage != nil {
+		if transition, err = a.stageTransition(ctx, existing, *patch.GrowthStage, ""); err != nil {
+			return nil, err
+		}
+		if transition != nil {
+			if patch.Version != 0 && patch.Version != existing.Version {
+				return nil, a.versionConflict(ctx, id)
+			}
+			patch.Version = existing.Version
+		}
+	}
+
+	patched, err := a.repo.Patch(ctx, id, patch, createPlaceholders, transition)
+	if errors.Is(err, entryRepo.ErrVersionConflict) {
+		return nil, a.versionConflict(ctx, id)
+	}
+	if err := nameTaken(err); err != nil {
+		return nil, err
+	}
+	return a.withLinkCounts(ctx, patched, err)
+}
+
+func (a *app) DeleteEntry(ctx context.Context, id string, policy models.DeletionPolicy) ([]*linkModels.Link, error) {
+	switch policy {
+	case "":
+		policy = models.DeletionPolicyKeep
+	case models.DeletionPolicyKeep, models.DeletionPolicyCascade, models.DeletionPolicyReject, models.DeletionPolicyRewrite:
+	default:
+		return nil, ErrInvalidDeletionPolicy
+	}
+
+	if _, err := a.getOwnedEntry(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if policy == models.DeletionPolicyReject {
+		backlinks, err := a.repo.ListBacklinks(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(backlinks) > 0 {
+			return nil, &BacklinksError{Backlinks: backlinks}
+		}
+	}
+
+	return a.repo.Trash(ctx, id, policy)
+}
+
+func (a *app) ListTrash(ctx context.Context) ([]*models.Entry, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := a.repo.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.countLinks(ctx, entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (a *app) RestoreEntry(ctx context.Context, id string) (*models.Entry, error) {
+	if _, err := a.getOwnedTrashedEntry(ctx, id); err != nil {
+		return nil, err
+	}
+
+	restored, err := a.repo.Restore(ctx, id)
+	if err := nameTaken(err); err != nil {
+		return nil, err
+	}
+	return a.withLinkCounts(ctx, restored, err)
+}
+
+func (a *app) PurgeEntry(ctx context.Context, id string) error {
+	if _, err := a.getOwnedTrashedEntry(ctx, id); err != nil {
+		return err
+	}
 
-	return a.repo.Create(ctx, entry)
+	return a.repo.Purge(ctx, id)
 }
 
-func (a *app) GetEntry(ctx context.Context, id string, userID string) (*models.Entry, error) {
+func (a *app) ListEntries(ctx context.Context, q models.ListQuery, pageToken string) ([]*models.Entry, string, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case q.Limit < 0:
+		return nil, "", ErrInvalidPageSize
+	case q.Limit == 0:
+		q.Limit = defaultPageSize
+	case q.Limit > maxPageSize:
+		q.Limit = maxPageSize
+	}
+	if q.Filter.Tags != "" {
+		if _, err := tagexpr.Parse(q.Filter.Tags); err != nil {
+			return nil, "", ErrInvalidTagExpression
+		}
+	}
+
+	fingerprint, err := queryFingerprint(userID, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if pageToken != "" {
+		var state pageState
+		if err := a.pageTokens.Decode(pageToken, &state); err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		if state.Query != fingerprint || state.Cursor == nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		q.After = state.Cursor
+	}
+
+	page, err := a.repo.ListPage(ctx, userID, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := a.countLinks(ctx, page.Entries...); err != nil {
+		return nil, "", err
+	}
+	if page.Next == nil {
+		return page.Entries, "", nil
+	}
+
+	nextToken, err := a.pageTokens.Encode(pageState{Query: fingerprint, Cursor: page.Next})
+	if err != nil {
+		return nil, "", err
+	}
+	return page.Entries, nextToken, nil
+}
+
+func (a *app) SearchEntries(ctx context.Context, q models.SearchQuery, pageToken string) ([]*models.SearchResult, string, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if tsquery.FromSearch(q.Text) == "" {
+		return nil, "", ErrInvalidSearchQuery
+	}
+	switch {
+	case q.Limit < 0:
+		return nil, "", ErrInvalidPageSize
+	case q.Limit == 0:
+		q.Limit = defaultPageSize
+	case q.Limit > maxPageSize:
+		q.Limit = maxPageSize
+	}
+	if q.Tags != "" {
+		if _, err := tagexpr.Parse(q.Tags); err != nil {
+			return nil, "", ErrInvalidTagExpression
+		}
+	}
+
+	fingerprint, err := searchFingerprint(userID, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if pageToken != "" {
+		var state searchPageState
+		if err := a.pageTokens.Decode(pageToken, &state); err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		if state.Query != fingerprint || state.Cursor == nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		q.After = state.Cursor
+	}
+
+	page, err := a.repo.Search(ctx, userID, q)
+	if err != nil {
+		return nil, "", err
+	}
+	entries := make([]*models.Entry, len(page.Results))
+	for i, r := range page.Results {
+		entries[i] = r.Entry
+	}
+	if err := a.countLinks(ctx, entries...); err != nil {
+		return nil, "", err
+	}
+	if page.Next == nil {
+		return page.Results, "", nil
+	}
+
+	nextToken, err := a.pageTokens.Encode(searchPageState{Query: fingerprint, Cursor: page.Next})
+	if err != nil {
+		return nil, "", err
+	}
+	return page.Results, nextToken, nil
+}
+
+func (a *app) SuggestEntries(ctx context.Context, query string, limit int) ([]*models.Suggestion, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrInvalidSearchQuery
+	}
+	switch {
+	case limit < 0:
+		return nil, ErrInvalidLimit
+	case limit == 0:
+		limit = defaultSuggestionLimit
+	case limit > maxSuggestionLimit:
+		limit = maxSuggestionLimit
+	}
+
+	suggestions, err := a.repo.Suggest(ctx, userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*models.Entry, len(suggestions))
+	for i, s := range suggestions {
+		entries[i] = s.Entry
+	}
+	if err := a.countLinks(ctx, entries...); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+func (a *app) ListBacklinks(ctx context.Context, id string, pageSize int, pageToken string) ([]*models.Backlink, string, error) {
+	target, err := a.getOwnedEntry(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case pageSize < 0:
+		return nil, "", ErrInvalidPageSize
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	offset := 0
+	if pageToken != "" {
+		var state backlinkPageState
+		if err := a.pageTokens.Decode(pageToken, &state); err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		if state.Entry != id || state.Offset <= 0 {
+			return nil, "", ErrInvalidPageToken
+		}
+		offset = state.Offset
+	}
+
+	// Fetch one extra entry to tell whether there is another page.
+	sources, err := a.repo.ListBacklinkedEntries(ctx, id, pageSize+1, offset)
+	if err != nil {
+		return nil, "", err
+	}
+	more := len(sources) > pageSize
+	if more {
+		sources = sources[:pageSize]
+	}
+
+	backlinks := make([]*models.Backlink, len(sources))
+	for i, source := range sources {
+		backlinks[i] = &models.Backlink{Source: source, Contexts: referenceContexts(source, target)}
+	}
+	if !more {
+		return backlinks, "", nil
+	}
+
+	nextToken, err := a.pageTokens.Encode(backlinkPageState{Entry: id, Offset: offset + pageSize})
+	if err != nil {
+		return nil, "", err
+	}
+	return backlinks, nextToken, nil
+}
+
+func (a *app) ListUnlinkedMentions(ctx context.Context, id string, pageSize int, pageToken string) ([]*models.Mention, string, error) {
+	target, err := a.getOwnedEntry(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case pageSize < 0:
+		return nil, "", ErrInvalidPageSize
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	offset := 0
+	if pageToken != "" {
+		var state mentionPageState
+		if err := a.pageTokens.Decode(pageToken, &state); err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+		if state.Entry != id || state.Offset <= 0 {
+			return nil, "", ErrInvalidPageToken
+		}
+		offset = state.Offset
+	}
+
+	// The repository only matches substrings, so keep fetching candidates
+	// until pageSize of them really mention the target, or they run out.
+	names := append([]string{target.Title}, target.Aliases...)
+	var mentions []*models.Mention
+	sources, next := 0, 0
+scan:
+	for {
+		candidates, err := a.repo.ListMentioning(ctx, target.UserID, id, names, pageSize, offset)
+		if err != nil {
+			return nil, "", err
+		}
+		for i, source := range candidates {
+			found := wikilink.Mentions(source.Content, names)
+			if len(found) == 0 {
+				continue
+			}
+			if sources == pageSize {
+				next = offset + i
+				break scan
+			}
+			sources++
+			for _, m := range found {
+				mentions = append(mentions, &models.Mention{
+					Source:  source,
+					Start:   m.Start,
+					End:     m.End,
+					Context: excerpt.Around(source.Content, m.Start, m.End),
+				})
+			}
+		}
+		if len(candidates) < pageSize {
+			break
+		}
+		offset += len(candidates)
+	}
+	if next == 0 {
+		return mentions, "", nil
+	}
+
+	nextToken, err := a.pageTokens.Encode(mentionPageState{Entry: id, Offset: next})
+	if err != nil {
+		return nil, "", err
+	}
+	return mentions, nextToken, nil
+}
+
+func (a *app) LinkMention(ctx context.Context, targetID, sourceID string, start int, version int64) (*models.Entry, error) {
+	target, err := a.getOwnedEntry(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := a.getOwnedEntry(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != source.Version {
+		if err := a.countLinks(ctx, source); err != nil {
+			return nil, err
+		}
+		return nil, &VersionConflictError{Current: source}
+	}
+	if source.ID == target.ID {
+		return nil, ErrMentionNotFound
+	}
+
+	names := append([]string{target.Title}, target.Aliases...)
+	for _, m := range wikilink.Mentions(source.Content, names) {
+		if m.Start != start {
+			continue
+		}
+		updated, err := a.repo.LinkMention(ctx, source.ID, target.ID, m.Start, m.End, source.Version)
+		if errors.Is(err, entryRepo.ErrVersionConflict) {
+			return nil, a.versionConflict(ctx, source.ID)
+		}
+		return a.withLinkCounts(ctx, updated, err)
+	}
+	return nil, ErrMentionNotFound
+}
+
+// referenceContexts returns an excerpt of source's content around each
+// wiki-link that refers to target by ID, title or alias.
+func referenceContexts(source, target *models.Entry) []string {
+	names := map[string]bool{strings.ToLower(target.Title): true}
+	for _, alias := range target.Aliases {
+		names[strings.ToLower(alias)] = true
+	}
+
+	contexts := []string{}
+	for _, ref := range wikilink.Parse(source.Content) {
+		if ref.ID == target.ID || (ref.Title != "" && names[strings.ToLower(ref.Title)]) {
+			contexts = append(contexts, excerpt.Around(source.Content, ref.Start, ref.End))
+		}
+	}
+	return contexts
+}
+
+func (a *app) ListRevisions(ctx context.Context, entryID string) ([]*models.Revision, error) {
+	if _, err := a.getOwnedEntry(ctx, entryID); err != nil {
+		return nil, err
+	}
+
+	return a.repo.ListRevisions(ctx, entryID)
+}
+
+func (a *app) GetRevision(ctx context.Context, entryID string, number int32) (*models.Revision, error) {
+	if _, err := a.getOwnedEntry(ctx, entryID); err != nil {
+		return nil, err
+	}
+
+	return a.getRevision(ctx, entryID, number)
+}
+
+func (a *app) DiffRevisions(ctx context.Context, entryID string, from int32, to int32) (string, error) {
+	if _, err := a.getOwnedEntry(ctx, entryID); err != nil {
+		return "", err
+	}
+
+	fromRevision, err := a.getRevision(ctx, entryID, from)
+	if err != nil {
+		return "", err
+	}
+	toRevision, err := a.getRevision(ctx, entryID, to)
+	if err != nil {
+		return "", err
+	}
+
+	return diff.Unified(
+		fmt.Sprintf("revision %d", from),
+		fmt.Sprintf("revision %d", to),
+		fromRevision.Content,
+		toRevision.Content,
+	), nil
+}
+
+func (a *app) RestoreRevision(ctx context.Context, entryID string, number int32) (*models.Entry, error) {
+	entry, err := a.getOwnedEntry(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := a.getRevision(ctx, entryID, number)
+	if err != nil {
+		return nil, err
+	}
+	transition, err := a.stageTransition(ctx, entry, revision.GrowthStage, fmt.Sprintf("restored revision %d", number))
+	if err != nil {
+		return nil, err
+	}
+	entry.Title = revision.Title
+	entry.Content = revision.Content
+	entry.GrowthStage = revision.GrowthStage
+
+	return a.update(ctx, entry, false, transition)
+}
+
+func (a *app) PromoteEntry(ctx context.Context, id string, stage models.GrowthStage, reason string, version int64) (*models.Entry, *models.StageTransition, error) {
+	if !stage.Valid() {
+		return nil, nil, ErrInvalidGrowthStage
+	}
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > maxReasonLength {
+		return nil, nil, ErrInvalidReason
+	}
+
+	existing, err := a.getOwnedEntry(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version != 0 && version != existing.Version {
+		return nil, nil, a.versionConflict(ctx, id)
+	}
+	if stage == existing.GrowthStage {
+		return nil, nil, ErrSameGrowthStage
+	}
+	transition, err := a.stageTransition(ctx, existing, stage, reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The transition was checked against the stage at this version.
+	patched, err := a.repo.Patch(ctx, id, models.Patch{GrowthStage: &stage, Version: existing.Version}, false, transition)
+	if errors.Is(err, entryRepo.ErrVersionConflict) {
+		return nil, nil, a.versionConflict(ctx, id)
+	}
+	patched, err = a.withLinkCounts(ctx, patched, err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return patched, transition, nil
+}
+
+func (a *app) ListStageTransitions(ctx context.Context, entryID string) ([]*models.StageTransition, error) {
+	if _, err := a.getOwnedEntry(ctx, entryID); err != nil {
+		return nil, err
+	}
+
+	return a.repo.ListStageTransitions(ctx, entryID)
+}
+
+// stageTransition checks that entry may move to growth stage to and returns the
+// transition to record, made by the caller, or nil if the stage is unchanged.
+func (a *app) stageTransition(ctx context.Context, entry *models.Entry, to models.GrowthStage, reason string) (*models.StageTransition, error) {
+	if to == entry.GrowthStage {
+		return nil, nil
+	}
+	if !a.lifecycle.Allows(entry.GrowthStage, to) {
+		return nil, &TransitionError{From: entry.GrowthStage, To: to, Allowed: a.lifecycle.Next(entry.GrowthStage)}
+	}
+
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	return &models.StageTransition{
+		EntryID: entry.ID,
+		UserID:  principal.UserID,
+		TokenID: principal.TokenID,
+		From:    entry.GrowthStage,
+		To:      to,
+		Reason:  reason,
+	}, nil
+}
+
+// update writes entry, turning a version conflict into a *VersionConflictError.
+func (a *app) update(ctx context.Context, entry *models.Entry, createPlaceholders bool, transition *models.StageTransition) (*models.Entry, error) {
+	updated, err := a.repo.Update(ctx, entry, createPlaceholders, transition)
+	if errors.Is(err, entryRepo.ErrVersionConflict) {
+		return nil, a.versionConflict(ctx, entry.ID)
+	}
+	if err := nameTaken(err); err != nil {
+		return nil, err
+	}
+	return a.withLinkCounts(ctx, updated, err)
+}
+
+// nameTaken converts a repository *NameTakenError into a *NameTakenError.
+// It returns nil for any other error.
+func nameTaken(err error) error {
+	var taken *entryRepo.NameTakenError
+	if !errors.As(err, &taken) {
+		return nil
+	}
+	return &NameTakenError{Name: taken.Name, EntryID: taken.EntryID}
+}
+
+// versionConflict builds a *VersionConflictError carrying the stored entry.
+func (a *app) versionConflict(ctx context.Context, id string) error {
+	current, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.countLinks(ctx, current); err != nil {
+		return err
+	}
+	return &VersionConflictError{Current: current}
+}
+
+// countLinks sets the link counts of entries, which the repository leaves unset
+// on reads so that internal lookups do not pay for them.
+func (a *app) countLinks(ctx context.Context, entries ...*models.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return a.repo.CountLinks(ctx, entries)
+}
+
+// withLinkCounts sets entry's link counts, unless err is already set.
+func (a *app) withLinkCounts(ctx context.Context, entry *models.Entry, err error) (*models.Entry, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := a.countLinks(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (a *app) getRevision(ctx context.Context, entryID string, number int32) (*models.Revision, error) {
+	revision, err := a.repo.GetRevision(ctx, entryID, number)
+	if errors.Is(err, entryRepo.ErrRevisionNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	return revision, err
+}
+
+// getOwnedEntry loads an entry and verifies it belongs to the authenticated caller.
+func (a *app) getOwnedEntry(ctx context.Context, id string) (*models.Entry, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	entry, err := a.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -49,32 +898,56 @@ func (a *app) GetEntry(ctx context.Context, id string, userID string) (*models.E
 	return entry, nil
 }
 
-func (a *app) UpdateEntry(ctx context.Context, entry *models.Entry) (*models.Entry, error) {
-	if err := entry.Validate(); err != nil {
-		return nil, ErrInvalidEntry
+// getOwnedTrashedEntry is getOwnedEntry for entries in the trash.
+func (a *app) getOwnedTrashedEntry(ctx context.Context, id string) (*models.Entry, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	existing, err := a.repo.GetByID(ctx, entry.ID)
+	entry, err := a.repo.GetTrashedByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, entryRepo.ErrEntryNotFound) {
+			return nil, ErrNotInTrash
+		}
 		return nil, err
 	}
 
-	if existing.UserID != entry.UserID {
+	if entry.UserID != userID {
 		return nil, ErrUnauthorized
 	}
 
-	return a.repo.Update(ctx, entry)
+	return entry, nil
 }
 
-func (a *app) DeleteEntry(ctx context.Context, id string) error {
-	_, err := a.repo.GetByID(ctx, id)
+// queryFingerprint identifies a listing so that a page token cannot be replayed
+// against a different user, filter or sort order.
+func queryFingerprint(userID string, q models.ListQuery) (string, error) {
+	b, err := json.Marshal(struct {
+		UserID     string
+		Filter     models.ListFilter
+		SortBy     models.SortField
+		Descending bool
+	}{userID, q.Filter, q.SortBy, q.Descending})
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	return a.repo.Delete(ctx, id)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16]), nil
 }
 
-func (a *app) ListEntries(ctx context.Context, userID string) ([]*models.Entry, error) {
-	return a.repo.ListByUser(ctx, userID)
+// searchFingerprint identifies a search so that a page token cannot be replayed
+// against a different user, search text or filters.
+func searchFingerprint(userID string, q models.SearchQuery) (string, error) {
+	b, err := json.Marshal(struct {
+		UserID      string
+		Text        string
+		GrowthStage models.GrowthStage
+		Tags        string
+	}{userID, q.Text, q.GrowthStage, q.Tags})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16]), nil
 }
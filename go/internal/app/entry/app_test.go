@@ -0,0 +1,1117 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/entry"
+	linkModels "moss/go/internal/models/link"
+	"moss/go/internal/pagetoken"
+	entryRepo "moss/go/internal/repository/entry"
+)
+
+// fakeRepo keeps entries in memory. Methods a test does not exercise panic
+// through the embedded nil Repository.
+type fakeRepo struct {
+	entryRepo.Repository
+	entries     map[string]*models.Entry
+	revisions   map[string][]*models.Revision // by entry ID, oldest first
+	backlinks   map[string][]*linkModels.Link // by target entry ID
+	trashPolicy models.DeletionPolicy         // argument of the last Trash call
+	purgeCutoff time.Time                     // argument of the last PurgeTrashedBefore call
+	suggested   []string                      // text and limit of the last Suggest call
+	linked      []string                      // source, target, start and end of LinkMention calls
+	transitions []*models.StageTransition     // recorded by Update and Patch
+}
+
+func newFakeRepo(entries ...*models.Entry) *fakeRepo {
+	r := &fakeRepo{
+		entries:   make(map[string]*models.Entry),
+		revisions: make(map[string][]*models.Revision),
+		backlinks: make(map[string][]*linkModels.Link),
+	}
+	for _, e := range entries {
+		r.entries[e.ID] = e
+	}
+	return r
+}
+
+func (r *fakeRepo) Create(_ context.Context, e *models.Entry, _ string, _ bool) (*models.Entry, error) {
+	if _, ok := r.entries[e.ID]; ok {
+		return nil, entryRepo.ErrEntryExists
+	}
+	if err := r.checkNamesFree(e); err != nil {
+		return nil, err
+	}
+	stored := *e
+	r.entries[e.ID] = &stored
+	copied := stored
+	return &copied, nil
+}
+
+// checkNamesFree fails like the repository if e's title is another live entry's
+// alias, or one of e's aliases is another live entry's title or alias.
+func (r *fakeRepo) checkNamesFree(e *models.Entry) error {
+	for _, other := range r.entries {
+		if other.ID == e.ID || other.UserID != e.UserID || other.DeletedAt != nil {
+			continue
+		}
+		for _, alias := range other.Aliases {
+			if strings.EqualFold(alias, e.Title) {
+				return &entryRepo.NameTakenError{Name: e.Title, EntryID: other.ID}
+			}
+		}
+		for _, alias := range e.Aliases {
+			if strings.EqualFold(alias, other.Title) || containsFold(other.Aliases, alias) {
+				return &entryRepo.NameTakenError{Name: alias, EntryID: other.ID}
+			}
+		}
+	}
+	return nil
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeRepo) GetByID(_ context.Context, id string) (*models.Entry, error) {
+	e, ok := r.entries[id]
+	if !ok || e.DeletedAt != nil {
+		return nil, entryRepo.ErrEntryNotFound
+	}
+	copied := *e
+	return &copied, nil
+}
+
+// Update stores e, bumping its version, appends a revision for it and records transition.
+func (r *fakeRepo) Update(_ context.Context, e *models.Entry, _ bool, transition *models.StageTransition) (*models.Entry, error) {
+	current, ok := r.entries[e.ID]
+	if !ok {
+		return nil, entryRepo.ErrEntryNotFound
+	}
+	if e.Version != 0 && e.Version != current.Version {
+		return nil, entryRepo.ErrVersionConflict
+	}
+	if err := r.checkNamesFree(e); err != nil {
+		return nil, err
+	}
+	if transition != nil {
+		r.transitions = append(r.transitions, transition)
+	}
+	stored := *e
+	stored.Version = current.Version + 1
+	r.entries[e.ID] = &stored
+	r.revisions[e.ID] = append(r.revisions[e.ID], &models.Revision{
+		EntryID:     e.ID,
+		Number:      int32(len(r.revisions[e.ID]) + 1),
+		Title:       e.Title,
+		Content:     e.Content,
+		GrowthStage: e.GrowthStage,
+	})
+	copied := stored
+	return &copied, nil
+}
+
+// Patch applies the set fields of p through Update.
+func (r *fakeRepo) Patch(ctx context.Context, id string, p models.Patch, createPlaceholders bool, transition *models.StageTransition) (*models.Entry, error) {
+	current, ok := r.entries[id]
+	if !ok {
+		return nil, entryRepo.ErrEntryNotFound
+	}
+	e := *current
+	e.Version = p.Version
+	if p.Title != nil {
+		e.Title = *p.Title
+	}
+	if p.Content != nil {
+		e.Content = *p.Content
+	}
+	if p.GrowthStage != nil {
+		e.GrowthStage = *p.GrowthStage
+	}
+	if p.Aliases != nil {
+		e.Aliases = *p.Aliases
+	}
+	return r.Update(ctx, &e, createPlaceholders, transition)
+}
+
+// Trash records policy and returns the links into the entry as removed,
+// unless policy keeps them.
+func (r *fakeRepo) Trash(_ context.Context, id string, policy models.DeletionPolicy) ([]*linkModels.Link, error) {
+	e, ok := r.entries[id]
+	if !ok || e.DeletedAt != nil {
+		return nil, entryRepo.ErrEntryNotFound
+	}
+	now := time.Now().UTC()
+	e.DeletedAt = &now
+	r.trashPolicy = policy
+	if policy == models.DeletionPolicyKeep {
+		return nil, nil
+	}
+	return r.backlinks[id], nil
+}
+
+func (r *fakeRepo) ListBacklinks(_ context.Context, id string) ([]*linkModels.Link, error) {
+	return r.backlinks[id], nil
+}
+
+func (r *fakeRepo) GetTrashedByID(_ context.Context, id string) (*models.Entry, error) {
+	e, ok := r.entries[id]
+	if !ok || e.DeletedAt == nil {
+		return nil, entryRepo.ErrEntryNotFound
+	}
+	copied := *e
+	return &copied, nil
+}
+
+func (r *fakeRepo) Restore(_ context.Context, id string) (*models.Entry, error) {
+	e, ok := r.entries[id]
+	if !ok || e.DeletedAt == nil {
+		return nil, entryRepo.ErrEntryNotFound
+	}
+	if err := r.checkNamesFree(e); err != nil {
+		return nil, err
+	}
+	e.DeletedAt = nil
+	copied := *e
+	return &copied, nil
+}
+
+func (r *fakeRepo) Purge(_ context.Context, id string) error {
+	if e, ok := r.entries[id]; !ok || e.DeletedAt == nil {
+		return entryRepo.ErrEntryNotFound
+	}
+	delete(r.entries, id)
+	delete(r.revisions, id)
+	return nil
+}
+
+func (r *fakeRepo) PurgeTrashedBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	r.purgeCutoff = cutoff
+	var purged int64
+	for id, e := range r.entries {
+		if e.DeletedAt != nil && e.DeletedAt.Before(cutoff) {
+			delete(r.entries, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *fakeRepo) GetRevision(_ context.Context, entryID string, number int32) (*models.Revision, error) {
+	revisions := r.revisions[entryID]
+	if number < 1 || int(number) > len(revisions) {
+		return nil, entryRepo.ErrRevisionNotFound
+	}
+	copied := *revisions[number-1]
+	return &copied, nil
+}
+
+// ListPage pages through the user's entries in ID order, ignoring filters.
+func (r *fakeRepo) ListPage(_ context.Context, userID string, q models.ListQuery) (*models.EntryPage, error) {
+	var ids []string
+	for id, e := range r.entries {
+		if e.UserID == userID && (q.After == nil || id > q.After.ID) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	page := &models.EntryPage{}
+	for _, id := range ids {
+		if len(page.Entries) == q.Limit {
+			page.Next = &models.Cursor{ID: page.Entries[len(page.Entries)-1].ID}
+			break
+		}
+		copied := *r.entries[id]
+		page.Entries = append(page.Entries, &copied)
+	}
+	return page, nil
+}
+
+// Search returns every live entry of the user as one result per page, in ID
+// order, ignoring the query text.
+func (r *fakeRepo) Search(ctx context.Context, userID string, q models.SearchQuery) (*models.SearchPage, error) {
+	listQuery := models.ListQuery{Limit: 1}
+	if q.After != nil {
+		listQuery.After = &models.Cursor{ID: q.After.ID}
+	}
+	entries, err := r.ListPage(ctx, userID, listQuery)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.SearchPage{}
+	for _, e := range entries.Entries {
+		page.Results = append(page.Results, &models.SearchResult{Entry: e, Rank: 0.5})
+	}
+	if entries.Next != nil {
+		page.Next = &models.SearchCursor{Rank: 0.5, ID: entries.Next.ID}
+	}
+	return page, nil
+}
+
+func (r *fakeRepo) Suggest(_ context.Context, _ string, text string, limit int) ([]*models.Suggestion, error) {
+	r.suggested = []string{text, strconv.Itoa(limit)}
+	return nil, nil
+}
+
+// ListBacklinkedEntries treats every other entry with a wiki-link as linking
+// to id, in ID order, so that contexts are left to the app to find.
+func (r *fakeRepo) ListBacklinkedEntries(_ context.Context, id string, limit, offset int) ([]*models.Entry, error) {
+	var ids []string
+	for sourceID, e := range r.entries {
+		if sourceID != id && e.DeletedAt == nil && strings.Contains(e.Content, "[[") {
+			ids = append(ids, sourceID)
+		}
+	}
+	sort.Strings(ids)
+
+	var sources []*models.Entry
+	for i := offset; i < len(ids) && len(sources) < limit; i++ {
+		copied := *r.entries[ids[i]]
+		sources = append(sources, &copied)
+	}
+	return sources, nil
+}
+
+// ListMentioning returns the user's other live entries containing one of
+// names anywhere, in ID order.
+func (r *fakeRepo) ListMentioning(_ context.Context, userID, id string, names []string, limit, offset int) ([]*models.Entry, error) {
+	var ids []string
+	for sourceID, e := range r.entries {
+		if sourceID == id || e.UserID != userID || e.DeletedAt != nil {
+			continue
+		}
+		for _, name := range names {
+			if strings.Contains(strings.ToLower(e.Content), strings.ToLower(name)) {
+				ids = append(ids, sourceID)
+				break
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	var sources []*models.Entry
+	for i := offset; i < len(ids) && len(sources) < limit; i++ {
+		copied := *r.entries[ids[i]]
+		sources = append(sources, &copied)
+	}
+	return sources, nil
+}
+
+// LinkMention records the call and replaces content[start:end] with a link by ID.
+func (r *fakeRepo) LinkMention(ctx context.Context, sourceID, targetID string, start, end int, version int64) (*models.Entry, error) {
+	r.linked = append(r.linked, sourceID, targetID, strconv.Itoa(start), strconv.Itoa(end))
+	source := *r.entries[sourceID]
+	source.Content = source.Content[:start] + "[[id:" + targetID + "|" + source.Content[start:end] + "]]" + source.Content[end:]
+	source.Version = version
+	return r.Update(ctx, &source, false, nil)
+}
+
+// CountLinks counts the links in backlinks.
+func (r *fakeRepo) CountLinks(_ context.Context, entries []*models.Entry) error {
+	for _, e := range entries {
+		e.LinkCount, e.BacklinkCount = 0, int64(len(r.backlinks[e.ID]))
+		for _, links := range r.backlinks {
+			for _, l := range links {
+				if l.SourceEntryID == e.ID {
+					e.LinkCount++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func newTestApp(repo *fakeRepo) App {
+	return NewApp(repo, pagetoken.NewCodec([]byte("page-token-secret")), models.DefaultLifecycle())
+}
+
+func userContext(userID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID, SessionID: "session-" + userID})
+}
+
+func TestCreateEntry(t *testing.T) {
+	const takenID = "01890a5d-ac96-774b-bcce-b302099a8057"
+	repo := newFakeRepo(&models.Entry{ID: takenID, UserID: "user-1", Title: "Moss", Content: "Grows on stones.", GrowthStage: models.GrowthStageSeed})
+	a := newTestApp(repo)
+
+	created, err := a.CreateEntry(userContext("user-1"), &models.Entry{Title: "Ferns", Content: "Unfurl.", GrowthStage: models.GrowthStageSeed}, "", false)
+	if err != nil {
+		t.Fatalf("CreateEntry = %v", err)
+	}
+	id, err := uuid.Parse(created.ID)
+	if err != nil || id.Version() != 7 {
+		t.Errorf("generated ID %q, want a UUIDv7", created.ID)
+	}
+	if created.UserID != "user-1" {
+		t.Errorf("UserID = %q, want the caller", created.UserID)
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		entry  models.Entry
+		key    string
+		wantID string
+		want   error
+	}{
+		{"retry by owner", "user-1", models.Entry{ID: takenID, Title: "Moss", Content: "Grows on stones.", GrowthStage: models.GrowthStageSeed}, "", takenID, nil},
+		{"ID of another user", "user-2", models.Entry{ID: takenID, Title: "Moss", Content: "Grows on stones.", GrowthStage: models.GrowthStageSeed}, "", "", ErrEntryIDTaken},
+		{"malformed ID", "user-1", models.Entry{ID: "entry-1", Title: "Moss", Content: "Grows on stones.", GrowthStage: models.GrowthStageSeed}, "", "", ErrInvalidEntry},
+		{"missing title", "user-1", models.Entry{Content: "Grows on stones."}, "", "", ErrInvalidEntry},
+		{"long idempotency key", "user-1", models.Entry{Title: "Moss", Content: "Grows on stones.", GrowthStage: models.GrowthStageSeed}, string(make([]byte, 256)), "", ErrInvalidIdempotencyKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.CreateEntry(userContext(tt.userID), &tt.entry, tt.key, false)
+			if err != tt.want {
+				t.Fatalf("CreateEntry = %v, want %v", err, tt.want)
+			}
+			if err == nil && got.ID != tt.wantID {
+				t.Errorf("CreateEntry returned %s, want %s", got.ID, tt.wantID)
+			}
+		})
+	}
+
+	if _, err := a.CreateEntry(context.Background(), &models.Entry{Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed}, "", false); err != auth.ErrUnauthenticated {
+		t.Errorf("CreateEntry without a principal = %v, want ErrUnauthenticated", err)
+	}
+}
+
+func TestGetEntryChecksOwner(t *testing.T) {
+	repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed})
+	a := newTestApp(repo)
+
+	if _, err := a.GetEntry(userContext("user-2"), "entry-1"); err != ErrUnauthorized {
+		t.Errorf("GetEntry by another user = %v, want ErrUnauthorized", err)
+	}
+	if _, err := a.GetEntry(userContext("user-1"), "missing"); err != entryRepo.ErrEntryNotFound {
+		t.Errorf("GetEntry(missing) = %v, want ErrEntryNotFound", err)
+	}
+	if got, err := a.GetEntry(userContext("user-1"), "entry-1"); err != nil || got.Title != "Moss" {
+		t.Errorf("GetEntry = %+v, %v", got, err)
+	}
+}
+
+func TestListEntriesPageTokens(t *testing.T) {
+	repo := newFakeRepo(
+		&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "entry-2", UserID: "user-1", Title: "Ferns", Content: "Unfurl.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "entry-3", UserID: "user-1", Title: "Lichen", Content: "Crusts.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "entry-4", UserID: "user-2", Title: "Algae", Content: "Floats.", GrowthStage: models.GrowthStageSeed},
+	)
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+	q := models.ListQuery{SortBy: models.SortByTitle, Limit: 2}
+
+	first, token, err := a.ListEntries(ctx, q, "")
+	if err != nil {
+		t.Fatalf("ListEntries = %v", err)
+	}
+	if len(first) != 2 || token == "" {
+		t.Fatalf("first page has %d entries and token %q, want 2 and a token", len(first), token)
+	}
+	second, next, err := a.ListEntries(ctx, q, token)
+	if err != nil {
+		t.Fatalf("ListEntries(page 2) = %v", err)
+	}
+	if len(second) != 1 || second[0].ID != "entry-3" || next != "" {
+		t.Errorf("second page = %d entries, token %q, want only entry-3 and no token", len(second), next)
+	}
+
+	otherFilter := q
+	otherFilter.Filter.TitlePrefix = "m"
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		q     models.ListQuery
+		token string
+		want  error
+	}{
+		{"other filter", ctx, otherFilter, token, ErrInvalidPageToken},
+		{"other sort", ctx, models.ListQuery{SortBy: models.SortByCreated, Limit: 2}, token, ErrInvalidPageToken},
+		{"other user", userContext("user-2"), q, token, ErrInvalidPageToken},
+		{"tampered", ctx, q, token[:len(token)-2] + "xx", ErrInvalidPageToken},
+		{"garbage", ctx, q, "not-a-token", ErrInvalidPageToken},
+		{"negative size", ctx, models.ListQuery{Limit: -1}, "", ErrInvalidPageSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := a.ListEntries(tt.ctx, tt.q, tt.token); err != tt.want {
+				t.Errorf("ListEntries = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed})
+	repo.revisions["entry-1"] = []*models.Revision{{EntryID: "entry-1", Number: 1, Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed}}
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+
+	if _, err := a.UpdateEntry(ctx, &models.Entry{ID: "entry-1", Title: "Mosses", Content: "Grows\non stones.", GrowthStage: models.GrowthStageSprout}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := a.DiffRevisions(ctx, "entry-1", 1, 2)
+	if err != nil {
+		t.Fatalf("DiffRevisions = %v", err)
+	}
+	want := "--- revision 1\n+++ revision 2\n@@ -1 +1,2 @@\n-Grows.\n+Grows\n+on stones.\n"
+	if got != want {
+		t.Errorf("DiffRevisions =\n%s\nwant\n%s", got, want)
+	}
+
+	restored, err := a.RestoreRevision(ctx, "entry-1", 1)
+	if err != nil {
+		t.Fatalf("RestoreRevision = %v", err)
+	}
+	if restored.Title != "Moss" || restored.Content != "Grows." || restored.GrowthStage != models.GrowthStageSeed {
+		t.Errorf("RestoreRevision = %+v, want revision 1", restored)
+	}
+	if n := len(repo.revisions["entry-1"]); n != 3 {
+		t.Errorf("entry has %d revisions after restoring, want 3", n)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"get missing", func() error { _, err := a.GetRevision(ctx, "entry-1", 9); return err }, ErrRevisionNotFound},
+		{"diff missing", func() error { _, err := a.DiffRevisions(ctx, "entry-1", 1, 9); return err }, ErrRevisionNotFound},
+		{"restore missing", func() error { _, err := a.RestoreRevision(ctx, "entry-1", 0); return err }, ErrRevisionNotFound},
+		{"other user", func() error { _, err := a.GetRevision(userContext("user-2"), "entry-1", 1); return err }, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err != tt.want {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateEntryVersionConflict(t *testing.T) {
+	repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", Version: 3, GrowthStage: models.GrowthStageSeed})
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+
+	_, err := a.UpdateEntry(ctx, &models.Entry{ID: "entry-1", Title: "Stale", Content: "Edit.", Version: 2, GrowthStage: models.GrowthStageSeed}, false)
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("UpdateEntry with a stale version = %v, want a *VersionConflictError", err)
+	}
+	if conflict.Current.Version != 3 || conflict.Current.Title != "Moss" {
+		t.Errorf("conflict carries %+v, want the stored entry", conflict.Current)
+	}
+
+	updated, err := a.UpdateEntry(ctx, &models.Entry{ID: "entry-1", Title: "Fresh", Content: "Edit.", Version: 3, GrowthStage: models.GrowthStageSeed}, false)
+	if err != nil {
+		t.Fatalf("UpdateEntry with the current version = %v", err)
+	}
+	if updated.Version != 4 {
+		t.Errorf("Version = %d, want 4", updated.Version)
+	}
+
+	if _, err := a.UpdateEntry(ctx, &models.Entry{ID: "entry-1", Title: "Blind", Content: "Edit.", GrowthStage: models.GrowthStageSeed}, false); err != nil {
+		t.Errorf("UpdateEntry without a version = %v, want it to overwrite", err)
+	}
+}
+
+func TestPatchEntry(t *testing.T) {
+	title, empty := "Mosses", ""
+	tests := []struct {
+		name      string
+		userID    string
+		patch     models.Patch
+		wantTitle string
+		want      error
+	}{
+		{"title only", "user-1", models.Patch{Title: &title}, "Mosses", nil},
+		{"current version", "user-1", models.Patch{Title: &title, Version: 3}, "Mosses", nil},
+		{"nothing set", "user-1", models.Patch{}, "Moss", nil},
+		{"clear title", "user-1", models.Patch{Title: &empty}, "", ErrInvalidEntry},
+		{"clear content", "user-1", models.Patch{Content: &empty}, "", ErrInvalidEntry},
+		{"other user", "user-2", models.Patch{Title: &title}, "", ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", Version: 3, GrowthStage: models.GrowthStageSeed})
+			got, err := newTestApp(repo).PatchEntry(userContext(tt.userID), "entry-1", tt.patch, false)
+			if err != tt.want {
+				t.Fatalf("PatchEntry = %v, want %v", err, tt.want)
+			}
+			if err == nil && (got.Title != tt.wantTitle || got.Content != "Grows.") {
+				t.Errorf("PatchEntry = %+v, want title %q and the content kept", got, tt.wantTitle)
+			}
+		})
+	}
+
+	repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", Version: 3, GrowthStage: models.GrowthStageSeed})
+	_, err := newTestApp(repo).PatchEntry(userContext("user-1"), "entry-1", models.Patch{Title: &title, Version: 1}, false)
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.Current.Version != 3 {
+		t.Errorf("PatchEntry with a stale version = %v, want a *VersionConflictError at version 3", err)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	repo := newFakeRepo(
+		&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "entry-2", UserID: "user-1", Title: "Ferns", Content: "Unfurl.", GrowthStage: models.GrowthStageSeed},
+	)
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+
+	if _, err := a.DeleteEntry(ctx, "entry-1", ""); err != nil {
+		t.Fatalf("DeleteEntry = %v", err)
+	}
+	if _, err := a.GetEntry(ctx, "entry-1"); err != entryRepo.ErrEntryNotFound {
+		t.Errorf("GetEntry on a trashed entry = %v, want ErrEntryNotFound", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"restore live entry", func() error { _, err := a.RestoreEntry(ctx, "entry-2"); return err }, ErrNotInTrash},
+		{"purge live entry", func() error { return a.PurgeEntry(ctx, "entry-2") }, ErrNotInTrash},
+		{"restore missing entry", func() error { _, err := a.RestoreEntry(ctx, "missing"); return err }, ErrNotInTrash},
+		{"restore other user's", func() error { _, err := a.RestoreEntry(userContext("user-2"), "entry-1"); return err }, ErrUnauthorized},
+		{"purge other user's", func() error { return a.PurgeEntry(userContext("user-2"), "entry-1") }, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err != tt.want {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	restored, err := a.RestoreEntry(ctx, "entry-1")
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("RestoreEntry = %+v, %v, want the live entry", restored, err)
+	}
+	if _, err := a.DeleteEntry(ctx, "entry-1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PurgeEntry(ctx, "entry-1"); err != nil {
+		t.Fatalf("PurgeEntry = %v", err)
+	}
+	if _, ok := repo.entries["entry-1"]; ok {
+		t.Error("PurgeEntry kept the entry")
+	}
+}
+
+func TestDeleteEntryPolicies(t *testing.T) {
+	backlink := &linkModels.Link{SourceEntryID: "entry-2", TargetEntryID: "entry-1", UserID: "user-1"}
+	tests := []struct {
+		name        string
+		policy      models.DeletionPolicy
+		backlinks   []*linkModels.Link
+		wantPolicy  models.DeletionPolicy // passed to Trash; empty if not trashed
+		wantRemoved int
+		wantErr     error
+	}{
+		{"default keeps links", "", []*linkModels.Link{backlink}, models.DeletionPolicyKeep, 0, nil},
+		{"cascade", models.DeletionPolicyCascade, []*linkModels.Link{backlink}, models.DeletionPolicyCascade, 1, nil},
+		{"rewrite", models.DeletionPolicyRewrite, []*linkModels.Link{backlink}, models.DeletionPolicyRewrite, 1, nil},
+		{"reject without backlinks", models.DeletionPolicyReject, nil, models.DeletionPolicyReject, 0, nil},
+		{"reject with backlinks", models.DeletionPolicyReject, []*linkModels.Link{backlink}, "", 0, &BacklinksError{Backlinks: []*linkModels.Link{backlink}}},
+		{"unknown", "archive", nil, "", 0, ErrInvalidDeletionPolicy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed})
+			repo.backlinks["entry-1"] = tt.backlinks
+
+			removed, err := newTestApp(repo).DeleteEntry(userContext("user-1"), "entry-1", tt.policy)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("DeleteEntry = %v, want %v", err, tt.wantErr)
+			}
+			if repo.trashPolicy != tt.wantPolicy {
+				t.Errorf("trashed with policy %q, want %q", repo.trashPolicy, tt.wantPolicy)
+			}
+			if trashed := repo.entries["entry-1"].DeletedAt != nil; trashed != (tt.wantPolicy != "") {
+				t.Errorf("entry trashed = %v, want %v", trashed, tt.wantPolicy != "")
+			}
+			if len(removed) != tt.wantRemoved {
+				t.Errorf("DeleteEntry removed %d links, want %d", len(removed), tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestListEntriesRejectsInvalidTagExpression(t *testing.T) {
+	a := newTestApp(newFakeRepo())
+
+	q := models.ListQuery{Filter: models.ListFilter{Tags: "#go AND ("}}
+	if _, _, err := a.ListEntries(userContext("user-1"), q, ""); err != ErrInvalidTagExpression {
+		t.Errorf("ListEntries = %v, want ErrInvalidTagExpression", err)
+	}
+}
+
+func TestSearchEntries(t *testing.T) {
+	repo := newFakeRepo(
+		&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "entry-2", UserID: "user-1", Title: "Ferns", Content: "Unfurl.", GrowthStage: models.GrowthStageSeed},
+	)
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+	q := models.SearchQuery{Text: "moss"}
+
+	first, token, err := a.SearchEntries(ctx, q, "")
+	if err != nil || len(first) != 1 || token == "" {
+		t.Fatalf("SearchEntries = %d results, token %q, %v; want one result and a token", len(first), token, err)
+	}
+	second, next, err := a.SearchEntries(ctx, q, token)
+	if err != nil || len(second) != 1 || second[0].Entry.ID != "entry-2" || next != "" {
+		t.Errorf("second page = %d results, token %q, %v; want entry-2 and no token", len(second), next, err)
+	}
+	_, listToken, err := a.ListEntries(ctx, models.ListQuery{Limit: 1}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		q     models.SearchQuery
+		token string
+		want  error
+	}{
+		{"empty", models.SearchQuery{}, "", ErrInvalidSearchQuery},
+		{"punctuation only", models.SearchQuery{Text: `!!! -- ""`}, "", ErrInvalidSearchQuery},
+		{"negative size", models.SearchQuery{Text: "moss", Limit: -1}, "", ErrInvalidPageSize},
+		{"invalid tags", models.SearchQuery{Text: "moss", Tags: "#go AND ("}, "", ErrInvalidTagExpression},
+		{"other text", models.SearchQuery{Text: "ferns"}, token, ErrInvalidPageToken},
+		{"list token", q, listToken, ErrInvalidPageToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := a.SearchEntries(ctx, tt.q, tt.token); err != tt.want {
+				t.Errorf("SearchEntries = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestEntries(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		limit         int
+		wantSuggested []string
+		want          error
+	}{
+		{"trims query", "  mos ", 5, []string{"mos", "5"}, nil},
+		{"default limit", "mos", 0, []string{"mos", strconv.Itoa(defaultSuggestionLimit)}, nil},
+		{"clamps limit", "mos", 1000, []string{"mos", strconv.Itoa(maxSuggestionLimit)}, nil},
+		{"blank query", " \t", 5, nil, ErrInvalidSearchQuery},
+		{"negative limit", "mos", -1, nil, ErrInvalidLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			if _, err := newTestApp(repo).SuggestEntries(userContext("user-1"), tt.query, tt.limit); err != tt.want {
+				t.Fatalf("SuggestEntries = %v, want %v", err, tt.want)
+			}
+			if !reflect.DeepEqual(repo.suggested, tt.wantSuggested) {
+				t.Errorf("Suggest called with %q, want %q", repo.suggested, tt.wantSuggested)
+			}
+		})
+	}
+}
+
+func TestEntryNameTaken(t *testing.T) {
+	const newID = "01890a5d-ac96-774b-bcce-b302099a8057"
+	existing := &models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", Aliases: []string{"Bryophyte"}, GrowthStage: models.GrowthStageSeed}
+	tests := []struct {
+		name  string
+		entry models.Entry
+		want  error
+	}{
+		{"title is an alias", models.Entry{ID: newID, Title: "bryophyte", Content: "Grows.", GrowthStage: models.GrowthStageSeed}, &NameTakenError{Name: "bryophyte", EntryID: "entry-1"}},
+		{"alias is a title", models.Entry{ID: newID, Title: "Mosses", Content: "Grows.", Aliases: []string{"MOSS"}, GrowthStage: models.GrowthStageSeed}, &NameTakenError{Name: "MOSS", EntryID: "entry-1"}},
+		{"alias is an alias", models.Entry{ID: newID, Title: "Mosses", Content: "Grows.", Aliases: []string{"Bryophyte"}, GrowthStage: models.GrowthStageSeed}, &NameTakenError{Name: "Bryophyte", EntryID: "entry-1"}},
+		{"alias is not a wiki-link name", models.Entry{ID: newID, Title: "Mosses", Content: "Grows.", Aliases: []string{"a|b"}, GrowthStage: models.GrowthStageSeed}, ErrInvalidEntry},
+		{"free", models.Entry{ID: newID, Title: "Mosses", Content: "Grows.", Aliases: []string{"Musci"}, GrowthStage: models.GrowthStageSeed}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := *existing
+			a := newTestApp(newFakeRepo(&stored))
+			created := tt.entry
+			if _, err := a.CreateEntry(userContext("user-1"), &created, "", false); !reflect.DeepEqual(err, tt.want) {
+				t.Errorf("CreateEntry = %v, want %v", err, tt.want)
+			}
+
+			stored = *existing
+			other := &models.Entry{ID: newID, UserID: "user-1", Title: "Other", Content: "Grows.", GrowthStage: models.GrowthStageSeed}
+			a = newTestApp(newFakeRepo(&stored, other))
+			updated := tt.entry
+			if _, err := a.UpdateEntry(userContext("user-1"), &updated, false); !reflect.DeepEqual(err, tt.want) {
+				t.Errorf("UpdateEntry = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListBacklinks(t *testing.T) {
+	repo := newFakeRepo(
+		&models.Entry{ID: "moss", UserID: "user-1", Title: "Moss", Content: "Grows.", Aliases: []string{"Bryophyte"}, GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "a-ferns", UserID: "user-1", Title: "Ferns", Content: "Unlike [[moss]], ferns have roots. See [[Lichen]].", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "b-forest", UserID: "user-1", Title: "Forest", Content: "A [[Bryophyte|carpet]] covers [[id:moss|the floor]].", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "c-rocks", UserID: "user-1", Title: "Rocks", Content: "Mostly [[Lichen]].", GrowthStage: models.GrowthStageSeed},
+	)
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+
+	first, token, err := a.ListBacklinks(ctx, "moss", 2, "")
+	if err != nil {
+		t.Fatalf("ListBacklinks = %v", err)
+	}
+	if len(first) != 2 || token == "" {
+		t.Fatalf("first page has %d backlinks and token %q, want 2 and a token", len(first), token)
+	}
+	wantContexts := map[string][]string{
+		"a-ferns":  {"[[moss]]"},
+		"b-forest": {"[[Bryophyte|carpet]]", "[[id:moss|the floor]]"},
+	}
+	for _, b := range first {
+		want := wantContexts[b.Source.ID]
+		if len(b.Contexts) != len(want) {
+			t.Errorf("%s has contexts %q, want ones around %q", b.Source.ID, b.Contexts, want)
+			continue
+		}
+		for i, link := range want {
+			if !strings.Contains(b.Contexts[i], link) {
+				t.Errorf("%s context %d = %q, want it around %s", b.Source.ID, i, b.Contexts[i], link)
+			}
+		}
+	}
+
+	second, next, err := a.ListBacklinks(ctx, "moss", 2, token)
+	if err != nil {
+		t.Fatalf("ListBacklinks(page 2) = %v", err)
+	}
+	if len(second) != 1 || second[0].Source.ID != "c-rocks" || len(second[0].Contexts) != 0 || next != "" {
+		t.Errorf("second page = %+v, token %q, want c-rocks without contexts and no token", second, next)
+	}
+
+	if _, _, err := a.ListBacklinks(ctx, "a-ferns", 2, token); err != ErrInvalidPageToken {
+		t.Errorf("ListBacklinks with another entry's token = %v, want ErrInvalidPageToken", err)
+	}
+	if _, _, err := a.ListBacklinks(ctx, "moss", -1, ""); err != ErrInvalidPageSize {
+		t.Errorf("ListBacklinks with a negative page size = %v, want ErrInvalidPageSize", err)
+	}
+	if _, _, err := a.ListBacklinks(userContext("user-2"), "moss", 2, ""); err != ErrUnauthorized {
+		t.Errorf("ListBacklinks by another user = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestListUnlinkedMentions(t *testing.T) {
+	repo := newFakeRepo(
+		&models.Entry{ID: "moss", UserID: "user-1", Title: "Moss", Content: "Grows.", Aliases: []string{"Bryophyte"}, GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "a", UserID: "user-1", Title: "A", Content: "Moss and more moss.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "b", UserID: "user-1", Title: "B", Content: "Mossy stones.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "c", UserID: "user-1", Title: "C", Content: "`moss` in code.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "d", UserID: "user-1", Title: "D", Content: "A bryophyte.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "e", UserID: "user-1", Title: "E", Content: "Moss again.", GrowthStage: models.GrowthStageSeed},
+	)
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+
+	first, token, err := a.ListUnlinkedMentions(ctx, "moss", 2, "")
+	if err != nil {
+		t.Fatalf("ListUnlinkedMentions = %v", err)
+	}
+	if got := mentionPositions(first); !reflect.DeepEqual(got, []string{"a:0-4", "a:14-18", "d:2-11"}) {
+		t.Errorf("first page = %q, want both mentions in a and the alias in d", got)
+	}
+	if token == "" {
+		t.Fatal("first page has no page token")
+	}
+
+	second, next, err := a.ListUnlinkedMentions(ctx, "moss", 2, token)
+	if err != nil {
+		t.Fatalf("ListUnlinkedMentions(page 2) = %v", err)
+	}
+	if got := mentionPositions(second); !reflect.DeepEqual(got, []string{"e:0-4"}) || next != "" {
+		t.Errorf("second page = %q, token %q, want e only and no token", got, next)
+	}
+
+	if _, _, err := a.ListUnlinkedMentions(ctx, "a", 2, token); err != ErrInvalidPageToken {
+		t.Errorf("ListUnlinkedMentions with another entry's token = %v, want ErrInvalidPageToken", err)
+	}
+}
+
+func mentionPositions(mentions []*models.Mention) []string {
+	var positions []string
+	for _, m := range mentions {
+		positions = append(positions, m.Source.ID+":"+strconv.Itoa(m.Start)+"-"+strconv.Itoa(m.End))
+	}
+	return positions
+}
+
+func TestLinkMention(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetID   string
+		sourceID   string
+		start      int
+		version    int64
+		wantLinked []string
+		wantErr    error
+	}{
+		{"title", "moss", "ferns", 7, 0, []string{"ferns", "moss", "7", "11"}, nil},
+		{"alias", "moss", "ferns", 15, 2, []string{"ferns", "moss", "15", "24"}, nil},
+		{"not at a mention", "moss", "ferns", 8, 0, nil, ErrMentionNotFound},
+		{"inside a link", "moss", "ferns", 34, 0, nil, ErrMentionNotFound},
+		{"itself", "moss", "moss", 0, 0, nil, ErrMentionNotFound},
+		{"stale version", "moss", "ferns", 7, 1, nil, &VersionConflictError{}},
+		{"other user's source", "moss", "algae", 0, 0, nil, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(
+				&models.Entry{ID: "moss", UserID: "user-1", Title: "Moss", Content: "Moss grows.", Aliases: []string{"Bryophyte"}, Version: 1, GrowthStage: models.GrowthStageSeed},
+				&models.Entry{ID: "ferns", UserID: "user-1", Title: "Ferns", Content: "Unlike moss, a bryophyte, ferns [[Moss]]", Version: 2, GrowthStage: models.GrowthStageSeed},
+				&models.Entry{ID: "algae", UserID: "user-2", Title: "Algae", Content: "Moss", Version: 1, GrowthStage: models.GrowthStageSeed},
+			)
+
+			updated, err := newTestApp(repo).LinkMention(userContext("user-1"), tt.targetID, tt.sourceID, tt.start, tt.version)
+			var conflict *VersionConflictError
+			if _, wantConflict := tt.wantErr.(*VersionConflictError); wantConflict {
+				if !errors.As(err, &conflict) || conflict.Current.Version != 2 {
+					t.Fatalf("LinkMention = %v, want a *VersionConflictError at version 2", err)
+				}
+			} else if err != tt.wantErr {
+				t.Fatalf("LinkMention = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(repo.linked, tt.wantLinked) {
+				t.Errorf("repository LinkMention calls = %q, want %q", repo.linked, tt.wantLinked)
+			}
+			if err == nil && updated.Version != 3 {
+				t.Errorf("LinkMention returned version %d, want 3", updated.Version)
+			}
+		})
+	}
+}
+
+func TestMentionsOfTitlesWithLinkSyntax(t *testing.T) {
+	for _, title := range []string{"Rock|Paper", "C#", "Moss]]"} {
+		t.Run(title, func(t *testing.T) {
+			repo := newFakeRepo(
+				&models.Entry{ID: "target", UserID: "user-1", Title: title, Content: "Grows.", Version: 1, GrowthStage: models.GrowthStageSeed},
+				&models.Entry{ID: "source", UserID: "user-1", Title: "Notes", Content: "Notes on " + title + " today.", Version: 1, GrowthStage: models.GrowthStageSeed},
+			)
+			a := newTestApp(repo)
+			ctx := userContext("user-1")
+
+			mentions, _, err := a.ListUnlinkedMentions(ctx, "target", 10, "")
+			if err != nil {
+				t.Fatalf("ListUnlinkedMentions = %v", err)
+			}
+			want := "source:9-" + strconv.Itoa(9+len(title))
+			if got := mentionPositions(mentions); !reflect.DeepEqual(got, []string{want}) {
+				t.Fatalf("mentions = %q, want %q", got, want)
+			}
+
+			if _, err := a.LinkMention(ctx, "target", "source", 9, 1); err != nil {
+				t.Fatalf("LinkMention = %v", err)
+			}
+			if wantLinked := []string{"source", "target", "9", strconv.Itoa(9 + len(title))}; !reflect.DeepEqual(repo.linked, wantLinked) {
+				t.Errorf("repository LinkMention calls = %q, want %q", repo.linked, wantLinked)
+			}
+		})
+	}
+}
+
+func TestLinkCounts(t *testing.T) {
+	repo := newFakeRepo(
+		&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed},
+		&models.Entry{ID: "entry-2", UserID: "user-1", Title: "Ferns", Content: "Like [[Moss]].", GrowthStage: models.GrowthStageSeed},
+	)
+	repo.backlinks["entry-1"] = []*linkModels.Link{{SourceEntryID: "entry-2", TargetEntryID: "entry-1", UserID: "user-1"}}
+	a := newTestApp(repo)
+	ctx := userContext("user-1")
+
+	got, err := a.GetEntry(ctx, "entry-1")
+	if err != nil || got.LinkCount != 0 || got.BacklinkCount != 1 {
+		t.Errorf("GetEntry = %+v, %v, want 0 links and 1 backlink", got, err)
+	}
+
+	entries, _, err := a.ListEntries(ctx, models.ListQuery{}, "")
+	if err != nil {
+		t.Fatalf("ListEntries = %v", err)
+	}
+	counts := map[string][2]int64{}
+	for _, e := range entries {
+		counts[e.ID] = [2]int64{e.LinkCount, e.BacklinkCount}
+	}
+	if want := map[string][2]int64{"entry-1": {0, 1}, "entry-2": {1, 0}}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("ListEntries link counts = %v, want %v", counts, want)
+	}
+}
+
+func TestPromoteEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		stage     models.GrowthStage
+		reason    string
+		version   int64
+		wantStage models.GrowthStage // stored afterwards
+		wantErr   error
+	}{
+		{"grow one stage", userContext("user-1"), models.GrowthStageBloom, " ready ", 0, models.GrowthStageBloom, nil},
+		{"cut back", userContext("user-1"), models.GrowthStageSeed, "", 4, models.GrowthStageSeed, nil},
+		{"skip a stage", userContext("user-1"), models.GrowthStageEvergreen, "", 0, models.GrowthStageSprout, &TransitionError{
+			From: models.GrowthStageSprout, To: models.GrowthStageEvergreen,
+			Allowed: []models.GrowthStage{models.GrowthStageSeed, models.GrowthStageBloom},
+		}},
+		{"same stage", userContext("user-1"), models.GrowthStageSprout, "", 0, models.GrowthStageSprout, ErrSameGrowthStage},
+		{"unknown stage", userContext("user-1"), "tree", "", 0, models.GrowthStageSprout, ErrInvalidGrowthStage},
+		{"long reason", userContext("user-1"), models.GrowthStageBloom, strings.Repeat("é", maxReasonLength+1), 0, models.GrowthStageSprout, ErrInvalidReason},
+		{"other user", userContext("user-2"), models.GrowthStageBloom, "", 0, models.GrowthStageSprout, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSprout, Version: 4})
+
+			updated, transition, err := newTestApp(repo).PromoteEntry(tt.ctx, "entry-1", tt.stage, tt.reason, tt.version)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("PromoteEntry = %v, want %v", err, tt.wantErr)
+			}
+			if got := repo.entries["entry-1"].GrowthStage; got != tt.wantStage {
+				t.Errorf("stored stage = %s, want %s", got, tt.wantStage)
+			}
+			if err != nil {
+				if len(repo.transitions) != 0 {
+					t.Errorf("recorded %d transitions for a failed promotion", len(repo.transitions))
+				}
+				return
+			}
+			want := &models.StageTransition{EntryID: "entry-1", UserID: "user-1", From: models.GrowthStageSprout, To: tt.stage, Reason: strings.TrimSpace(tt.reason)}
+			if !reflect.DeepEqual(transition, want) || !reflect.DeepEqual(repo.transitions, []*models.StageTransition{want}) {
+				t.Errorf("transition = %+v, recorded %+v, want %+v", transition, repo.transitions, want)
+			}
+			if updated.GrowthStage != tt.stage || updated.Version != 5 {
+				t.Errorf("PromoteEntry = %+v, want stage %s at version 5", updated, tt.stage)
+			}
+		})
+	}
+
+	repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSprout, Version: 4})
+	_, _, err := newTestApp(repo).PromoteEntry(userContext("user-1"), "entry-1", models.GrowthStageBloom, "", 3)
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.Current.Version != 4 {
+		t.Errorf("PromoteEntry with a stale version = %v, want a *VersionConflictError at version 4", err)
+	}
+}
+
+func TestStageChangesThroughEdits(t *testing.T) {
+	tokenCtx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: "user-1", TokenID: "token-1"})
+	bloom, evergreen := models.GrowthStageBloom, models.GrowthStageEvergreen
+	tests := []struct {
+		name           string
+		edit           func(App) error
+		wantTransition *models.StageTransition
+		wantErr        bool
+	}{
+		{
+			"update ignores the stage",
+			func(a App) error {
+				updated, err := a.UpdateEntry(tokenCtx, &models.Entry{ID: "entry-1", Title: "Mosses", Content: "Grows.", GrowthStage: bloom}, false)
+				if err == nil && updated.GrowthStage != models.GrowthStageSprout {
+					return fmt.Errorf("stage = %q, want it kept at %q", updated.GrowthStage, models.GrowthStageSprout)
+				}
+				return err
+			},
+			nil,
+			false,
+		},
+		{
+			"patch",
+			func(a App) error {
+				_, err := a.PatchEntry(tokenCtx, "entry-1", models.Patch{GrowthStage: &bloom}, false)
+				return err
+			},
+			&models.StageTransition{EntryID: "entry-1", UserID: "user-1", TokenID: "token-1", From: models.GrowthStageSprout, To: bloom},
+			false,
+		},
+		{
+			"patch skipping a stage",
+			func(a App) error {
+				_, err := a.PatchEntry(tokenCtx, "entry-1", models.Patch{GrowthStage: &evergreen}, false)
+				return err
+			},
+			nil,
+			true,
+		},
+		{
+			"restore revision",
+			func(a App) error {
+				_, err := a.RestoreRevision(tokenCtx, "entry-1", 1)
+				return err
+			},
+			&models.StageTransition{EntryID: "entry-1", UserID: "user-1", TokenID: "token-1", From: models.GrowthStageSprout, To: models.GrowthStageSeed, Reason: "restored revision 1"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSprout, Version: 2})
+			repo.revisions["entry-1"] = []*models.Revision{{EntryID: "entry-1", Number: 1, Title: "Moss", Content: "Grows.", GrowthStage: models.GrowthStageSeed}}
+
+			err := tt.edit(newTestApp(repo))
+			var transitionErr *TransitionError
+			if tt.wantErr != errors.As(err, &transitionErr) || (!tt.wantErr && err != nil) {
+				t.Fatalf("edit = %v, want a *TransitionError: %v", err, tt.wantErr)
+			}
+			var want []*models.StageTransition
+			if tt.wantTransition != nil {
+				want = []*models.StageTransition{tt.wantTransition}
+			}
+			if !reflect.DeepEqual(repo.transitions, want) {
+				t.Errorf("recorded transitions %+v, want %+v", repo.transitions, want)
+			}
+		})
+	}
+}
+
+func TestRestoreEntryNameTaken(t *testing.T) {
+	trashedAt := time.Now().UTC()
+	repo := newFakeRepo(
+		&models.Entry{ID: "entry-1", UserID: "user-1", Title: "Moss", Content: "Grows.", Aliases: []string{"Bryophyte"}, GrowthStage: models.GrowthStageSeed, DeletedAt: &trashedAt},
+		&models.Entry{ID: "entry-2", UserID: "user-1", Title: "Bryophyte", Content: "Took the alias.", GrowthStage: models.GrowthStageSeed},
+	)
+	a := newTestApp(repo)
+
+	_, err := a.RestoreEntry(userContext("user-1"), "entry-1")
+	if want := (&NameTakenError{Name: "Bryophyte", EntryID: "entry-2"}); !reflect.DeepEqual(err, want) {
+		t.Fatalf("RestoreEntry = %v, want %v", err, want)
+	}
+	if repo.entries["entry-1"].DeletedAt == nil {
+		t.Error("RestoreEntry took the entry out of the trash despite the clash")
+	}
+}
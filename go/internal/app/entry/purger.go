@@ -0,0 +1,48 @@
+package entry
+
+import (
+	"context"
+	"log"
+	"time"
+
+	entryRepo "moss/go/internal/repository/entry"
+)
+
+// Purger permanently deletes entries that have been in the trash for longer
+// than a retention window.
+type Purger struct {
+	repo      entryRepo.Repository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurger(repo entryRepo.Repository, retention time.Duration, interval time.Duration) *Purger {
+	return &Purger{repo: repo, retention: retention, interval: interval}
+}
+
+// Run purges expired trash every interval until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	purged, err := p.repo.PurgeTrashedBefore(ctx, time.Now().UTC().Add(-p.retention))
+	if err != nil {
+		log.Printf("Failed to purge trash: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d entries from the trash", purged)
+	}
+}
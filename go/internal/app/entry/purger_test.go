@@ -0,0 +1,35 @@
+package entry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "moss/go/internal/models/entry"
+)
+
+func TestPurgerPurgesExpiredTrash(t *testing.T) {
+	old := time.Now().UTC().Add(-31 * 24 * time.Hour)
+	recent := time.Now().UTC().Add(-time.Hour)
+	repo := newFakeRepo(
+		&models.Entry{ID: "old", UserID: "user-1", Title: "Old", Content: "Gone.", DeletedAt: &old},
+		&models.Entry{ID: "recent", UserID: "user-1", Title: "Recent", Content: "Kept.", DeletedAt: &recent},
+		&models.Entry{ID: "live", UserID: "user-1", Title: "Live", Content: "Kept."},
+	)
+	retention := 30 * 24 * time.Hour
+
+	before := time.Now().UTC()
+	NewPurger(repo, retention, time.Hour).purge(context.Background())
+
+	if want := before.Add(-retention); repo.purgeCutoff.Before(want) || repo.purgeCutoff.After(time.Now().UTC().Add(-retention)) {
+		t.Errorf("purged before %v, want now minus the retention (%v)", repo.purgeCutoff, want)
+	}
+	if _, ok := repo.entries["old"]; ok {
+		t.Error("kept an entry trashed before the retention window")
+	}
+	for _, id := range []string{"recent", "live"} {
+		if _, ok := repo.entries[id]; !ok {
+			t.Errorf("purged %s", id)
+		}
+	}
+}
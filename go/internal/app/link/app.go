@@ -3,63 +3,490 @@ package link
 import (
 	"context"
 	"errors"
+	"time"
 
+	"moss/go/internal/auth"
+	entryModels "moss/go/internal/models/entry"
 	models "moss/go/internal/models/link"
+	entryRepo "moss/go/internal/repository/entry"
 	linkRepo "moss/go/internal/repository/link"
+	"moss/go/internal/tagexpr"
 )
 
 var (
-	ErrInvalidLink  = errors.New("invalid link")
-	ErrUnauthorized = errors.New("unauthorized access")
+	ErrInvalidLink      = errors.New("invalid link")
+	ErrUnauthorized     = errors.New("unauthorized access")
+	ErrEntryNotFound    = entryRepo.ErrEntryNotFound // Also returned for entries in the trash
+	ErrInvalidRelation  = errors.New("invalid relation name")
+	ErrUnknownRelation  = errors.New("relation is not in the caller's vocabulary")
+	ErrRelationExists   = errors.New("relation name already in use")
+	ErrRelationNotFound = errors.New("relation not found")
+	ErrRelationInUse    = errors.New("relation is used by links")
+
+	ErrInvalidDepth         = errors.New("depth must be between 1 and 4")
+	ErrInvalidDirection     = errors.New("invalid direction")
+	ErrInvalidGrowthStage   = errors.New("invalid growth stage")
+	ErrInvalidTagExpression = errors.New("invalid tag expression")
+	ErrInvalidLimit         = errors.New("limit must not be negative")
+	ErrInvalidPathDepth     = errors.New("max depth must be between 1 and 6")
+	ErrPathSearchTimeout    = errors.New("path search took too long; try a smaller max depth")
 )
 
+const (
+	maxDepth = 4
+
+	defaultMaxNodes = 200
+	maxMaxNodes     = 1000
+	defaultMaxEdges = 1000
+	maxMaxEdges     = 5000
+
+	// Exports are files to download, so they may be larger than graph views.
+	maxExportNodes = 10000
+	maxExportEdges = 50000
+
+	defaultPathDepth = 4
+	maxPathDepth     = 6
+	maxAlternatives  = 10
+
+	// pathSearchBudget bounds how long FindPaths may keep the database busy.
+	pathSearchBudget = 2 * time.Second
+)
+
+// App methods act on behalf of the Principal carried in ctx (see package auth).
+// The List and Count methods only consider links with the given relation if
+// relation is non-nil; an empty relation selects plain links.
+//
+// Links are stored under the relation's Name. Where a method takes a relation,
+// its inverse name may be given instead and means the link the other way round:
+// "A has-part B" is the link "B part-of A", and listing A's outgoing "has-part"
+// links lists the "part-of" links into A. Links are returned as stored.
 type App interface {
 	CreateLink(ctx context.Context, l *models.Link) (*models.Link, error)
-	DeleteLink(ctx context.Context, sourceID string, targetID string) error
-	ListLinksBySource(ctx context.Context, sourceID string) ([]*models.Link, error)
-	ListLinksByTarget(ctx context.Context, targetID string) ([]*models.Link, error)
-	CountLinksBySource(ctx context.Context, sourceID string) (int64, error)
-	CountLinksByTarget(ctx context.Context, targetID string) (int64, error)
+	DeleteLink(ctx context.Context, sourceID string, targetID string, relation string) error
+	ListLinksBySource(ctx context.Context, sourceID string, relation *string) ([]*models.Link, error)
+	ListLinksByTarget(ctx context.Context, targetID string, relation *string) ([]*models.Link, error)
+	CountLinksBySource(ctx context.Context, sourceID string, relation *string) (int64, error)
+	CountLinksByTarget(ctx context.Context, targetID string, relation *string) (int64, error)
+	// ListPendingLinks lists the caller's unresolved wiki-links, only those
+	// from sourceID if it is non-empty.
+	ListPendingLinks(ctx context.Context, sourceID string) ([]*models.PendingLink, error)
+
+	// CreateRelation adds a relation to the caller's vocabulary. An empty
+	// inverse makes the relation symmetric. Names and inverse names share one
+	// namespace, so each one means a single thing.
+	CreateRelation(ctx context.Context, name, inverse string) (*models.Relation, error)
+	ListRelations(ctx context.Context) ([]*models.Relation, error)
+	// DeleteRelation removes a relation from the caller's vocabulary,
+	// or returns ErrRelationInUse while links still have it.
+	DeleteRelation(ctx context.Context, name string) error
+
+	// GetNeighborhood returns the entries within q.Depth hops of an entry and the
+	// links between them. A zero depth, direction or limit takes its default.
+	GetNeighborhood(ctx context.Context, q models.NeighborhoodQuery) (*models.Graph, error)
+	// FindPaths returns the shortest path between two entries and up to
+	// alternatives more, shortest first; none if they are not connected within
+	// q.MaxDepth hops. q.MaxPaths is ignored. ErrPathSearchTimeout is returned
+	// if the search runs out of time.
+	FindPaths(ctx context.Context, q models.PathQuery, alternatives int) ([]*models.Path, error)
+	// GardenInsights computes metrics over the caller's whole link graph, with up
+	// to top entries in each ranking. Results are cached until the graph changes.
+	GardenInsights(ctx context.Context, top int) (*models.Insights, error)
+	// ExportGraph returns the part of the caller's link graph that q selects,
+	// with node attributes for export. A zero depth or direction takes its
+	// default, as in GetNeighborhood; the limits in q are ignored.
+	ExportGraph(ctx context.Context, q models.ExportQuery) (*models.Graph, error)
 }
 
 type app struct {
-	repo linkRepo.Repository
+	repo     linkRepo.Repository
+	entries  entryRepo.Repository
+	insights *insightsCache
 }
 
-func NewApp(repo linkRepo.Repository) App {
-	return &app{repo: repo}
+func NewApp(repo linkRepo.Repository, entries entryRepo.Repository) App {
+	return &app{repo: repo, entries: entries, insights: newInsightsCache()}
 }
 
-// CreateLink validates and creates a new Link.
+// CreateLink validates and creates a new Link between two entries owned by the caller.
+// A typed link names a relation from the caller's vocabulary. A link given by
+// the relation's InverseName is created the other way round under its Name.
 func (a *app) CreateLink(ctx context.Context, l *models.Link) (*models.Link, error) {
+	userID, err := a.authorizeEntry(ctx, l.SourceEntryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.authorizeEntry(ctx, l.TargetEntryID); err != nil {
+		return nil, err
+	}
+	l.UserID = userID
+
 	if err := l.Validate(); err != nil {
 		return nil, ErrInvalidLink
 	}
+	if l.Relation != "" {
+		name, ok := models.NormalizeRelation(l.Relation)
+		if !ok {
+			return nil, ErrInvalidRelation
+		}
+		relation, flipped, err := a.resolveRelation(ctx, userID, name)
+		if err != nil {
+			return nil, err
+		}
+		l.Relation = relation
+		if flipped {
+			l.SourceEntryID, l.TargetEntryID = l.TargetEntryID, l.SourceEntryID
+		}
+	}
+
 	return a.repo.CreateEntryLink(ctx, l)
 }
 
 // DeleteLink checks ownership then deletes the Link.
-func (a *app) DeleteLink(ctx context.Context, sourceID string, targetID string) error {
-	// Ensure that the link exists and that userID matches.
-	_, err := a.repo.ListBySource(ctx, sourceID)
+func (a *app) DeleteLink(ctx context.Context, sourceID string, targetID string, relation string) error {
+	userID, err := a.authorizeEntry(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	name, flipped, err := a.resolveFilter(ctx, userID, &relation)
+	if err != nil {
+		return err
+	}
+	if flipped {
+		sourceID, targetID = targetID, sourceID
+	}
+
+	return a.repo.DeleteEntryLink(ctx, sourceID, targetID, *name)
+}
+
+func (a *app) ListLinksBySource(ctx context.Context, sourceID string, relation *string) ([]*models.Link, error) {
+	userID, err := a.authorizeEntry(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	relation, flipped, err := a.resolveFilter(ctx, userID, relation)
+	if err != nil {
+		return nil, err
+	}
+	if flipped {
+		return a.repo.ListByTarget(ctx, sourceID, relation)
+	}
+	return a.repo.ListBySource(ctx, sourceID, relation)
+}
+
+func (a *app) ListLinksByTarget(ctx context.Context, targetID string, relation *string) ([]*models.Link, error) {
+	userID, err := a.authorizeEntry(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	relation, flipped, err := a.resolveFilter(ctx, userID, relation)
+	if err != nil {
+		return nil, err
+	}
+	if flipped {
+		return a.repo.ListBySource(ctx, targetID, relation)
+	}
+	return a.repo.ListByTarget(ctx, targetID, relation)
+}
+
+func (a *app) CountLinksBySource(ctx context.Context, sourceID string, relation *string) (int64, error) {
+	userID, err := a.authorizeEntry(ctx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	relation, flipped, err := a.resolveFilter(ctx, userID, relation)
+	if err != nil {
+		return 0, err
+	}
+	if flipped {
+		return a.repo.CountByTarget(ctx, sourceID, relation)
+	}
+	return a.repo.CountBySource(ctx, sourceID, relation)
+}
+
+func (a *app) CountLinksByTarget(ctx context.Context, targetID string, relation *string) (int64, error) {
+	userID, err := a.authorizeEntry(ctx, targetID)
+	if err != nil {
+		return 0, err
+	}
+	relation, flipped, err := a.resolveFilter(ctx, userID, relation)
+	if err != nil {
+		return 0, err
+	}
+	if flipped {
+		return a.repo.CountBySource(ctx, targetID, relation)
+	}
+	return a.repo.CountByTarget(ctx, targetID, relation)
+}
+
+func (a *app) ListPendingLinks(ctx context.Context, sourceID string) ([]*models.PendingLink, error) {
+	if sourceID == "" {
+		userID, err := auth.UserIDFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return a.repo.ListPending(ctx, userID, "")
+	}
+
+	userID, err := a.authorizeEntry(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	return a.repo.ListPending(ctx, userID, sourceID)
+}
+
+func (a *app) CreateRelation(ctx context.Context, name, inverse string) (*models.Relation, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, ok := models.NormalizeRelation(name)
+	if !ok {
+		return nil, ErrInvalidRelation
+	}
+	if inverse != "" {
+		if inverse, ok = models.NormalizeRelation(inverse); !ok {
+			return nil, ErrInvalidRelation
+		}
+		if inverse == name {
+			inverse = ""
+		}
+	}
+
+	relations, err := a.repo.ListRelations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if findRelation(relations, name) != nil || (inverse != "" && findRelation(relations, inverse) != nil) {
+		return nil, ErrRelationExists
+	}
+
+	created, err := a.repo.CreateRelation(ctx, &models.Relation{
+		UserID:      userID,
+		Name:        name,
+		InverseName: inverse,
+	})
+	if errors.Is(err, linkRepo.ErrRelationExists) {
+		return nil, ErrRelationExists
+	}
+	return created, err
+}
+
+func (a *app) ListRelations(ctx context.Context) ([]*models.Relation, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return a.repo.ListRelations(ctx, userID)
+}
+
+func (a *app) DeleteRelation(ctx context.Context, name string) error {
+	userID, err := auth.UserIDFromContext(ctx)
 	if err != nil {
 		return err
 	}
-	return a.repo.DeleteEntryLink(ctx, sourceID, targetID)
+
+	name, ok := models.NormalizeRelation(name)
+	if !ok {
+		return ErrRelationNotFound
+	}
+	inUse, err := a.repo.RelationInUse(ctx, userID, []string{name})
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrRelationInUse
+	}
+
+	err = a.repo.DeleteRelation(ctx, userID, name)
+	if errors.Is(err, linkRepo.ErrRelationNotFound) {
+		return ErrRelationNotFound
+	}
+	return err
+}
+
+func (a *app) GetNeighborhood(ctx context.Context, q models.NeighborhoodQuery) (*models.Graph, error) {
+	userID, err := a.authorizeEntry(ctx, q.EntryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Depth, q.Direction, err = walkDefaults(q.Depth, q.Direction); err != nil {
+		return nil, err
+	}
+	var flipped bool
+	if q.Relation, flipped, err = a.resolveFilter(ctx, userID, q.Relation); err != nil {
+		return nil, err
+	}
+	if flipped {
+		q.Direction = q.Direction.Reverse()
+	}
+	if err := checkEntryFilters(q.GrowthStage, q.Tags); err != nil {
+		return nil, err
+	}
+	if q.MaxNodes < 0 || q.MaxEdges < 0 {
+		return nil, ErrInvalidLimit
+	}
+	q.MaxNodes = limit(q.MaxNodes, defaultMaxNodes, maxMaxNodes)
+	q.MaxEdges = limit(q.MaxEdges, defaultMaxEdges, maxMaxEdges)
+
+	return a.entries.Neighborhood(ctx, userID, q)
+}
+
+func (a *app) FindPaths(ctx context.Context, q models.PathQuery, alternatives int) ([]*models.Path, error) {
+	userID, err := a.authorizeEntry(ctx, q.FromEntryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.authorizeEntry(ctx, q.ToEntryID); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case q.MaxDepth == 0:
+		q.MaxDepth = defaultPathDepth
+	case q.MaxDepth < 0 || q.MaxDepth > maxPathDepth:
+		return nil, ErrInvalidPathDepth
+	}
+	if alternatives < 0 {
+		return nil, ErrInvalidLimit
+	}
+	q.MaxPaths = 1 + min(alternatives, maxAlternatives)
+
+	searchCtx, cancel := context.WithTimeout(ctx, pathSearchBudget)
+	defer cancel()
+	paths, err := a.entries.FindPaths(searchCtx, userID, q)
+	if err != nil && ctx.Err() == nil && errors.Is(searchCtx.Err(), context.DeadlineExceeded) {
+		return nil, ErrPathSearchTimeout
+	}
+	return paths, err
+}
+
+func (a *app) ExportGraph(ctx context.Context, q models.ExportQuery) (*models.Graph, error) {
+	var userID string
+	var err error
+	if q.RootEntryID != "" {
+		if userID, err = a.authorizeEntry(ctx, q.RootEntryID); err != nil {
+			return nil, err
+		}
+		if q.Depth, q.Direction, err = walkDefaults(q.Depth, q.Direction); err != nil {
+			return nil, err
+		}
+	} else if userID, err = auth.UserIDFromContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkEntryFilters(q.GrowthStage, q.Tags); err != nil {
+		return nil, err
+	}
+	q.MaxNodes, q.MaxEdges = maxExportNodes, maxExportEdges
+
+	return a.entries.ExportGraph(ctx, userID, q)
+}
+
+// walkDefaults validates the depth and direction of a walk through the link
+// graph, filling in the defaults for zero values.
+func walkDefaults(depth int, direction models.Direction) (int, models.Direction, error) {
+	switch {
+	case depth == 0:
+		depth = 1
+	case depth < 0 || depth > maxDepth:
+		return 0, "", ErrInvalidDepth
+	}
+	switch direction {
+	case "":
+		direction = models.DirectionBoth
+	case models.DirectionOut, models.DirectionIn, models.DirectionBoth:
+	default:
+		return 0, "", ErrInvalidDirection
+	}
+	return depth, direction, nil
+}
+
+// checkEntryFilters validates the optional growth stage and tag expression
+// that entries in a graph are filtered by.
+func checkEntryFilters(growthStage, tags string) error {
+	if growthStage != "" && !entryModels.GrowthStage(growthStage).Valid() {
+		return ErrInvalidGrowthStage
+	}
+	if tags != "" {
+		if _, err := tagexpr.Parse(tags); err != nil {
+			return ErrInvalidTagExpression
+		}
+	}
+	return nil
+}
+
+// limit returns n, or def if n is zero, capped at max.
+func limit(n, def, max int) int {
+	if n == 0 {
+		return def
+	}
+	return min(n, max)
 }
 
-func (a *app) ListLinksBySource(ctx context.Context, sourceID string) ([]*models.Link, error) {
-	return a.repo.ListBySource(ctx, sourceID)
+// findRelation returns the relation in relations that is called name,
+// either way round, or nil if there is none.
+func findRelation(relations []*models.Relation, name string) *models.Relation {
+	for _, r := range relations {
+		if r.Name == name || r.InverseName == name {
+			return r
+		}
+	}
+	return nil
 }
 
-func (a *app) ListLinksByTarget(ctx context.Context, targetID string) ([]*models.Link, error) {
-	return a.repo.ListByTarget(ctx, targetID)
+// resolveRelation looks up the normalized relation name in userID's
+// vocabulary and returns the relation's Name. It reports true if name is the
+// relation's InverseName, so that links read from target to source. It
+// returns ErrUnknownRelation if the vocabulary has no relation called name.
+func (a *app) resolveRelation(ctx context.Context, userID, name string) (string, bool, error) {
+	relations, err := a.repo.ListRelations(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+	r := findRelation(relations, name)
+	if r == nil {
+		return "", false, ErrUnknownRelation
+	}
+	return r.Name, r.Name != name, nil
 }
 
-func (a *app) CountLinksBySource(ctx context.Context, sourceID string) (int64, error) {
-	return a.repo.CountBySource(ctx, sourceID)
+// resolveFilter resolves an optional relation filter like resolveRelation.
+// Both nil and "" are passed through: they mean "any relation" and "plain
+// links only". A name not in the vocabulary is kept, and matches no links.
+func (a *app) resolveFilter(ctx context.Context, userID string, relation *string) (*string, bool, error) {
+	if relation == nil || *relation == "" {
+		return relation, false, nil
+	}
+	name, ok := models.NormalizeRelation(*relation)
+	if !ok {
+		return nil, false, ErrInvalidRelation
+	}
+	canonical, flipped, err := a.resolveRelation(ctx, userID, name)
+	if errors.Is(err, ErrUnknownRelation) {
+		return &name, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &canonical, flipped, nil
 }
 
-func (a *app) CountLinksByTarget(ctx context.Context, targetID string) (int64, error) {
-	return a.repo.CountByTarget(ctx, targetID)
+// authorizeEntry verifies that entryID belongs to the authenticated caller
+// and returns the caller's user ID. Links can only be created between a user's
+// own entries, so owning an entry implies owning every link that touches it.
+func (a *app) authorizeEntry(ctx context.Context, entryID string) (string, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := a.entries.GetByID(ctx, entryID)
+	if err != nil {
+		return "", err
+	}
+	if entry.UserID != userID {
+		return "", ErrUnauthorized
+	}
+	return userID, nil
 }
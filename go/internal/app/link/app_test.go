@@ -0,0 +1,443 @@
+package link
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"moss/go/internal/auth"
+	entryModels "moss/go/internal/models/entry"
+	models "moss/go/internal/models/link"
+	entryRepo "moss/go/internal/repository/entry"
+	linkRepo "moss/go/internal/repository/link"
+)
+
+// fakeLinks keeps links and relations in memory. Methods a test does not
+// exercise panic through the embedded nil Repository.
+type fakeLinks struct {
+	linkRepo.Repository
+	links     []*models.Link
+	relations []*models.Relation
+	relation  *string // filter of the last List call
+	byTarget  bool    // whether the last List call was ListByTarget
+}
+
+func (f *fakeLinks) CreateEntryLink(_ context.Context, l *models.Link) (*models.Link, error) {
+	stored := *l
+	f.links = append(f.links, &stored)
+	copied := stored
+	return &copied, nil
+}
+
+func (f *fakeLinks) ListBySource(_ context.Context, sourceID string, relation *string) ([]*models.Link, error) {
+	f.relation, f.byTarget = relation, false
+	var links []*models.Link
+	for _, l := range f.links {
+		if l.SourceEntryID == sourceID && (relation == nil || l.Relation == *relation) {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeLinks) ListByTarget(_ context.Context, targetID string, relation *string) ([]*models.Link, error) {
+	f.relation, f.byTarget = relation, true
+	var links []*models.Link
+	for _, l := range f.links {
+		if l.TargetEntryID == targetID && (relation == nil || l.Relation == *relation) {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeLinks) CreateRelation(_ context.Context, r *models.Relation) (*models.Relation, error) {
+	for _, existing := range f.relations {
+		if existing.UserID == r.UserID && existing.Name == r.Name {
+			return nil, linkRepo.ErrRelationExists
+		}
+	}
+	stored := *r
+	f.relations = append(f.relations, &stored)
+	copied := stored
+	return &copied, nil
+}
+
+func (f *fakeLinks) ListRelations(_ context.Context, userID string) ([]*models.Relation, error) {
+	var relations []*models.Relation
+	for _, r := range f.relations {
+		if r.UserID == userID {
+			relations = append(relations, r)
+		}
+	}
+	return relations, nil
+}
+
+func (f *fakeLinks) DeleteRelation(_ context.Context, userID, name string) error {
+	for i, r := range f.relations {
+		if r.UserID == userID && r.Name == name {
+			f.relations = append(f.relations[:i], f.relations[i+1:]...)
+			return nil
+		}
+	}
+	return linkRepo.ErrRelationNotFound
+}
+
+func (f *fakeLinks) RelationInUse(_ context.Context, userID string, names []string) (bool, error) {
+	for _, l := range f.links {
+		for _, name := range names {
+			if l.UserID == userID && l.Relation == name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// fakeEntries serves GetByID from a fixed set of entries and records graph queries.
+type fakeEntries struct {
+	entryRepo.Repository
+	entries      map[string]*entryModels.Entry
+	neighborhood *models.NeighborhoodQuery // argument of the last Neighborhood call
+	paths        *models.PathQuery         // argument of the last FindPaths call
+	graph        *models.Graph             // returned by LinkGraph
+	fingerprint  string                    // returned by LinkGraphFingerprint
+	graphReads   int                       // LinkGraph calls
+	export       *models.ExportQuery       // argument of the last ExportGraph call
+	exportUser   string                    // user of the last ExportGraph call
+}
+
+func newFakeEntries(entries ...*entryModels.Entry) *fakeEntries {
+	f := &fakeEntries{entries: make(map[string]*entryModels.Entry)}
+	for _, e := range entries {
+		f.entries[e.ID] = e
+	}
+	return f
+}
+
+func (f *fakeEntries) GetByID(_ context.Context, id string) (*entryModels.Entry, error) {
+	e, ok := f.entries[id]
+	if !ok {
+		return nil, entryRepo.ErrEntryNotFound
+	}
+	copied := *e
+	return &copied, nil
+}
+
+func (f *fakeEntries) Neighborhood(_ context.Context, _ string, q models.NeighborhoodQuery) (*models.Graph, error) {
+	f.neighborhood = &q
+	return &models.Graph{}, nil
+}
+
+// FindPaths records q and fails with the context's error once it is done.
+func (f *fakeEntries) FindPaths(ctx context.Context, _ string, q models.PathQuery) ([]*models.Path, error) {
+	f.paths = &q
+	return nil, ctx.Err()
+}
+
+func (f *fakeEntries) LinkGraph(_ context.Context, _ string) (*models.Graph, error) {
+	f.graphReads++
+	return f.graph, nil
+}
+
+func (f *fakeEntries) LinkGraphFingerprint(_ context.Context, _ string) (string, error) {
+	return f.fingerprint, nil
+}
+
+func (f *fakeEntries) ExportGraph(_ context.Context, userID string, q models.ExportQuery) (*models.Graph, error) {
+	f.export, f.exportUser = &q, userID
+	return &models.Graph{}, nil
+}
+
+func userContext(userID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID, SessionID: "session-" + userID})
+}
+
+// gardenEntries are two entries of user-1 and one of user-2.
+func gardenEntries() *fakeEntries {
+	return newFakeEntries(
+		&entryModels.Entry{ID: "moss", UserID: "user-1", Title: "Moss"},
+		&entryModels.Entry{ID: "ferns", UserID: "user-1", Title: "Ferns"},
+		&entryModels.Entry{ID: "algae", UserID: "user-2", Title: "Algae"},
+	)
+}
+
+func TestCreateRelation(t *testing.T) {
+	tests := []struct {
+		name, inverse string
+		want          *models.Relation
+		wantErr       error
+	}{
+		{"Supports", "supported-BY", &models.Relation{UserID: "user-1", Name: "supports", InverseName: "supported-by"}, nil},
+		{"contradicts", "", &models.Relation{UserID: "user-1", Name: "contradicts"}, nil},
+		{"related", "Related", &models.Relation{UserID: "user-1", Name: "related"}, nil},
+		{"cites", "", nil, ErrRelationExists},
+		{"cited-by", "", nil, ErrRelationExists},
+		{"quotes", "cited-by", nil, ErrRelationExists},
+		{"has space", "", nil, ErrInvalidRelation},
+		{"quotes", "quoted by", nil, ErrInvalidRelation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.inverse, func(t *testing.T) {
+			repo := &fakeLinks{relations: []*models.Relation{{UserID: "user-1", Name: "cites", InverseName: "cited-by"}}}
+			a := NewApp(repo, gardenEntries())
+
+			got, err := a.CreateRelation(userContext("user-1"), tt.name, tt.inverse)
+			if err != tt.wantErr {
+				t.Fatalf("CreateRelation = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateRelation = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateLinkRelation(t *testing.T) {
+	tests := []struct {
+		name         string
+		link         models.Link
+		wantRelation string
+		wantErr      error
+	}{
+		{"plain", models.Link{SourceEntryID: "moss", TargetEntryID: "ferns"}, "", nil},
+		{"typed", models.Link{SourceEntryID: "moss", TargetEntryID: "ferns", Relation: " Cites"}, "cites", nil},
+		{"inverse name", models.Link{SourceEntryID: "moss", TargetEntryID: "ferns", Relation: "cited-by"}, "cites", nil},
+		{"unknown", models.Link{SourceEntryID: "moss", TargetEntryID: "ferns", Relation: "refutes"}, "", ErrUnknownRelation},
+		{"invalid", models.Link{SourceEntryID: "moss", TargetEntryID: "ferns", Relation: "re futes"}, "", ErrInvalidRelation},
+		{"other user's target", models.Link{SourceEntryID: "moss", TargetEntryID: "algae"}, "", ErrUnauthorized},
+		{"missing source", models.Link{SourceEntryID: "lichen", TargetEntryID: "moss"}, "", entryRepo.ErrEntryNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLinks{relations: []*models.Relation{{UserID: "user-1", Name: "cites", InverseName: "cited-by"}}}
+			a := NewApp(repo, gardenEntries())
+
+			wantSource := tt.link.SourceEntryID
+			if tt.link.Relation == "cited-by" {
+				wantSource = tt.link.TargetEntryID
+			}
+			got, err := a.CreateLink(userContext("user-1"), &tt.link)
+			if err != tt.wantErr {
+				t.Fatalf("CreateLink = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (got.Relation != tt.wantRelation || got.UserID != "user-1") {
+				t.Errorf("CreateLink = %+v, want relation %q owned by user-1", got, tt.wantRelation)
+			}
+			if err == nil && got.SourceEntryID != wantSource {
+				t.Errorf("CreateLink stored the link from %s, want from %s", got.SourceEntryID, wantSource)
+			}
+		})
+	}
+}
+
+func TestListLinksRelationFilter(t *testing.T) {
+	plain, typed, upper, inverse, unknown, invalid := "", "cites", "CITES", "cited-by", "refutes", "not valid"
+	tests := []struct {
+		name         string
+		relation     *string
+		want         *string
+		wantByTarget bool
+		wantErr      error
+	}{
+		{"any", nil, nil, false, nil},
+		{"plain only", &plain, &plain, false, nil},
+		{"typed", &typed, &typed, false, nil},
+		{"normalized", &upper, &typed, false, nil},
+		{"inverse name", &inverse, &typed, true, nil},
+		{"not in vocabulary", &unknown, &unknown, false, nil},
+		{"invalid", &invalid, nil, false, ErrInvalidRelation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLinks{relations: []*models.Relation{{UserID: "user-1", Name: "cites", InverseName: "cited-by"}}}
+			a := NewApp(repo, gardenEntries())
+
+			if _, err := a.ListLinksBySource(userContext("user-1"), "moss", tt.relation); err != tt.wantErr {
+				t.Fatalf("ListLinksBySource = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(repo.relation, tt.want) {
+				t.Errorf("filtered by %v, want %v", repo.relation, tt.want)
+			}
+			if repo.byTarget != tt.wantByTarget {
+				t.Errorf("listed links by target = %v, want %v", repo.byTarget, tt.wantByTarget)
+			}
+		})
+	}
+}
+
+func TestDeleteRelation(t *testing.T) {
+	repo := &fakeLinks{
+		relations: []*models.Relation{
+			{UserID: "user-1", Name: "cites", InverseName: "cited-by"},
+			{UserID: "user-1", Name: "contradicts"},
+		},
+		links: []*models.Link{{SourceEntryID: "moss", TargetEntryID: "ferns", UserID: "user-1", Relation: "cites"}},
+	}
+	a := NewApp(repo, gardenEntries())
+	ctx := userContext("user-1")
+
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"cites", ErrRelationInUse},
+		{"refutes", ErrRelationNotFound},
+		{"not valid", ErrRelationNotFound},
+		{"Contradicts", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := a.DeleteRelation(ctx, tt.name); err != tt.want {
+				t.Errorf("DeleteRelation = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(repo.relations) != 1 || repo.relations[0].Name != "cites" {
+		t.Errorf("relations left: %+v, want only cites", repo.relations)
+	}
+}
+
+func TestGetNeighborhood(t *testing.T) {
+	cites, upper, inverse, invalid := "cites", "Cites", "Cited-By", "not valid"
+	tests := []struct {
+		name    string
+		q       models.NeighborhoodQuery
+		want    *models.NeighborhoodQuery
+		wantErr error
+	}{
+		{
+			"defaults",
+			models.NeighborhoodQuery{EntryID: "moss"},
+			&models.NeighborhoodQuery{EntryID: "moss", Depth: 1, Direction: models.DirectionBoth, MaxNodes: defaultMaxNodes, MaxEdges: defaultMaxEdges},
+			nil,
+		},
+		{
+			"caps limits and normalizes relation",
+			models.NeighborhoodQuery{EntryID: "moss", Depth: 4, Direction: models.DirectionIn, Relation: &upper, MaxNodes: 5000, MaxEdges: 9000},
+			&models.NeighborhoodQuery{EntryID: "moss", Depth: 4, Direction: models.DirectionIn, Relation: &cites, MaxNodes: maxMaxNodes, MaxEdges: maxMaxEdges},
+			nil,
+		},
+		{
+			"inverse name reverses direction",
+			models.NeighborhoodQuery{EntryID: "moss", Direction: models.DirectionOut, Relation: &inverse},
+			&models.NeighborhoodQuery{EntryID: "moss", Depth: 1, Direction: models.DirectionIn, Relation: &cites, MaxNodes: defaultMaxNodes, MaxEdges: defaultMaxEdges},
+			nil,
+		},
+		{"too deep", models.NeighborhoodQuery{EntryID: "moss", Depth: 5}, nil, ErrInvalidDepth},
+		{"negative depth", models.NeighborhoodQuery{EntryID: "moss", Depth: -1}, nil, ErrInvalidDepth},
+		{"direction", models.NeighborhoodQuery{EntryID: "moss", Direction: "up"}, nil, ErrInvalidDirection},
+		{"relation", models.NeighborhoodQuery{EntryID: "moss", Relation: &invalid}, nil, ErrInvalidRelation},
+		{"growth stage", models.NeighborhoodQuery{EntryID: "moss", GrowthStage: "tree"}, nil, ErrInvalidGrowthStage},
+		{"tags", models.NeighborhoodQuery{EntryID: "moss", Tags: "#a AND ("}, nil, ErrInvalidTagExpression},
+		{"negative limit", models.NeighborhoodQuery{EntryID: "moss", MaxEdges: -1}, nil, ErrInvalidLimit},
+		{"other user's entry", models.NeighborhoodQuery{EntryID: "algae"}, nil, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := gardenEntries()
+			a := NewApp(&fakeLinks{relations: []*models.Relation{{UserID: "user-1", Name: "cites", InverseName: "cited-by"}}}, entries)
+
+			if _, err := a.GetNeighborhood(userContext("user-1"), tt.q); err != tt.wantErr {
+				t.Fatalf("GetNeighborhood = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(entries.neighborhood, tt.want) {
+				t.Errorf("Neighborhood query = %+v, want %+v", entries.neighborhood, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPaths(t *testing.T) {
+	tests := []struct {
+		name         string
+		q            models.PathQuery
+		alternatives int
+		want         *models.PathQuery
+		wantErr      error
+	}{
+		{
+			"defaults",
+			models.PathQuery{FromEntryID: "moss", ToEntryID: "ferns"},
+			0,
+			&models.PathQuery{FromEntryID: "moss", ToEntryID: "ferns", MaxDepth: defaultPathDepth, MaxPaths: 1},
+			nil,
+		},
+		{
+			"caps alternatives",
+			models.PathQuery{FromEntryID: "moss", ToEntryID: "ferns", Undirected: true, MaxDepth: 6, MaxPaths: 3},
+			50,
+			&models.PathQuery{FromEntryID: "moss", ToEntryID: "ferns", Undirected: true, MaxDepth: 6, MaxPaths: 1 + maxAlternatives},
+			nil,
+		},
+		{"too deep", models.PathQuery{FromEntryID: "moss", ToEntryID: "ferns", MaxDepth: 7}, 0, nil, ErrInvalidPathDepth},
+		{"negative alternatives", models.PathQuery{FromEntryID: "moss", ToEntryID: "ferns"}, -1, nil, ErrInvalidLimit},
+		{"other user's target", models.PathQuery{FromEntryID: "moss", ToEntryID: "algae"}, 0, nil, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := gardenEntries()
+			a := NewApp(&fakeLinks{}, entries)
+
+			if _, err := a.FindPaths(userContext("user-1"), tt.q, tt.alternatives); err != tt.wantErr {
+				t.Fatalf("FindPaths = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(entries.paths, tt.want) {
+				t.Errorf("FindPaths query = %+v, want %+v", entries.paths, tt.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(userContext("user-1"))
+	entries := gardenEntries()
+	a := NewApp(&fakeLinks{}, entries)
+	cancel()
+	// Authorization reads through the fake without checking ctx, so the
+	// search itself is the first to see the cancellation.
+	if _, err := a.FindPaths(ctx, models.PathQuery{FromEntryID: "moss", ToEntryID: "ferns"}, 0); err != context.Canceled {
+		t.Errorf("FindPaths after the caller gave up = %v, want context.Canceled rather than a timeout", err)
+	}
+}
+
+func TestExportGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		q       models.ExportQuery
+		want    *models.ExportQuery
+		wantErr error
+	}{
+		{
+			"whole garden ignores walk",
+			models.ExportQuery{Depth: 9, Direction: "up", GrowthStage: "seed", MaxNodes: 5},
+			&models.ExportQuery{Depth: 9, Direction: "up", GrowthStage: "seed", MaxNodes: maxExportNodes, MaxEdges: maxExportEdges},
+			nil,
+		},
+		{
+			"from a root",
+			models.ExportQuery{RootEntryID: "moss"},
+			&models.ExportQuery{RootEntryID: "moss", Depth: 1, Direction: models.DirectionBoth, MaxNodes: maxExportNodes, MaxEdges: maxExportEdges},
+			nil,
+		},
+		{"root too deep", models.ExportQuery{RootEntryID: "moss", Depth: 5}, nil, ErrInvalidDepth},
+		{"root direction", models.ExportQuery{RootEntryID: "moss", Direction: "up"}, nil, ErrInvalidDirection},
+		{"growth stage", models.ExportQuery{GrowthStage: "tree"}, nil, ErrInvalidGrowthStage},
+		{"tags", models.ExportQuery{Tags: "#a OR"}, nil, ErrInvalidTagExpression},
+		{"other user's root", models.ExportQuery{RootEntryID: "algae"}, nil, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := gardenEntries()
+			a := NewApp(&fakeLinks{}, entries)
+
+			if _, err := a.ExportGraph(userContext("user-1"), tt.q); err != tt.wantErr {
+				t.Fatalf("ExportGraph = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(entries.export, tt.want) {
+				t.Errorf("ExportGraph query = %+v, want %+v", entries.export, tt.want)
+			}
+			if tt.want != nil && entries.exportUser != "user-1" {
+				t.Errorf("exported the graph of %q, want user-1", entries.exportUser)
+			}
+		})
+	}
+}
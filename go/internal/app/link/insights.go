@@ -0,0 +1,172 @@
+package link
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"moss/go/internal/auth"
+	"moss/go/internal/graphstats"
+	models "moss/go/internal/models/link"
+)
+
+const (
+	defaultInsightsTop = 10
+	maxInsightsTop     = 100
+
+	// maxCachedInsights bounds how many users' insights are kept in memory.
+	maxCachedInsights = 1000
+
+	pageRankDamping     = 0.85
+	pageRankIterations  = 100
+	pageRankTolerance   = 1e-9
+	communityIterations = 20
+)
+
+func (a *app) GardenInsights(ctx context.Context, top int) (*models.Insights, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case top < 0:
+		return nil, ErrInvalidLimit
+	case top == 0:
+		top = defaultInsightsTop
+	case top > maxInsightsTop:
+		top = maxInsightsTop
+	}
+
+	// Read the fingerprint first: if the graph changes while it is being read,
+	// the next call sees a new fingerprint and computes the insights again.
+	fingerprint, err := a.entries.LinkGraphFingerprint(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	insights := a.insights.get(userID, fingerprint)
+	if insights == nil {
+		graph, err := a.entries.LinkGraph(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		insights = computeInsights(graph, maxInsightsTop)
+		a.insights.put(userID, fingerprint, insights)
+	}
+
+	trimmed := *insights
+	trimmed.TopByInDegree = insights.TopByInDegree[:min(top, len(insights.TopByInDegree))]
+	trimmed.TopByOutDegree = insights.TopByOutDegree[:min(top, len(insights.TopByOutDegree))]
+	trimmed.TopByPageRank = insights.TopByPageRank[:min(top, len(insights.TopByPageRank))]
+	return &trimmed, nil
+}
+
+// computeInsights computes the metrics over graph, with up to top entries in each ranking.
+func computeInsights(graph *models.Graph, top int) *models.Insights {
+	index := make(map[string]int, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		index[n.EntryID] = i
+	}
+	edges := make([][2]int, 0, len(graph.Edges))
+	for _, l := range graph.Edges {
+		source, ok1 := index[l.SourceEntryID]
+		target, ok2 := index[l.TargetEntryID]
+		if ok1 && ok2 {
+			edges = append(edges, [2]int{source, target})
+		}
+	}
+	g := graphstats.New(len(graph.Nodes), edges)
+
+	insights := &models.Insights{
+		EntryCount: len(graph.Nodes),
+		LinkCount:  len(edges),
+		Orphans:    []*models.Node{},
+		DeadEnds:   []*models.Node{},
+		ComputedAt: time.Now().UTC(),
+	}
+	inDegree := make([]float64, g.N)
+	outDegree := make([]float64, g.N)
+	for v, n := range graph.Nodes {
+		inDegree[v], outDegree[v] = float64(len(g.In[v])), float64(len(g.Out[v]))
+		switch {
+		case inDegree[v] == 0 && outDegree[v] == 0:
+			insights.Orphans = append(insights.Orphans, n)
+		case outDegree[v] == 0:
+			insights.DeadEnds = append(insights.DeadEnds, n)
+		}
+	}
+	insights.TopByInDegree = rank(graph.Nodes, inDegree, top)
+	insights.TopByOutDegree = rank(graph.Nodes, outDegree, top)
+	insights.TopByPageRank = rank(graph.Nodes, g.PageRank(pageRankDamping, pageRankIterations, pageRankTolerance), top)
+	insights.Components = clusters(graph.Nodes, g.Components())
+	insights.Communities = clusters(graph.Nodes, g.Communities(communityIterations))
+	return insights
+}
+
+// rank returns up to top nodes with a positive score, highest first.
+func rank(nodes []*models.Node, scores []float64, top int) []*models.RankedNode {
+	ranked := []*models.RankedNode{}
+	for v, score := range scores {
+		if score > 0 {
+			ranked = append(ranked, &models.RankedNode{Node: nodes[v], Score: score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	return ranked[:min(top, len(ranked))]
+}
+
+// clusters maps groups of node indexes to nodes, leaving out groups of one.
+func clusters(nodes []*models.Node, groups [][]int) [][]*models.Node {
+	result := [][]*models.Node{}
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		members := make([]*models.Node, len(group))
+		for i, v := range group {
+			members[i] = nodes[v]
+		}
+		result = append(result, members)
+	}
+	return result
+}
+
+// insightsCache keeps each user's latest insights with the fingerprint of the
+// link graph they were computed from.
+type insightsCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedInsights
+}
+
+type cachedInsights struct {
+	fingerprint string
+	insights    *models.Insights
+}
+
+func newInsightsCache() *insightsCache {
+	return &insightsCache{entries: make(map[string]cachedInsights)}
+}
+
+// get returns userID's cached insights if they were computed from the graph with fingerprint.
+func (c *insightsCache) get(userID, fingerprint string) *models.Insights {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[userID]
+	if !ok || cached.fingerprint != fingerprint {
+		return nil
+	}
+	return cached.insights
+}
+
+func (c *insightsCache) put(userID, fingerprint string, insights *models.Insights) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[userID]; !ok && len(c.entries) >= maxCachedInsights {
+		// Evict an arbitrary user; they recompute on their next request.
+		for id := range c.entries {
+			delete(c.entries, id)
+			break
+		}
+	}
+	c.entries[userID] = cachedInsights{fingerprint: fingerprint, insights: insights}
+}
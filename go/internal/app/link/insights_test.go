@@ -0,0 +1,87 @@
+package link
+
+import (
+	"reflect"
+	"testing"
+
+	models "moss/go/internal/models/link"
+)
+
+// hubGraph is a -> hub <- b, hub -> c, with d linked to nothing.
+func hubGraph() *models.Graph {
+	nodes := []*models.Node{{EntryID: "a"}, {EntryID: "b"}, {EntryID: "c"}, {EntryID: "d"}, {EntryID: "hub"}}
+	return &models.Graph{
+		Nodes: nodes,
+		Edges: []*models.Link{
+			{SourceEntryID: "a", TargetEntryID: "hub"},
+			{SourceEntryID: "b", TargetEntryID: "hub"},
+			{SourceEntryID: "hub", TargetEntryID: "c"},
+			{SourceEntryID: "hub", TargetEntryID: "gone"}, // to an entry no longer in the graph
+		},
+	}
+}
+
+func nodeIDs(nodes []*models.Node) []string {
+	ids := []string{}
+	for _, n := range nodes {
+		ids = append(ids, n.EntryID)
+	}
+	return ids
+}
+
+func TestComputeInsights(t *testing.T) {
+	insights := computeInsights(hubGraph(), 2)
+
+	if insights.EntryCount != 5 || insights.LinkCount != 3 {
+		t.Errorf("counts = %d entries, %d links, want 5 and 3", insights.EntryCount, insights.LinkCount)
+	}
+	if got := nodeIDs(insights.Orphans); !reflect.DeepEqual(got, []string{"d"}) {
+		t.Errorf("Orphans = %q, want d", got)
+	}
+	if got := nodeIDs(insights.DeadEnds); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("DeadEnds = %q, want c", got)
+	}
+	if len(insights.TopByInDegree) != 2 || insights.TopByInDegree[0].Node.EntryID != "hub" || insights.TopByInDegree[0].Score != 2 {
+		t.Errorf("TopByInDegree = %+v, want hub first with 2 and at most 2 entries", insights.TopByInDegree)
+	}
+	if len(insights.Components) != 1 || len(insights.Components[0]) != 4 {
+		t.Errorf("Components = %v, want one of 4 entries without the orphan", insights.Components)
+	}
+}
+
+func TestGardenInsightsCache(t *testing.T) {
+	entries := gardenEntries()
+	entries.graph, entries.fingerprint = hubGraph(), "v1"
+	a := NewApp(&fakeLinks{}, entries)
+	ctx := userContext("user-1")
+
+	first, err := a.GardenInsights(ctx, 1)
+	if err != nil {
+		t.Fatalf("GardenInsights = %v", err)
+	}
+	if len(first.TopByInDegree) != 1 {
+		t.Errorf("TopByInDegree has %d entries, want top 1", len(first.TopByInDegree))
+	}
+	second, err := a.GardenInsights(ctx, 3)
+	if err != nil {
+		t.Fatalf("GardenInsights = %v", err)
+	}
+	if entries.graphReads != 1 {
+		t.Errorf("read the graph %d times for an unchanged fingerprint, want once", entries.graphReads)
+	}
+	if len(second.TopByInDegree) != 2 || !second.ComputedAt.Equal(first.ComputedAt) {
+		t.Errorf("cached insights = %+v, want both entries with incoming links, computed once", second)
+	}
+
+	entries.fingerprint = "v2"
+	if _, err := a.GardenInsights(ctx, 3); err != nil {
+		t.Fatalf("GardenInsights = %v", err)
+	}
+	if entries.graphReads != 2 {
+		t.Errorf("read the graph %d times after the fingerprint changed, want twice", entries.graphReads)
+	}
+
+	if _, err := a.GardenInsights(ctx, -1); err != ErrInvalidLimit {
+		t.Errorf("GardenInsights(-1) = %v, want ErrInvalidLimit", err)
+	}
+}
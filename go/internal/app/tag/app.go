@@ -0,0 +1,118 @@
+package tag
+
+import (
+	"context"
+	"errors"
+
+	"moss/go/internal/auth"
+	"moss/go/internal/hashtag"
+	models "moss/go/internal/models/tag"
+	entryRepo "moss/go/internal/repository/entry"
+	tagRepo "moss/go/internal/repository/tag"
+)
+
+var (
+	ErrInvalidTag  = errors.New("invalid tag name")
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// App methods act on behalf of the Principal carried in ctx (see package auth).
+// Tags are taken from the #tags in entries' content, so renaming and merging
+// them rewrites those entries, all in one transaction.
+type App interface {
+	// ListTags returns the caller's tags with their entry counts, ordered by name.
+	ListTags(ctx context.Context) ([]*models.Tag, error)
+	// RenameTag renames a tag and the tags nested under it, returning how many
+	// entries were rewritten. It fails with ErrTagExists if newName is in use.
+	RenameTag(ctx context.Context, name string, newName string) (int64, error)
+	// MergeTags renames each of names, and the tags nested under them, to into,
+	// which may already exist. It returns how many entries were rewritten.
+	MergeTags(ctx context.Context, names []string, into string) (int64, error)
+}
+
+type app struct {
+	repo    tagRepo.Repository
+	entries entryRepo.Repository
+}
+
+func NewApp(repo tagRepo.Repository, entries entryRepo.Repository) App {
+	return &app{repo: repo, entries: entries}
+}
+
+func (a *app) ListTags(ctx context.Context) ([]*models.Tag, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.repo.ListByUser(ctx, userID)
+}
+
+func (a *app) RenameTag(ctx context.Context, name string, newName string) (int64, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	from, err := a.existingTag(ctx, userID, name)
+	if err != nil {
+		return 0, err
+	}
+	to, ok := hashtag.Normalize(newName)
+	if !ok {
+		return 0, ErrInvalidTag
+	}
+	if to == from {
+		return 0, nil
+	}
+	if _, err := a.repo.GetByName(ctx, userID, to); err == nil {
+		return 0, ErrTagExists
+	} else if !errors.Is(err, tagRepo.ErrTagNotFound) {
+		return 0, err
+	}
+
+	return a.entries.Retag(ctx, userID, []string{from}, to)
+}
+
+func (a *app) MergeTags(ctx context.Context, names []string, into string) (int64, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	to, ok := hashtag.Normalize(into)
+	if !ok {
+		return 0, ErrInvalidTag
+	}
+	var from []string
+	for _, name := range names {
+		tag, err := a.existingTag(ctx, userID, name)
+		if err != nil {
+			return 0, err
+		}
+		if tag != to {
+			from = append(from, tag)
+		}
+	}
+	if len(from) == 0 {
+		return 0, nil
+	}
+
+	return a.entries.Retag(ctx, userID, from, to)
+}
+
+// existingTag normalizes name and checks that the user has a tag by that name.
+func (a *app) existingTag(ctx context.Context, userID string, name string) (string, error) {
+	normalized, ok := hashtag.Normalize(name)
+	if !ok {
+		return "", ErrInvalidTag
+	}
+	if _, err := a.repo.GetByName(ctx, userID, normalized); err != nil {
+		if errors.Is(err, tagRepo.ErrTagNotFound) {
+			return "", ErrTagNotFound
+		}
+		return "", err
+	}
+	return normalized, nil
+}
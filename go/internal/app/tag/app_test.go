@@ -0,0 +1,105 @@
+package tag
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/tag"
+	entryRepo "moss/go/internal/repository/entry"
+	tagRepo "moss/go/internal/repository/tag"
+)
+
+// fakeTags is a tagRepo.Repository over a fixed set of tag names.
+type fakeTags map[string]bool
+
+func (f fakeTags) ListByUser(_ context.Context, userID string) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	for name := range f {
+		tags = append(tags, &models.Tag{UserID: userID, Name: name})
+	}
+	return tags, nil
+}
+
+func (f fakeTags) GetByName(_ context.Context, userID, name string) (*models.Tag, error) {
+	if !f[name] {
+		return nil, tagRepo.ErrTagNotFound
+	}
+	return &models.Tag{UserID: userID, Name: name}, nil
+}
+
+// fakeEntries records Retag calls. Other entry repository methods are not used by this package.
+type fakeEntries struct {
+	entryRepo.Repository
+	retagged [][]string // from tags of each Retag call, followed by to
+}
+
+func (f *fakeEntries) Retag(_ context.Context, _ string, from []string, to string) (int64, error) {
+	f.retagged = append(f.retagged, append(append([]string(nil), from...), to))
+	return int64(len(from)), nil
+}
+
+var userCtx = auth.WithPrincipal(context.Background(), &auth.Principal{UserID: "user-1", SessionID: "session-1"})
+
+func TestRenameTag(t *testing.T) {
+	tests := []struct {
+		name         string
+		from, to     string
+		wantRetagged [][]string
+		wantErr      error
+	}{
+		{"rename", "#Lang", "#Languages", [][]string{{"lang", "languages"}}, nil},
+		{"nested", "lang/go", "golang", [][]string{{"lang/go", "golang"}}, nil},
+		{"same name", "lang", "#LANG", nil, nil},
+		{"missing", "rust", "rustlang", nil, ErrTagNotFound},
+		{"taken", "lang", "books", nil, ErrTagExists},
+		{"invalid new name", "lang", "#", nil, ErrInvalidTag},
+		{"invalid name", "", "lang", nil, ErrInvalidTag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := &fakeEntries{}
+			a := NewApp(fakeTags{"lang": true, "lang/go": true, "books": true}, entries)
+
+			_, err := a.RenameTag(userCtx, tt.from, tt.to)
+			if err != tt.wantErr {
+				t.Fatalf("RenameTag = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(entries.retagged, tt.wantRetagged) {
+				t.Errorf("Retag calls = %q, want %q", entries.retagged, tt.wantRetagged)
+			}
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		names        []string
+		into         string
+		wantRetagged [][]string
+		wantErr      error
+	}{
+		{"into existing", []string{"golang", "#Go"}, "lang/go", [][]string{{"golang", "go", "lang/go"}}, nil},
+		{"into new", []string{"golang"}, "gopher", [][]string{{"golang", "gopher"}}, nil},
+		{"skips into", []string{"golang", "lang/go"}, "lang/go", [][]string{{"golang", "lang/go"}}, nil},
+		{"only into", []string{"lang/go"}, "lang/go", nil, nil},
+		{"missing", []string{"golang", "rust"}, "lang/go", nil, ErrTagNotFound},
+		{"invalid into", []string{"golang"}, "", nil, ErrInvalidTag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := &fakeEntries{}
+			a := NewApp(fakeTags{"go": true, "golang": true, "lang/go": true}, entries)
+
+			_, err := a.MergeTags(userCtx, tt.names, tt.into)
+			if err != tt.wantErr {
+				t.Fatalf("MergeTags = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(entries.retagged, tt.wantRetagged) {
+				t.Errorf("Retag calls = %q, want %q", entries.retagged, tt.wantRetagged)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/token"
+	tokenRepo "moss/go/internal/repository/token"
+)
+
+// touchInterval bounds how often last-used time is written for a busy token.
+const touchInterval = time.Minute
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrSessionOnly  = errors.New("personal access tokens can only be managed from a login session")
+)
+
+type App interface {
+	// CreateToken stores a new token and returns it along with its secret,
+	// which is not retrievable afterwards.
+	CreateToken(ctx context.Context, t *models.Token) (*models.Token, string, error)
+	ListTokens(ctx context.Context) ([]*models.Token, error)
+	RevokeToken(ctx context.Context, id string) error
+}
+
+type app struct {
+	repo tokenRepo.Repository
+}
+
+func NewApp(repo tokenRepo.Repository) App {
+	return &app{repo: repo}
+}
+
+func (a *app) CreateToken(ctx context.Context, t *models.Token) (*models.Token, string, error) {
+	userID, err := a.sessionUserID(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := t.Validate(); err != nil {
+		return nil, "", ErrInvalidToken
+	}
+
+	opaque, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret := models.SecretPrefix + opaque
+
+	t.ID = uuid.NewString()
+	t.UserID = userID
+	t.TokenHash = auth.HashToken(secret)
+
+	created, err := a.repo.Create(ctx, t)
+	if err != nil {
+		return nil, "", err
+	}
+	return created, secret, nil
+}
+
+func (a *app) ListTokens(ctx context.Context) ([]*models.Token, error) {
+	userID, err := a.sessionUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.repo.ListByUser(ctx, userID)
+}
+
+func (a *app) RevokeToken(ctx context.Context, id string) error {
+	userID, err := a.sessionUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.repo.Revoke(ctx, id, userID)
+}
+
+// sessionUserID returns the caller's user ID, refusing callers that are
+// themselves authenticated with a personal access token.
+func (a *app) sessionUserID(ctx context.Context) (string, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return "", auth.ErrUnauthenticated
+	}
+	if p.TokenID != "" {
+		return "", ErrSessionOnly
+	}
+	return p.UserID, nil
+}
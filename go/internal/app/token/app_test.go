@@ -0,0 +1,159 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/token"
+	tokenRepo "moss/go/internal/repository/token"
+)
+
+// fakeRepo is an in-memory tokenRepo.Repository.
+type fakeRepo struct {
+	mu      sync.Mutex
+	tokens  map[string]*models.Token
+	touches int
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{tokens: make(map[string]*models.Token)}
+}
+
+func (r *fakeRepo) Create(_ context.Context, t *models.Token) (*models.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t.CreatedAt = time.Now().UTC()
+	stored := *t
+	r.tokens[t.ID] = &stored
+	copied := stored
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetByHash(_ context.Context, hash string) (*models.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == hash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, tokenRepo.ErrTokenNotFound
+}
+
+func (r *fakeRepo) ListByUser(_ context.Context, userID string) ([]*models.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []*models.Token
+	for _, t := range r.tokens {
+		if t.UserID == userID {
+			copied := *t
+			tokens = append(tokens, &copied)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeRepo) Revoke(_ context.Context, id string, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[id]
+	if !ok || t.UserID != userID || t.RevokedAt != nil {
+		return tokenRepo.ErrTokenNotFound
+	}
+	now := time.Now().UTC()
+	t.RevokedAt = &now
+	return nil
+}
+
+func (r *fakeRepo) Touch(_ context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.touches++
+	if t, ok := r.tokens[id]; ok {
+		t.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+var (
+	sessionCtx = auth.WithPrincipal(context.Background(), &auth.Principal{UserID: "user-1", SessionID: "session-1"})
+	tokenCtx   = auth.WithPrincipal(context.Background(), &auth.Principal{
+		UserID: "user-1", TokenID: "token-1", Scopes: []auth.Scope{auth.ScopeEntriesRead},
+	})
+)
+
+func TestCreateToken(t *testing.T) {
+	repo := newFakeRepo()
+	a := NewApp(repo)
+
+	created, secret, err := a.CreateToken(sessionCtx, &models.Token{
+		Name:   "backup",
+		Scopes: []auth.Scope{auth.ScopeEntriesRead},
+	})
+	if err != nil {
+		t.Fatalf("CreateToken = %v", err)
+	}
+	if !strings.HasPrefix(secret, models.SecretPrefix) {
+		t.Errorf("secret %q lacks prefix %q", secret, models.SecretPrefix)
+	}
+	if created.UserID != "user-1" || created.ID == "" {
+		t.Errorf("CreateToken = %+v, want a new token for user-1", created)
+	}
+	if stored := repo.tokens[created.ID]; stored.TokenHash != auth.HashToken(secret) {
+		t.Errorf("stored hash %q, want the hash of the secret", stored.TokenHash)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		token models.Token
+		want  error
+	}{
+		{"no name", sessionCtx, models.Token{Scopes: []auth.Scope{auth.ScopeEntriesRead}}, ErrInvalidToken},
+		{"no scopes", sessionCtx, models.Token{Name: "backup"}, ErrInvalidToken},
+		{"unknown scope", sessionCtx, models.Token{Name: "backup", Scopes: []auth.Scope{"entries:admin"}}, ErrInvalidToken},
+		{"expired", sessionCtx, models.Token{Name: "backup", Scopes: []auth.Scope{auth.ScopeEntriesRead}, ExpiresAt: &past}, ErrInvalidToken},
+		{"from a token", tokenCtx, models.Token{Name: "backup", Scopes: []auth.Scope{auth.ScopeEntriesRead}}, ErrSessionOnly},
+		{"unauthenticated", context.Background(), models.Token{Name: "backup", Scopes: []auth.Scope{auth.ScopeEntriesRead}}, auth.ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := a.CreateToken(tt.ctx, &tt.token); err != tt.want {
+				t.Errorf("CreateToken = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestManageTokensRequiresSession(t *testing.T) {
+	a := NewApp(newFakeRepo())
+
+	if _, err := a.ListTokens(tokenCtx); err != ErrSessionOnly {
+		t.Errorf("ListTokens = %v, want ErrSessionOnly", err)
+	}
+	if err := a.RevokeToken(tokenCtx, "token-1"); err != ErrSessionOnly {
+		t.Errorf("RevokeToken = %v, want ErrSessionOnly", err)
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	a := NewApp(newFakeRepo())
+	created, _, err := a.CreateToken(sessionCtx, &models.Token{Name: "backup", Scopes: []auth.Scope{auth.ScopeEntriesRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherCtx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: "user-2", SessionID: "session-2"})
+	if err := a.RevokeToken(otherCtx, created.ID); err != tokenRepo.ErrTokenNotFound {
+		t.Errorf("RevokeToken by another user = %v, want ErrTokenNotFound", err)
+	}
+	if err := a.RevokeToken(sessionCtx, created.ID); err != nil {
+		t.Errorf("RevokeToken = %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/token"
+	tokenRepo "moss/go/internal/repository/token"
+)
+
+type tokenAuthenticator struct {
+	repo tokenRepo.Repository
+	next auth.Authenticator
+}
+
+// NewAuthenticator returns an Authenticator that resolves personal access tokens
+// and delegates every other bearer token to next.
+func NewAuthenticator(repo tokenRepo.Repository, next auth.Authenticator) auth.Authenticator {
+	return &tokenAuthenticator{repo: repo, next: next}
+}
+
+func (a *tokenAuthenticator) Authenticate(ctx context.Context, bearer string) (*auth.Principal, error) {
+	if !strings.HasPrefix(bearer, models.SecretPrefix) {
+		return a.next.Authenticate(ctx, bearer)
+	}
+
+	t, err := a.repo.GetByHash(ctx, auth.HashToken(bearer))
+	if err != nil {
+		if errors.Is(err, tokenRepo.ErrTokenNotFound) {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !t.Active(now) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) > touchInterval {
+		if err := a.repo.Touch(ctx, t.ID, now); err != nil {
+			log.Printf("failed to record use of token %s: %v", t.ID, err)
+		}
+	}
+
+	return &auth.Principal{
+		UserID:  t.UserID,
+		TokenID: t.ID,
+		Scopes:  t.Scopes,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/token"
+)
+
+// nextAuthenticator stands in for the session authenticator.
+type nextAuthenticator struct{ calls int }
+
+func (n *nextAuthenticator) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	n.calls++
+	if token == "session-token" {
+		return &auth.Principal{UserID: "user-1", SessionID: "session-1"}, nil
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+func TestAuthenticator(t *testing.T) {
+	repo := newFakeRepo()
+	a := NewApp(repo)
+	created, secret, err := a.CreateToken(sessionCtx, &models.Token{
+		Name:   "backup",
+		Scopes: []auth.Scope{auth.ScopeEntriesRead, auth.ScopeLinksRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := &nextAuthenticator{}
+	authenticator := NewAuthenticator(repo, next)
+	ctx := context.Background()
+
+	p, err := authenticator.Authenticate(ctx, secret)
+	if err != nil {
+		t.Fatalf("Authenticate = %v", err)
+	}
+	if p.UserID != "user-1" || p.TokenID != created.ID || p.SessionID != "" || len(p.Scopes) != 2 {
+		t.Errorf("Authenticate = %+v, want token %s for user-1 with its scopes", p, created.ID)
+	}
+	if next.calls != 0 {
+		t.Error("Authenticate delegated a personal access token")
+	}
+
+	if _, err := authenticator.Authenticate(ctx, "session-token"); err != nil || next.calls != 1 {
+		t.Errorf("Authenticate(session token) = %v after %d delegated calls, want it delegated", err, next.calls)
+	}
+	if _, err := authenticator.Authenticate(ctx, models.SecretPrefix+"unknown"); err != auth.ErrInvalidToken {
+		t.Errorf("Authenticate(unknown) = %v, want ErrInvalidToken", err)
+	}
+
+	past := time.Now().Add(-time.Second)
+	repo.tokens[created.ID].ExpiresAt = &past
+	if _, err := authenticator.Authenticate(ctx, secret); err != auth.ErrInvalidToken {
+		t.Errorf("Authenticate(expired) = %v, want ErrInvalidToken", err)
+	}
+	repo.tokens[created.ID].ExpiresAt = nil
+
+	if err := a.RevokeToken(sessionCtx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticator.Authenticate(ctx, secret); err != auth.ErrInvalidToken {
+		t.Errorf("Authenticate(revoked) = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestAuthenticatorThrottlesTouch(t *testing.T) {
+	repo := newFakeRepo()
+	_, secret, err := NewApp(repo).CreateToken(sessionCtx, &models.Token{Name: "backup", Scopes: []auth.Scope{auth.ScopeEntriesRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := NewAuthenticator(repo, &nextAuthenticator{})
+
+	for i := 0; i < 3; i++ {
+		if _, err := authenticator.Authenticate(context.Background(), secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.touches != 1 {
+		t.Errorf("recorded use %d times in a burst, want once", repo.touches)
+	}
+}
@@ -0,0 +1,227 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/user"
+	userRepo "moss/go/internal/repository/user"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything past 72 bytes
+
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidUser        = errors.New("invalid user")
+	ErrInvalidPassword    = errors.New("password must be between 8 and 72 bytes")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidSession     = errors.New("invalid or expired session")
+	ErrSessionOnly        = errors.New("only a login session can log out; revoke personal access tokens instead")
+)
+
+// dummyHash is compared against when a login email is unknown,
+// so that response time does not reveal which emails are registered.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("moss-dummy-password"), bcrypt.DefaultCost)
+
+type App interface {
+	SignUp(ctx context.Context, u *models.User, password string) (*models.User, *models.SessionTokens, error)
+	Login(ctx context.Context, email string, password string) (*models.User, *models.SessionTokens, error)
+	RefreshSession(ctx context.Context, refreshToken string) (*models.SessionTokens, error)
+	Logout(ctx context.Context, allSessions bool) error
+	GetProfile(ctx context.Context) (*models.User, error)
+	UpdateProfile(ctx context.Context, displayName string, gardenSlug string) (*models.User, error)
+}
+
+type app struct {
+	repo   userRepo.Repository
+	signer *auth.TokenSigner
+}
+
+func NewApp(repo userRepo.Repository, signer *auth.TokenSigner) App {
+	return &app{repo: repo, signer: signer}
+}
+
+// SignUp registers a new user and starts a session for them.
+func (a *app) SignUp(ctx context.Context, u *models.User, password string) (*models.User, *models.SessionTokens, error) {
+	u.Email = models.NormalizeEmail(u.Email)
+	if err := u.Validate(); err != nil {
+		return nil, nil, ErrInvalidUser
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return nil, nil, ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.ID = uuid.NewString()
+	u.PasswordHash = string(hash)
+
+	created, err := a.repo.Create(ctx, u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := a.startSession(ctx, created.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, tokens, nil
+}
+
+// Login verifies the user's password and starts a new session.
+func (a *app) Login(ctx context.Context, email string, password string) (*models.User, *models.SessionTokens, error) {
+	u, err := a.repo.GetByEmail(ctx, models.NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, userRepo.ErrUserNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+			return nil, nil, ErrInvalidCredentials
+		}
+		return nil, nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	tokens, err := a.startSession(ctx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, tokens, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token.
+// The refresh token is rotated, so each one can only be used once; presenting
+// one that was already rotated away revokes its session, since the token must
+// have been copied.
+func (a *app) RefreshSession(ctx context.Context, refreshToken string) (*models.SessionTokens, error) {
+	hash := auth.HashToken(refreshToken)
+	session, err := a.repo.GetSessionByRefreshTokenHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, userRepo.ErrSessionNotFound) {
+			return nil, a.revokeReusedSession(ctx, hash)
+		}
+		return nil, err
+	}
+	if !session.Active(time.Now()) {
+		return nil, ErrInvalidSession
+	}
+
+	newRefreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	session, err = a.repo.RotateSession(ctx, session.ID, hash, auth.HashToken(newRefreshToken), time.Now().UTC().Add(refreshTokenTTL))
+	if err != nil {
+		if errors.Is(err, userRepo.ErrSessionNotFound) {
+			// Another request rotated the token, or revoked the session, first.
+			return nil, ErrInvalidSession
+		}
+		return nil, err
+	}
+
+	return a.issueTokens(session, newRefreshToken)
+}
+
+// revokeReusedSession revokes the session that rotated away the refresh token
+// with the given hash, if any, and returns ErrInvalidSession.
+func (a *app) revokeReusedSession(ctx context.Context, hash string) error {
+	session, err := a.repo.GetSessionByRetiredRefreshTokenHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, userRepo.ErrSessionNotFound) {
+			return ErrInvalidSession
+		}
+		return err
+	}
+	if err := a.repo.RevokeSession(ctx, session.ID); err != nil {
+		return err
+	}
+	return ErrInvalidSession
+}
+
+// Logout revokes the caller's current session, or all of their sessions.
+// Callers authenticated with a personal access token have no session to end.
+func (a *app) Logout(ctx context.Context, allSessions bool) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthenticated
+	}
+	if p.SessionID == "" {
+		return ErrSessionOnly
+	}
+
+	if allSessions {
+		return a.repo.RevokeUserSessions(ctx, p.UserID)
+	}
+	return a.repo.RevokeSession(ctx, p.SessionID)
+}
+
+func (a *app) GetProfile(ctx context.Context) (*models.User, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.repo.GetByID(ctx, userID)
+}
+
+func (a *app) UpdateProfile(ctx context.Context, displayName string, gardenSlug string) (*models.User, error) {
+	u, err := a.GetProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u.DisplayName = displayName
+	u.GardenSlug = gardenSlug
+	if err := u.Validate(); err != nil {
+		return nil, ErrInvalidUser
+	}
+
+	return a.repo.UpdateProfile(ctx, u)
+}
+
+// startSession persists a new session for userID and issues its tokens.
+func (a *app) startSession(ctx context.Context, userID string) (*models.SessionTokens, error) {
+	refreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := a.repo.CreateSession(ctx, &models.Session{
+		ID:               uuid.NewString(),
+		UserID:           userID,
+		RefreshTokenHash: auth.HashToken(refreshToken),
+		ExpiresAt:        time.Now().UTC().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.issueTokens(session, refreshToken)
+}
+
+func (a *app) issueTokens(session *models.Session, refreshToken string) (*models.SessionTokens, error) {
+	accessToken, accessExpiresAt, err := a.signer.Issue(session.UserID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SessionTokens{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,394 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/user"
+	userRepo "moss/go/internal/repository/user"
+)
+
+// fakeRepo is an in-memory userRepo.Repository.
+type fakeRepo struct {
+	mu       sync.Mutex
+	users    map[string]*models.User
+	sessions map[string]*models.Session
+	retired  map[string]string // Retired refresh token hash to session ID
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		users:    make(map[string]*models.User),
+		sessions: make(map[string]*models.Session),
+		retired:  make(map[string]string),
+	}
+}
+
+func (r *fakeRepo) Create(_ context.Context, u *models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, other := range r.users {
+		if other.Email == u.Email {
+			return nil, userRepo.ErrEmailTaken
+		}
+		if other.GardenSlug == u.GardenSlug {
+			return nil, userRepo.ErrGardenSlugTaken
+		}
+	}
+	stored := *u
+	r.users[u.ID] = &stored
+	return &stored, nil
+}
+
+func (r *fakeRepo) GetByID(_ context.Context, id string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, userRepo.ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, userRepo.ErrUserNotFound
+}
+
+func (r *fakeRepo) UpdateProfile(_ context.Context, u *models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[u.ID]
+	if !ok {
+		return nil, userRepo.ErrUserNotFound
+	}
+	stored.DisplayName, stored.GardenSlug = u.DisplayName, u.GardenSlug
+	copied := *stored
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateSession(_ context.Context, s *models.Session) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *s
+	r.sessions[s.ID] = &stored
+	copied := stored
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetSessionByID(_ context.Context, id string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, userRepo.ErrSessionNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetSessionByRefreshTokenHash(_ context.Context, hash string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sessions {
+		if s.RefreshTokenHash == hash {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, userRepo.ErrSessionNotFound
+}
+
+func (r *fakeRepo) GetSessionByRetiredRefreshTokenHash(_ context.Context, hash string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[r.retired[hash]]
+	if !ok {
+		return nil, userRepo.ErrSessionNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *fakeRepo) RotateSession(_ context.Context, id string, oldHash string, newHash string, expiresAt time.Time) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok || s.RefreshTokenHash != oldHash || !s.Active(time.Now()) {
+		return nil, userRepo.ErrSessionNotFound
+	}
+	s.RefreshTokenHash, s.ExpiresAt = newHash, expiresAt
+	r.retired[oldHash] = id
+	copied := *s
+	return &copied, nil
+}
+
+func (r *fakeRepo) RevokeSession(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok && s.RevokedAt == nil {
+		now := time.Now()
+		s.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeRepo) RevokeUserSessions(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func newTestApp(t *testing.T) (App, *fakeRepo, *auth.TokenSigner) {
+	t.Helper()
+	signer, err := auth.NewTokenSigner([]byte("0123456789abcdef0123456789abcdef"), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newFakeRepo()
+	return NewApp(repo, signer), repo, signer
+}
+
+// signUp registers ada@example.com with password "correct horse".
+func signUp(t *testing.T, a App) (*models.User, *models.SessionTokens) {
+	t.Helper()
+	u, tokens, err := a.SignUp(context.Background(), &models.User{
+		Email:       " Ada@Example.com",
+		DisplayName: "Ada",
+		GardenSlug:  "ada",
+	}, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u, tokens
+}
+
+// sessionContext returns a context authenticated as the session tokens belong to.
+func sessionContext(t *testing.T, signer *auth.TokenSigner, tokens *models.SessionTokens) context.Context {
+	t.Helper()
+	p, err := signer.Authenticate(context.Background(), tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.WithPrincipal(context.Background(), p)
+}
+
+func TestSignUp(t *testing.T) {
+	a, repo, _ := newTestApp(t)
+	u, tokens := signUp(t, a)
+
+	if u.Email != "ada@example.com" {
+		t.Errorf("Email = %q, want it normalized", u.Email)
+	}
+	if u.PasswordHash == "" || u.PasswordHash == "correct horse" {
+		t.Errorf("PasswordHash = %q, want a bcrypt hash", u.PasswordHash)
+	}
+	session, err := repo.GetSessionByID(context.Background(), tokens.SessionID)
+	if err != nil {
+		t.Fatalf("session %s was not stored: %v", tokens.SessionID, err)
+	}
+	if session.UserID != u.ID || session.RefreshTokenHash == tokens.RefreshToken {
+		t.Errorf("stored session = %+v, want one for %s holding a hash of the refresh token", session, u.ID)
+	}
+
+	tests := []struct {
+		name     string
+		user     models.User
+		password string
+		want     error
+	}{
+		{"short password", models.User{Email: "bo@example.com", DisplayName: "Bo", GardenSlug: "bo-garden"}, "short", ErrInvalidPassword},
+		{"long password", models.User{Email: "bo@example.com", DisplayName: "Bo", GardenSlug: "bo-garden"}, string(make([]byte, 73)), ErrInvalidPassword},
+		{"bad email", models.User{Email: "bo", DisplayName: "Bo", GardenSlug: "bo-garden"}, "correct horse", ErrInvalidUser},
+		{"bad slug", models.User{Email: "bo@example.com", DisplayName: "Bo", GardenSlug: "Bo Garden"}, "correct horse", ErrInvalidUser},
+		{"email taken", models.User{Email: "ADA@example.com", DisplayName: "Bo", GardenSlug: "bo-garden"}, "correct horse", userRepo.ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := a.SignUp(context.Background(), &tt.user, tt.password); !errors.Is(err, tt.want) {
+				t.Errorf("SignUp = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	a, _, _ := newTestApp(t)
+	u, _ := signUp(t, a)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		want     error
+	}{
+		{"correct", "ada@example.com", "correct horse", nil},
+		{"email case and spaces", "  ADA@example.COM ", "correct horse", nil},
+		{"wrong password", "ada@example.com", "wrong horse", ErrInvalidCredentials},
+		{"unknown email", "bo@example.com", "correct horse", ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, tokens, err := a.Login(context.Background(), tt.email, tt.password)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Login = %v, want %v", err, tt.want)
+			}
+			if err == nil && (got.ID != u.ID || tokens.RefreshToken == "") {
+				t.Errorf("Login = %+v, %+v, want %s with a new session", got, tokens, u.ID)
+			}
+		})
+	}
+}
+
+func TestRefreshSessionRotatesToken(t *testing.T) {
+	a, repo, _ := newTestApp(t)
+	ctx := context.Background()
+	_, tokens := signUp(t, a)
+
+	refreshed, err := a.RefreshSession(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession = %v", err)
+	}
+	if refreshed.SessionID != tokens.SessionID {
+		t.Errorf("RefreshSession moved to session %s, want %s", refreshed.SessionID, tokens.SessionID)
+	}
+	if refreshed.RefreshToken == tokens.RefreshToken {
+		t.Error("RefreshSession kept the refresh token, want a new one")
+	}
+	again, err := a.RefreshSession(ctx, refreshed.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession with the new token = %v", err)
+	}
+
+	if _, err := a.RefreshSession(ctx, tokens.RefreshToken); err != ErrInvalidSession {
+		t.Errorf("reuse of a rotated refresh token = %v, want ErrInvalidSession", err)
+	}
+	if repo.sessions[tokens.SessionID].RevokedAt == nil {
+		t.Error("reuse of a rotated refresh token left the session active")
+	}
+	if _, err := a.RefreshSession(ctx, again.RefreshToken); err != ErrInvalidSession {
+		t.Errorf("RefreshSession after reuse = %v, want ErrInvalidSession", err)
+	}
+}
+
+func TestRefreshSessionConcurrentUse(t *testing.T) {
+	a, _, _ := newTestApp(t)
+	_, tokens := signUp(t, a)
+
+	const requests = 8
+	errs := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := a.RefreshSession(context.Background(), tokens.RefreshToken)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	rotated := 0
+	for err := range errs {
+		switch err {
+		case nil:
+			rotated++
+		case ErrInvalidSession:
+		default:
+			t.Errorf("RefreshSession = %v", err)
+		}
+	}
+	if rotated != 1 {
+		t.Errorf("%d of %d requests rotated the same refresh token, want 1", rotated, requests)
+	}
+}
+
+func TestRefreshSessionRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		alter func(*models.Session)
+	}{
+		{"revoked", func(s *models.Session) { now := time.Now(); s.RevokedAt = &now }},
+		{"expired", func(s *models.Session) { s.ExpiresAt = time.Now().Add(-time.Second) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, repo, _ := newTestApp(t)
+			_, tokens := signUp(t, a)
+			tt.alter(repo.sessions[tokens.SessionID])
+
+			if _, err := a.RefreshSession(context.Background(), tokens.RefreshToken); err != ErrInvalidSession {
+				t.Errorf("RefreshSession = %v, want ErrInvalidSession", err)
+			}
+		})
+	}
+
+	a, _, _ := newTestApp(t)
+	if _, err := a.RefreshSession(context.Background(), "unknown"); err != ErrInvalidSession {
+		t.Errorf("RefreshSession(unknown) = %v, want ErrInvalidSession", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	a, repo, signer := newTestApp(t)
+	_, first := signUp(t, a)
+	_, second, err := a.Login(context.Background(), "ada@example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Logout(sessionContext(t, signer, first), false); err != nil {
+		t.Fatalf("Logout = %v", err)
+	}
+	if repo.sessions[first.SessionID].RevokedAt == nil {
+		t.Error("Logout left the session active")
+	}
+	if repo.sessions[second.SessionID].RevokedAt != nil {
+		t.Error("Logout revoked another session")
+	}
+
+	if err := a.Logout(sessionContext(t, signer, second), true); err != nil {
+		t.Fatalf("Logout(all) = %v", err)
+	}
+	if repo.sessions[second.SessionID].RevokedAt == nil {
+		t.Error("Logout(all) left a session active")
+	}
+
+	if err := a.Logout(context.Background(), false); err != auth.ErrUnauthenticated {
+		t.Errorf("Logout without a principal = %v, want ErrUnauthenticated", err)
+	}
+}
+
+func TestLogoutRequiresSession(t *testing.T) {
+	a, repo, _ := newTestApp(t)
+	u, tokens := signUp(t, a)
+	tokenCtx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: u.ID, TokenID: "token-1"})
+
+	for _, allSessions := range []bool{false, true} {
+		if err := a.Logout(tokenCtx, allSessions); err != ErrSessionOnly {
+			t.Errorf("Logout(allSessions: %v) with a personal access token = %v, want ErrSessionOnly", allSessions, err)
+		}
+	}
+	if repo.sessions[tokens.SessionID].RevokedAt != nil {
+		t.Error("Logout with a personal access token revoked a session")
+	}
+}
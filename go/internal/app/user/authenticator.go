@@ -0,0 +1,41 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"moss/go/internal/auth"
+	userRepo "moss/go/internal/repository/user"
+)
+
+type sessionAuthenticator struct {
+	signer *auth.TokenSigner
+	repo   userRepo.Repository
+}
+
+// NewAuthenticator returns an Authenticator that accepts access tokens issued by
+// signer as long as the session they belong to has not been revoked or expired.
+func NewAuthenticator(signer *auth.TokenSigner, repo userRepo.Repository) auth.Authenticator {
+	return &sessionAuthenticator{signer: signer, repo: repo}
+}
+
+func (a *sessionAuthenticator) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	p, err := a.signer.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := a.repo.GetSessionByID(ctx, p.SessionID)
+	if err != nil {
+		if errors.Is(err, userRepo.ErrSessionNotFound) {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if session.UserID != p.UserID || !session.Active(time.Now()) {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return p, nil
+}
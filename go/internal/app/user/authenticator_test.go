@@ -0,0 +1,45 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"moss/go/internal/auth"
+)
+
+func TestAuthenticator(t *testing.T) {
+	a, _, signer := newTestApp(t)
+	repo := a.(*app).repo
+	authenticator := NewAuthenticator(signer, repo)
+	u, tokens := signUp(t, a)
+	ctx := context.Background()
+
+	p, err := authenticator.Authenticate(ctx, tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate = %v", err)
+	}
+	if p.UserID != u.ID || p.SessionID != tokens.SessionID {
+		t.Errorf("Authenticate = %+v, want user %s in session %s", p, u.ID, tokens.SessionID)
+	}
+
+	forged, _, err := signer.Issue("someone-else", tokens.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, _, err := signer.Issue(u.ID, "no-such-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{"other user's session": forged, "unknown session": unknown} {
+		if _, err := authenticator.Authenticate(ctx, token); err != auth.ErrInvalidToken {
+			t.Errorf("Authenticate(%s) = %v, want ErrInvalidToken", name, err)
+		}
+	}
+
+	if err := a.Logout(auth.WithPrincipal(ctx, p), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticator.Authenticate(ctx, tokens.AccessToken); err != auth.ErrInvalidToken {
+		t.Errorf("Authenticate after logout = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrInvalidToken    = errors.New("invalid or expired token")
+)
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	UserID    string  // UUID of the authenticated user
+	SessionID string  // UUID of the login session, if authenticated with an access token
+	TokenID   string  // UUID of the personal access token, if authenticated with one
+	Scopes    []Scope // Scopes granted to the personal access token
+}
+
+// Authenticator verifies a bearer token and resolves it to a Principal.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the given Principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the Principal stored in ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// UserIDFromContext returns the authenticated user's ID,
+// or ErrUnauthenticated if the context carries no Principal.
+func UserIDFromContext(ctx context.Context) (string, error) {
+	p, ok := FromContext(ctx)
+	if !ok || p.UserID == "" {
+		return "", ErrUnauthenticated
+	}
+	return p.UserID, nil
+}
@@ -0,0 +1,43 @@
+package auth
+
+// Scope limits what a personal access token may do.
+type Scope string
+
+const (
+	ScopeEntriesRead  Scope = "entries:read"
+	ScopeEntriesWrite Scope = "entries:write"
+	ScopeLinksRead    Scope = "links:read"
+	ScopeLinksWrite   Scope = "links:write"
+)
+
+// KnownScopes lists every scope a token can be granted.
+var KnownScopes = []Scope{
+	ScopeEntriesRead,
+	ScopeEntriesWrite,
+	ScopeLinksRead,
+	ScopeLinksWrite,
+}
+
+// IsKnownScope reports whether s is one of KnownScopes.
+func IsKnownScope(s Scope) bool {
+	for _, known := range KnownScopes {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the Principal may act with scope s.
+// Principals from login sessions are not scope-restricted.
+func (p *Principal) HasScope(s Scope) bool {
+	if p.TokenID == "" {
+		return true
+	}
+	for _, granted := range p.Scopes {
+		if granted == s {
+			return true
+		}
+	}
+	return false
+}
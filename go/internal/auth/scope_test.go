@@ -0,0 +1,24 @@
+package auth
+
+import "testing"
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		scope     Scope
+		want      bool
+	}{
+		{"session", Principal{UserID: "u", SessionID: "s"}, ScopeLinksWrite, true},
+		{"granted", Principal{UserID: "u", TokenID: "t", Scopes: []Scope{ScopeEntriesRead, ScopeLinksRead}}, ScopeLinksRead, true},
+		{"not granted", Principal{UserID: "u", TokenID: "t", Scopes: []Scope{ScopeEntriesRead}}, ScopeEntriesWrite, false},
+		{"no scopes", Principal{UserID: "u", TokenID: "t"}, ScopeEntriesRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.HasScope(tt.scope); got != tt.want {
+				t.Errorf("HasScope(%s) = %v, want %v", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestTokenSigner(t *testing.T) {
+	signer, err := NewTokenSigner(testSecret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, expiresAt, err := signer.Issue("user-1", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(expiresAt); d <= 0 || d > time.Minute {
+		t.Errorf("token expires in %v, want within a minute", d)
+	}
+
+	p, err := signer.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate = %v", err)
+	}
+	if p.UserID != "user-1" || p.SessionID != "session-1" {
+		t.Errorf("Authenticate = %+v, want user-1 in session-1", p)
+	}
+}
+
+func TestTokenSignerRejects(t *testing.T) {
+	signer, err := NewTokenSigner(testSecret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := signer.Issue("user-1", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, sig, _ := strings.Cut(token, ".")
+
+	other, err := NewTokenSigner([]byte(strings.Repeat("x", 32)), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, _, err := other.Issue("user-1", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := NewTokenSigner(testSecret, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredToken, _, err := expired.Issue("user-1", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	noSubject, _, err := signer.Issue("", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", encoded},
+		{"changed signature", encoded + "." + strings.ToUpper(sig)},
+		{"changed claims", "e30." + sig},
+		{"other secret", otherToken},
+		{"expired", expiredToken},
+		{"no subject", noSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.Authenticate(context.Background(), tt.token); err != ErrInvalidToken {
+				t.Errorf("Authenticate(%q) = %v, want ErrInvalidToken", tt.token, err)
+			}
+		})
+	}
+}
+
+func TestNewTokenSignerShortSecret(t *testing.T) {
+	if _, err := NewTokenSigner([]byte("too short"), time.Minute); err == nil {
+		t.Error("NewTokenSigner accepted a 9-byte secret")
+	}
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
+	"maps"
 	"net/http"
+	"os"
+	"time"
 
 	"connectrpc.com/connect"
 	"golang.org/x/net/http2"
@@ -10,13 +14,29 @@ import (
 
 	entryApp "moss/go/internal/app/entry"
 	linkApp "moss/go/internal/app/link"
+	tagApp "moss/go/internal/app/tag"
+	tokenApp "moss/go/internal/app/token"
+	userApp "moss/go/internal/app/user"
+	"moss/go/internal/auth"
 	entryconnect "moss/go/internal/genproto/protobuf/entry/entryconnect"
 	linkconnect "moss/go/internal/genproto/protobuf/link/linkconnect"
+	tagconnect "moss/go/internal/genproto/protobuf/tag/tagconnect"
+	tokenconnect "moss/go/internal/genproto/protobuf/token/tokenconnect"
+	userconnect "moss/go/internal/genproto/protobuf/user/userconnect"
+	"moss/go/internal/interceptors"
+	entryModels "moss/go/internal/models/entry"
+	"moss/go/internal/pagetoken"
 	"moss/go/internal/repository/db"
 	entryRepo "moss/go/internal/repository/entry"
 	linkRepo "moss/go/internal/repository/link"
+	tagRepo "moss/go/internal/repository/tag"
+	tokenRepo "moss/go/internal/repository/token"
+	userRepo "moss/go/internal/repository/user"
 	entryService "moss/go/internal/service/entry"
 	linkService "moss/go/internal/service/link"
+	tagService "moss/go/internal/service/tag"
+	tokenService "moss/go/internal/service/token"
+	userService "moss/go/internal/service/user"
 )
 
 func main() {
@@ -36,27 +56,103 @@ func main() {
 	}
 	defer dbConn.Close()
 
+	// Initialize authentication
+	authSecret := []byte(os.Getenv("MOSS_AUTH_SECRET"))
+	tokenSigner, err := auth.NewTokenSigner(authSecret, 15*time.Minute)
+	if err != nil {
+		log.Fatalf("Failed to initialize auth (set MOSS_AUTH_SECRET): %v", err)
+	}
+
+	userRepo := userRepo.NewRepository(dbConn)
+	tokenRepo := tokenRepo.NewRepository(dbConn)
+	authenticator := tokenApp.NewAuthenticator(tokenRepo, userApp.NewAuthenticator(tokenSigner, userRepo))
+
+	userApp := userApp.NewApp(userRepo, tokenSigner)
+	userSvc := userService.NewService(userApp)
+
+	tokenApp := tokenApp.NewApp(tokenRepo)
+	tokenSvc := tokenService.NewService(tokenApp)
+
+	// Personal access tokens may only call the procedures listed here.
+	procedureScopes := make(map[string]auth.Scope)
+	maps.Copy(procedureScopes, entryService.ProcedureScopes)
+	maps.Copy(procedureScopes, linkService.ProcedureScopes)
+	maps.Copy(procedureScopes, tagService.ProcedureScopes)
+
+	authInterceptor := interceptors.NewAuthInterceptor(
+		authenticator,
+		procedureScopes,
+		userconnect.UserServiceSignUpProcedure,
+		userconnect.UserServiceLoginProcedure,
+		userconnect.UserServiceRefreshSessionProcedure,
+	)
+
 	// Initialize layers
+	// Growth stages change one step at a time unless MOSS_STAGE_TRANSITIONS
+	// lists the allowed moves, e.g. "seed>sprout,sprout>bloom,bloom>seed".
+	lifecycle := entryModels.DefaultLifecycle()
+	if v := os.Getenv("MOSS_STAGE_TRANSITIONS"); v != "" {
+		lifecycle, err = entryModels.ParseLifecycle(v)
+		if err != nil {
+			log.Fatalf("Invalid MOSS_STAGE_TRANSITIONS: %v", err)
+		}
+	}
+
 	repo := entryRepo.NewRepository(dbConn)
-	app := entryApp.NewApp(repo)
+	app := entryApp.NewApp(repo, pagetoken.NewCodec(authSecret), lifecycle)
 	entrySvc := entryService.NewService(app)
 
+	// Permanently delete entries left in the trash past the retention window.
+	trashRetention := 30 * 24 * time.Hour
+	if v := os.Getenv("MOSS_TRASH_RETENTION"); v != "" {
+		trashRetention, err = time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid MOSS_TRASH_RETENTION: %v", err)
+		}
+	}
+	go entryApp.NewPurger(repo, trashRetention, time.Hour).Run(context.Background())
+
 	linkRepo := linkRepo.NewRepository(dbConn)
-	linkApp := linkApp.NewApp(linkRepo)
+	linkApp := linkApp.NewApp(linkRepo, repo)
 	linkSvc := linkService.NewService(linkApp)
 
+	tagRepo := tagRepo.NewRepository(dbConn)
+	tagApp := tagApp.NewApp(tagRepo, repo)
+	tagSvc := tagService.NewService(tagApp)
+
 	// Create Connect adapters for your services
 	entryServicePath, entryConnectSvc := entryconnect.NewEntryServiceHandler(
 		entrySvc,
 		connect.WithInterceptors(
-		// Add your interceptors here
+			authInterceptor,
 		),
 	)
 
 	linkServicePath, linkConnectSvc := linkconnect.NewLinkServiceHandler(
 		linkSvc,
 		connect.WithInterceptors(
-		// Add your interceptors here
+			authInterceptor,
+		),
+	)
+
+	tagServicePath, tagConnectSvc := tagconnect.NewTagServiceHandler(
+		tagSvc,
+		connect.WithInterceptors(
+			authInterceptor,
+		),
+	)
+
+	userServicePath, userConnectSvc := userconnect.NewUserServiceHandler(
+		userSvc,
+		connect.WithInterceptors(
+			authInterceptor,
+		),
+	)
+
+	tokenServicePath, tokenConnectSvc := tokenconnect.NewTokenServiceHandler(
+		tokenSvc,
+		connect.WithInterceptors(
+			authInterceptor,
 		),
 	)
 
@@ -65,7 +161,7 @@ func main() {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Connect-Protocol-Version")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Connect-Protocol-Version, Idempotency-Key")
 			w.Header().Set("Access-Control-Max-Age", "3600")
 
 			if r.Method == "OPTIONS" {
@@ -81,6 +177,13 @@ func main() {
 	mux := http.NewServeMux()
 	mux.Handle(entryServicePath, entryConnectSvc)
 	mux.Handle(linkServicePath, linkConnectSvc)
+	mux.Handle(tagServicePath, tagConnectSvc)
+	mux.Handle(userServicePath, userConnectSvc)
+	mux.Handle(tokenServicePath, tokenConnectSvc)
+
+	// Graph exports can also be downloaded without a Connect client.
+	requireLinksRead := interceptors.NewHTTPAuth(authenticator, auth.ScopeLinksRead)
+	mux.Handle(linkService.ExportPath, requireLinksRead(linkSvc.ExportHandler()))
 
 	// Use h2c to support HTTP/2 without TLS
 	handler := corsMiddleware(mux)
@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is how many unchanged lines surround each change in a hunk.
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a line-level unified diff turning a into b, with fromName and
+// toName in the "---" and "+++" headers. It returns "" if a and b are equal.
+func Unified(fromName, toName, a, b string) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var buf strings.Builder
+	aLine, bLine := 0, 0 // lines of a and b consumed before ops[i]
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			aLine++
+			bLine++
+			i++
+			continue
+		}
+
+		// Grow the hunk until the next change is too far away to share context.
+		end := i + 1
+		for j := i + 1; j < len(ops) && j-end < 2*contextLines; j++ {
+			if ops[j].kind != opEqual {
+				end = j + 1
+			}
+		}
+
+		start := max(i-contextLines, 0)
+		for k := start; k < i; k++ {
+			aLine--
+			bLine--
+		}
+		stop := min(end+contextLines, len(ops))
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		aCount, bCount := 0, 0
+		for _, o := range ops[start:stop] {
+			if o.kind != opInsert {
+				aCount++
+			}
+			if o.kind != opDelete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+		for _, o := range ops[start:stop] {
+			buf.WriteByte(byte(o.kind))
+			buf.WriteString(o.line)
+			buf.WriteByte('\n')
+		}
+
+		aLine += aCount
+		bLine += bCount
+		i = stop
+	}
+	return buf.String()
+}
+
+// hunkRange formats the "start,count" half of a hunk header. start is the
+// number of lines before the hunk; an empty range names the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a shortest edit script from a to b using Myers' algorithm.
+func diffLines(a, b []string) []op {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards from (n, m) to recover the edits.
+	ops := make([]op, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, op{opEqual, a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, op{opInsert, b[y-1]})
+			} else {
+				ops = append(ops, op{opDelete, a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
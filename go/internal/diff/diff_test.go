@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered returns lines "1" to "n", each ending in a newline, with the lines
+// in changed replaced by "x".
+func numbered(n int, changed ...int) string {
+	var buf strings.Builder
+	for i := 1; i <= n; i++ {
+		line := fmt.Sprint(i)
+		for _, c := range changed {
+			if c == i {
+				line = "x"
+			}
+		}
+		buf.WriteString(line + "\n")
+	}
+	return buf.String()
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"both empty", "", "", ""},
+		{"missing final newline is not a change", "a\nb", "a\nb\n", ""},
+		{"from empty", "", "x\n", "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n"},
+		{"to empty", "x\n", "", "--- a\n+++ b\n@@ -1 +0,0 @@\n-x\n"},
+		{"change", "a\nb\nc\n", "a\nB\nc\n", "--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"insert at start", "b\nc\n", "a\nb\nc\n", "--- a\n+++ b\n@@ -1,2 +1,3 @@\n+a\n b\n c\n"},
+		{"delete at end", "a\nb\nc\n", "a\nb\n", "--- a\n+++ b\n@@ -1,3 +1,2 @@\n a\n b\n-c\n"},
+		{
+			"context is trimmed to three lines",
+			numbered(10), numbered(10, 5),
+			"--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+x\n 6\n 7\n 8\n",
+		},
+		{
+			"nearby changes share a hunk",
+			numbered(10), numbered(10, 3, 7),
+			"--- a\n+++ b\n@@ -1,10 +1,10 @@\n 1\n 2\n-3\n+x\n 4\n 5\n 6\n-7\n+x\n 8\n 9\n 10\n",
+		},
+		{
+			"distant changes get separate hunks",
+			numbered(20), numbered(20, 2, 18),
+			"--- a\n+++ b\n@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n 4\n 5\n@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+x\n 19\n 20\n",
+		},
+		{
+			"hunk after an insertion counts new lines",
+			"a\n" + numbered(12), "a\nb\n" + numbered(12, 12),
+			"--- a\n+++ b\n@@ -1,4 +1,5 @@\n a\n+b\n 1\n 2\n 3\n@@ -10,4 +11,4 @@\n 9\n 10\n 11\n-12\n+x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a", "b", tt.a, tt.b); got != tt.want {
+				t.Errorf("Unified(%q, %q) =\n%s\nwant\n%s", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesIsMinimal(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"abcabba", "cbabac", 5},
+		{"abc", "abc", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"abc", "xyz", 6},
+	}
+	for _, tt := range tests {
+		ops := diffLines(strings.Split(tt.a, ""), strings.Split(tt.b, ""))
+		var a, b strings.Builder
+		edits := 0
+		for _, o := range ops {
+			if o.kind != opInsert {
+				a.WriteString(o.line)
+			}
+			if o.kind != opDelete {
+				b.WriteString(o.line)
+			}
+			if o.kind != opEqual {
+				edits++
+			}
+		}
+		if a.String() != tt.a || b.String() != tt.b {
+			t.Errorf("diffLines(%q, %q) turns %q into %q", tt.a, tt.b, a.String(), b.String())
+		}
+		if edits != tt.edits {
+			t.Errorf("diffLines(%q, %q) has %d edits, want %d", tt.a, tt.b, edits, tt.edits)
+		}
+	}
+}
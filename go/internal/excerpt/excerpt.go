@@ -0,0 +1,158 @@
+package excerpt
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxLength is roughly how many bytes of content an excerpt shows.
+const maxLength = 300
+
+// Around returns the paragraph of Markdown content containing content[start:end],
+// as HTML with that span wrapped in <mark> and all other text escaped.
+// Paragraphs are separated by blank lines. A long paragraph is narrowed to the
+// sentence containing the span, and a long sentence to the words around it,
+// with "…" marking the cuts. Runs of whitespace are collapsed into one space.
+func Around(content string, start, end int) string {
+	from, to := paragraph(content, start, end)
+	if to-from > maxLength {
+		from, to = sentence(content, from, to, start, end)
+	}
+
+	before, after := content[from:start], content[end:to]
+	cutBefore, cutAfter := false, false
+	if to-from > maxLength {
+		budget := max(maxLength-(end-start), 0) / 2
+		if len(before) > budget {
+			before, cutBefore = trimStart(before, budget), true
+		}
+		if len(after) > budget {
+			after, cutAfter = trimEnd(after, budget), true
+		}
+	}
+
+	var buf strings.Builder
+	if cutBefore {
+		buf.WriteString("…")
+	}
+	buf.WriteString(html.EscapeString(collapse(strings.TrimLeftFunc(before, unicode.IsSpace))))
+	buf.WriteString("<mark>")
+	buf.WriteString(html.EscapeString(collapse(content[start:end])))
+	buf.WriteString("</mark>")
+	buf.WriteString(html.EscapeString(collapse(strings.TrimRightFunc(after, unicode.IsSpace))))
+	if cutAfter {
+		buf.WriteString("…")
+	}
+	return buf.String()
+}
+
+// paragraph returns the bounds of the run of non-blank lines around content[start:end].
+func paragraph(content string, start, end int) (int, int) {
+	from := lineStart(content, start)
+	for from > 0 {
+		prev := lineStart(content, from-1)
+		if isBlank(content[prev : from-1]) {
+			break
+		}
+		from = prev
+	}
+
+	to := lineEnd(content, end)
+	for to < len(content) {
+		next := lineEnd(content, to+1)
+		if isBlank(content[to+1 : next]) {
+			break
+		}
+		to = next
+	}
+	return from, to
+}
+
+// sentence narrows content[from:to] to the sentence containing content[start:end].
+// Sentences end at '.', '!' or '?' followed by whitespace, and at line breaks.
+func sentence(content string, from, to, start, end int) (int, int) {
+	for i := start - 1; i > from; i-- {
+		if content[i] == '\n' || (isSpace(content[i]) && isTerminator(content[i-1])) {
+			from = i + 1
+			break
+		}
+	}
+	for i := end; i < to; i++ {
+		if content[i] == '\n' {
+			return from, i
+		}
+		if isTerminator(content[i]) && (i+1 == to || isSpace(content[i+1])) {
+			return from, i + 1
+		}
+	}
+	return from, to
+}
+
+// trimStart returns about the last n bytes of s, starting at a word boundary if there is one.
+func trimStart(s string, n int) string {
+	i := len(s) - n
+	if space := strings.IndexFunc(s[i:], unicode.IsSpace); space >= 0 {
+		return s[i+space:]
+	}
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return s[i:]
+}
+
+// trimEnd returns about the first n bytes of s, ending at a word boundary if there is one.
+func trimEnd(s string, n int) string {
+	if space := strings.LastIndexFunc(s[:n], unicode.IsSpace); space >= 0 {
+		return s[:space]
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// collapse replaces each run of whitespace in s with a single space.
+func collapse(s string) string {
+	var buf strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			buf.WriteByte(' ')
+			space = false
+		}
+		buf.WriteRune(r)
+	}
+	if space {
+		buf.WriteByte(' ')
+	}
+	return buf.String()
+}
+
+func lineStart(content string, i int) int {
+	return strings.LastIndexByte(content[:i], '\n') + 1
+}
+
+func lineEnd(content string, i int) int {
+	if n := strings.IndexByte(content[i:], '\n'); n >= 0 {
+		return i + n
+	}
+	return len(content)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func isTerminator(b byte) bool {
+	return b == '.' || b == '!' || b == '?'
+}
@@ -0,0 +1,74 @@
+package excerpt
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAround(t *testing.T) {
+	longSentences := strings.Repeat("Filler words here. ", 10) + "The Go note. " + strings.Repeat("More filler text. ", 10)
+	longWords := strings.Repeat("word ", 80) + "Go" + strings.Repeat(" word", 80)
+	longRunes := strings.Repeat("é", 200) + "Go" + strings.Repeat("é", 200)
+	longMatch := "before " + strings.Repeat("x", 350) + " after"
+
+	tests := []struct {
+		name    string
+		content string
+		match   string // The span to mark: the first occurrence of match
+		want    string
+	}{
+		{"whole content", "See Go here.", "Go", "See <mark>Go</mark> here."},
+		{"paragraph", "Intro.\n\nSee Go here.\n\nOutro.", "Go", "See <mark>Go</mark> here."},
+		{"whitespace-only line separates paragraphs", "Intro.\n  \t\nSee Go", "Go", "See <mark>Go</mark>"},
+		{"multi-line paragraph", "line one\n  line   two Go\nline three\n\nnext", "Go", "line one line two <mark>Go</mark> line three"},
+		{"escaping", "a < b & [[Go]] > \"c\"", "[[Go]]", "a &lt; b &amp; <mark>[[Go]]</mark> &gt; &#34;c&#34;"},
+		{"span at start", "Go first", "Go", "<mark>Go</mark> first"},
+		{"span at end", "last Go", "Go", "last <mark>Go</mark>"},
+		{"long paragraph narrows to the sentence", longSentences, "Go", "The <mark>Go</mark> note."},
+		{
+			"long sentence is cut at words",
+			longWords, "Go",
+			"…" + strings.Repeat("word ", 29) + "<mark>Go</mark>" + strings.Repeat(" word", 29) + "…",
+		},
+		{
+			"cuts without spaces keep whole runes",
+			longRunes, "Go",
+			"…" + strings.Repeat("é", 74) + "<mark>Go</mark>" + strings.Repeat("é", 74) + "…",
+		},
+		{
+			"long span leaves no room around it",
+			longMatch, strings.Repeat("x", 350),
+			"…<mark>" + strings.Repeat("x", 350) + "</mark>…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := strings.Index(tt.content, tt.match)
+			if start < 0 {
+				t.Fatalf("%q not in content", tt.match)
+			}
+			got := Around(tt.content, start, start+len(tt.match))
+			if got != tt.want {
+				t.Errorf("Around = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Around = %q, which is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestTrimKeepsWholeRunes(t *testing.T) {
+	s := strings.Repeat("日本語", 20) // 3-byte runes
+	for n := 0; n <= len(s); n++ {
+		if got := trimStart(s, n); !utf8.ValidString(got) || len(got) > n {
+			t.Errorf("trimStart(s, %d) = %q", n, got)
+		}
+		if n < len(s) {
+			if got := trimEnd(s, n); !utf8.ValidString(got) || len(got) > n {
+				t.Errorf("trimEnd(s, %d) = %q", n, got)
+			}
+		}
+	}
+}
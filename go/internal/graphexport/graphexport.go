@@ -0,0 +1,227 @@
+package graphexport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	models "moss/go/internal/models/link"
+)
+
+// Format is a file format a Graph can be written in.
+type Format string
+
+const (
+	FormatDOT     Format = "dot"     // Graphviz
+	FormatGraphML Format = "graphml" // For Gephi, yEd and others
+	FormatJSON    Format = "json"    // Node-link JSON, as read by D3
+)
+
+// ContentType returns the MIME type of files in format f.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatGraphML:
+		return "application/graphml+xml"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "text/vnd.graphviz"
+	}
+}
+
+// Extension returns the usual file name extension for format f, with the dot.
+func (f Format) Extension() string {
+	switch f {
+	case FormatGraphML:
+		return ".graphml"
+	case FormatJSON:
+		return ".json"
+	default:
+		return ".gv"
+	}
+}
+
+// Write writes g to w in format f. Nodes carry the entry's title, growth stage,
+// tags and link counts, and edges the link's relation. Whether g was truncated
+// is recorded as an attribute of the graph.
+func Write(w io.Writer, f Format, g *models.Graph) error {
+	switch f {
+	case FormatDOT:
+		return writeDOT(w, g)
+	case FormatGraphML:
+		return writeGraphML(w, g)
+	case FormatJSON:
+		return writeJSON(w, g)
+	default:
+		return fmt.Errorf("unknown graph format %q", f)
+	}
+}
+
+func writeDOT(w io.Writer, g *models.Graph) error {
+	var buf strings.Builder
+	buf.WriteString("digraph garden {\n")
+	if g.Truncated {
+		buf.WriteString("  truncated=true;\n")
+	}
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&buf, "  %s [label=%s, growth_stage=%s, tags=%s, link_count=%d, backlink_count=%d];\n",
+			dotID(n.EntryID), dotID(n.Title), dotID(n.GrowthStage), dotID(strings.Join(n.Tags, ",")), n.LinkCount, n.BacklinkCount)
+	}
+	for _, l := range g.Edges {
+		fmt.Fprintf(&buf, "  %s -> %s", dotID(l.SourceEntryID), dotID(l.TargetEntryID))
+		if l.Relation != "" {
+			fmt.Fprintf(&buf, " [label=%s, relation=%s]", dotID(l.Relation), dotID(l.Relation))
+		}
+		buf.WriteString(";\n")
+	}
+	buf.WriteString("}\n")
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// dotID quotes s as a DOT string. Backslashes are escaped too, so that
+// Graphviz shows them as written rather than as label escapes.
+func dotID(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Data        []graphMLData `xml:"data"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, g *models.Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "truncated", For: "graph", Name: "truncated", Type: "boolean"},
+			{ID: "title", For: "node", Name: "title", Type: "string"},
+			{ID: "growth_stage", For: "node", Name: "growth_stage", Type: "string"},
+			{ID: "tags", For: "node", Name: "tags", Type: "string"},
+			{ID: "link_count", For: "node", Name: "link_count", Type: "long"},
+			{ID: "backlink_count", For: "node", Name: "backlink_count", Type: "long"},
+			{ID: "relation", For: "edge", Name: "relation", Type: "string"},
+		},
+	}
+	doc.Graph.ID = "garden"
+	doc.Graph.EdgeDefault = "directed"
+	doc.Graph.Data = []graphMLData{{Key: "truncated", Value: fmt.Sprint(g.Truncated)}}
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.EntryID, Data: []graphMLData{
+			{Key: "title", Value: n.Title},
+			{Key: "growth_stage", Value: n.GrowthStage},
+			{Key: "tags", Value: strings.Join(n.Tags, ",")},
+			{Key: "link_count", Value: fmt.Sprint(n.LinkCount)},
+			{Key: "backlink_count", Value: fmt.Sprint(n.BacklinkCount)},
+		}})
+	}
+	for _, l := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: l.SourceEntryID, Target: l.TargetEntryID, Data: []graphMLData{
+			{Key: "relation", Value: l.Relation},
+		}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// nodeLink is the node-link layout of d3-force, which networkx also reads.
+type nodeLink struct {
+	Directed   bool           `json:"directed"`
+	Multigraph bool           `json:"multigraph"`
+	Graph      nodeLinkGraph  `json:"graph"`
+	Nodes      []nodeLinkNode `json:"nodes"`
+	Links      []nodeLinkLink `json:"links"`
+}
+
+type nodeLinkGraph struct {
+	Truncated bool `json:"truncated"`
+}
+
+type nodeLinkNode struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	GrowthStage   string   `json:"growth_stage"`
+	Tags          []string `json:"tags"`
+	LinkCount     int64    `json:"link_count"`
+	BacklinkCount int64    `json:"backlink_count"`
+}
+
+type nodeLinkLink struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+}
+
+func writeJSON(w io.Writer, g *models.Graph) error {
+	doc := nodeLink{
+		Directed:   true,
+		Multigraph: true,
+		Graph:      nodeLinkGraph{Truncated: g.Truncated},
+		Nodes:      make([]nodeLinkNode, len(g.Nodes)),
+		Links:      make([]nodeLinkLink, len(g.Edges)),
+	}
+	for i, n := range g.Nodes {
+		tags := n.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		doc.Nodes[i] = nodeLinkNode{
+			ID:            n.EntryID,
+			Title:         n.Title,
+			GrowthStage:   n.GrowthStage,
+			Tags:          tags,
+			LinkCount:     n.LinkCount,
+			BacklinkCount: n.BacklinkCount,
+		}
+	}
+	for i, l := range g.Edges {
+		doc.Links[i] = nodeLinkLink{Source: l.SourceEntryID, Target: l.TargetEntryID, Relation: l.Relation}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,130 @@
+package graphexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	models "moss/go/internal/models/link"
+)
+
+func testGraph() *models.Graph {
+	return &models.Graph{
+		Nodes: []*models.Node{
+			{EntryID: "a", Title: `Say "hi" & <go>`, GrowthStage: "seed", Tags: []string{"go", "lang"}, LinkCount: 1},
+			{EntryID: "b", Title: "C:\\path\r\nnext", GrowthStage: "evergreen", BacklinkCount: 1},
+		},
+		Edges: []*models.Link{
+			{SourceEntryID: "a", TargetEntryID: "b", Relation: "part-of"},
+			{SourceEntryID: "b", TargetEntryID: "a"},
+		},
+		Truncated: true,
+	}
+}
+
+func TestDOTID(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", `""`},
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{`\"`, `"\\\""`},
+		{"one\ntwo", `"one\ntwo"`},
+		{"one\r\ntwo", `"one\ntwo"`},
+		{"thé", `"thé"`},
+	}
+	for _, tt := range tests {
+		if got := dotID(tt.s); got != tt.want {
+			t.Errorf("dotID(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatDOT, testGraph()); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph garden {
+  truncated=true;
+  "a" [label="Say \"hi\" & <go>", growth_stage="seed", tags="go,lang", link_count=1, backlink_count=0];
+  "b" [label="C:\\path\nnext", growth_stage="evergreen", tags="", link_count=0, backlink_count=1];
+  "a" -> "b" [label="part-of", relation="part-of"];
+  "b" -> "a";
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Write(DOT) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatGraphML, testGraph()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<data key="truncated">true</data>`,
+		`<data key="title">Say &#34;hi&#34; &amp; &lt;go&gt;</data>`,
+		`<edge source="a" target="b">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Write(GraphML) does not contain %s:\n%s", want, out)
+		}
+	}
+
+	var doc graphML
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Write(GraphML) is not valid XML: %v", err)
+	}
+	titles := []string{doc.Graph.Nodes[0].Data[0].Value, doc.Graph.Nodes[1].Data[0].Value}
+	if want := []string{`Say "hi" & <go>`, "C:\\path\r\nnext"}; titles[0] != want[0] || titles[1] != want[1] {
+		t.Errorf("titles read back as %q, want %q", titles, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, testGraph()); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graph struct {
+			Truncated bool `json:"truncated"`
+		} `json:"graph"`
+		Nodes []struct {
+			Tags json.RawMessage `json:"tags"`
+		} `json:"nodes"`
+		Links []nodeLinkLink `json:"links"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Write(JSON) is not valid JSON: %v", err)
+	}
+	if !doc.Graph.Truncated {
+		t.Error("graph.truncated = false, want true")
+	}
+	var tags []string
+	if err := json.Unmarshal(doc.Nodes[0].Tags, &tags); err != nil || !reflect.DeepEqual(tags, []string{"go", "lang"}) {
+		t.Errorf("nodes[0].tags = %s, want [go lang]", doc.Nodes[0].Tags)
+	}
+	if got := string(doc.Nodes[1].Tags); got != "[]" {
+		t.Errorf("nodes[1].tags = %s, want []", got)
+	}
+	if want := (nodeLinkLink{Source: "a", Target: "b", Relation: "part-of"}); len(doc.Links) != 2 || doc.Links[0] != want {
+		t.Errorf("links = %+v, want %+v first", doc.Links, want)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Format("svg"), testGraph()); err == nil {
+		t.Error("Write(svg) = nil, want an error")
+	}
+}
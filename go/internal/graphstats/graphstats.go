@@ -0,0 +1,232 @@
+package graphstats
+
+import (
+	"math"
+	"sort"
+)
+
+// Graph is a directed graph over nodes 0 to N-1, without parallel edges or self-loops.
+type Graph struct {
+	N   int
+	Out [][]int // Out[v] lists the targets of v's edges, in ascending order
+	In  [][]int // In[v] lists the sources of the edges into v, in ascending order
+}
+
+// New builds a Graph with n nodes from edges given as (source, target) pairs.
+// Duplicate edges and self-loops are dropped.
+func New(n int, edges [][2]int) *Graph {
+	g := &Graph{N: n, Out: make([][]int, n), In: make([][]int, n)}
+	seen := make(map[[2]int]bool, len(edges))
+	for _, e := range edges {
+		if e[0] == e[1] || seen[e] {
+			continue
+		}
+		seen[e] = true
+		g.Out[e[0]] = append(g.Out[e[0]], e[1])
+		g.In[e[1]] = append(g.In[e[1]], e[0])
+	}
+	for v := 0; v < n; v++ {
+		sort.Ints(g.Out[v])
+		sort.Ints(g.In[v])
+	}
+	return g
+}
+
+// PageRank returns each node's PageRank with the given damping factor. The ranks
+// sum to 1. Nodes without outgoing edges spread their rank evenly over all nodes.
+// Iteration stops once the ranks change by less than tolerance in total, or after
+// maxIterations.
+func (g *Graph) PageRank(damping float64, maxIterations int, tolerance float64) []float64 {
+	if g.N == 0 {
+		return nil
+	}
+	n := float64(g.N)
+	rank := make([]float64, g.N)
+	for v := range rank {
+		rank[v] = 1 / n
+	}
+
+	next := make([]float64, g.N)
+	for i := 0; i < maxIterations; i++ {
+		dangling := 0.0
+		for v := 0; v < g.N; v++ {
+			if len(g.Out[v]) == 0 {
+				dangling += rank[v]
+			}
+		}
+		base := (1-damping)/n + damping*dangling/n
+		for v := range next {
+			next[v] = base
+		}
+		for v := 0; v < g.N; v++ {
+			if len(g.Out[v]) == 0 {
+				continue
+			}
+			share := damping * rank[v] / float64(len(g.Out[v]))
+			for _, w := range g.Out[v] {
+				next[w] += share
+			}
+		}
+
+		delta := 0.0
+		for v := range rank {
+			delta += math.Abs(next[v] - rank[v])
+		}
+		rank, next = next, rank
+		if delta < tolerance {
+			break
+		}
+	}
+	return rank
+}
+
+// Components returns the weakly connected components of g, ignoring edge
+// direction: each is a list of nodes in ascending order. Components are ordered
+// largest first, then by their first node.
+func (g *Graph) Components() [][]int {
+	component := make([]int, g.N)
+	for v := range component {
+		component[v] = -1
+	}
+
+	var components [][]int
+	for start := 0; start < g.N; start++ {
+		if component[start] >= 0 {
+			continue
+		}
+		id := len(components)
+		members := []int{start}
+		component[start] = id
+		for i := 0; i < len(members); i++ {
+			v := members[i]
+			for _, neighbors := range [][]int{g.Out[v], g.In[v]} {
+				for _, w := range neighbors {
+					if component[w] < 0 {
+						component[w] = id
+						members = append(members, w)
+					}
+				}
+			}
+		}
+		sort.Ints(members)
+		components = append(components, members)
+	}
+	sortGroups(components)
+	return components
+}
+
+// Communities partitions g into clusters of densely linked nodes by label
+// propagation, ignoring edge direction. Every node starts in its own cluster,
+// then repeatedly joins the cluster with the most votes from its neighbors,
+// until no node moves or after maxIterations. A neighbor's vote counts once
+// more for each neighbor the two nodes share, so clusters follow triangles of
+// links rather than single bridging links. Nodes are visited in order and ties
+// are broken towards the current, then the lowest, cluster, so the result is
+// deterministic. Clusters are returned as for Components.
+func (g *Graph) Communities(maxIterations int) [][]int {
+	neighbors := g.undirected()
+	weights := make([][]int, g.N)
+	for v := range neighbors {
+		weights[v] = make([]int, len(neighbors[v]))
+		for i, w := range neighbors[v] {
+			weights[v][i] = 1 + shared(neighbors[v], neighbors[w])
+		}
+	}
+
+	label := make([]int, g.N)
+	for v := range label {
+		label[v] = v
+	}
+
+	votes := make(map[int]int)
+	for i := 0; i < maxIterations; i++ {
+		changed := false
+		for v := 0; v < g.N; v++ {
+			if len(neighbors[v]) == 0 {
+				continue
+			}
+			clear(votes)
+			for j, w := range neighbors[v] {
+				votes[label[w]] += weights[v][j]
+			}
+
+			most := 0
+			for _, n := range votes {
+				most = max(most, n)
+			}
+			if votes[label[v]] == most {
+				continue
+			}
+			best := g.N
+			for l, n := range votes {
+				if n == most && l < best {
+					best = l
+				}
+			}
+			label[v] = best
+			changed = true
+		}
+		if !changed {
+			break
+		}
+	}
+
+	byLabel := make(map[int][]int)
+	for v, l := range label {
+		byLabel[l] = append(byLabel[l], v)
+	}
+	communities := make([][]int, 0, len(byLabel))
+	for _, members := range byLabel {
+		communities = append(communities, members)
+	}
+	sortGroups(communities)
+	return communities
+}
+
+// undirected returns each node's neighbors in either direction, in ascending order.
+func (g *Graph) undirected() [][]int {
+	neighbors := make([][]int, g.N)
+	for v := 0; v < g.N; v++ {
+		out, in := g.Out[v], g.In[v]
+		merged := make([]int, 0, len(out)+len(in))
+		for len(out) > 0 || len(in) > 0 {
+			switch {
+			case len(in) == 0 || (len(out) > 0 && out[0] < in[0]):
+				merged, out = append(merged, out[0]), out[1:]
+			case len(out) == 0 || in[0] < out[0]:
+				merged, in = append(merged, in[0]), in[1:]
+			default:
+				merged, out, in = append(merged, out[0]), out[1:], in[1:]
+			}
+		}
+		neighbors[v] = merged
+	}
+	return neighbors
+}
+
+// shared returns how many nodes two ascending lists have in common.
+func shared(a, b []int) int {
+	n := 0
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case b[0] < a[0]:
+			b = b[1:]
+		default:
+			n++
+			a, b = a[1:], b[1:]
+		}
+	}
+	return n
+}
+
+// sortGroups orders groups of ascending nodes largest first, then by their first node.
+func sortGroups(groups [][]int) {
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i]) != len(groups[j]) {
+			return len(groups[i]) > len(groups[j])
+		}
+		return groups[i][0] < groups[j][0]
+	})
+}
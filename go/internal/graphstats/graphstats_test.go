@@ -0,0 +1,106 @@
+package graphstats
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	g := New(3, [][2]int{{0, 2}, {0, 1}, {0, 2}, {1, 1}, {2, 0}})
+	if want := [][]int{{1, 2}, nil, {0}}; !reflect.DeepEqual(g.Out, want) {
+		t.Errorf("Out = %v, want %v", g.Out, want)
+	}
+	if want := [][]int{{2}, {0}, {0}}; !reflect.DeepEqual(g.In, want) {
+		t.Errorf("In = %v, want %v", g.In, want)
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		edges [][2]int
+		want  []float64
+	}{
+		{"empty", 0, nil, nil},
+		{"single node", 1, nil, []float64{1}},
+		{"no edges", 4, nil, []float64{0.25, 0.25, 0.25, 0.25}},
+		{"cycle", 3, [][2]int{{0, 1}, {1, 2}, {2, 0}}, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}},
+		// r0 = 0.15/2 + 0.85*r1/2 and r1 = r0 + 0.85*r0, as node 1 is dangling.
+		{"dangling node", 2, [][2]int{{0, 1}}, []float64{1 / 2.85, 1.85 / 2.85}},
+		// Each leaf gets base = 0.15/4 + 0.85*r0/4 and r0 = base + 0.85*3*base.
+		{"star into center", 4, [][2]int{{1, 0}, {2, 0}, {3, 0}}, []float64{3.55 / 6.55, 1 / 6.55, 1 / 6.55, 1 / 6.55}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.n, tt.edges).PageRank(0.85, 1000, 1e-12)
+			if len(got) != len(tt.want) {
+				t.Fatalf("PageRank = %v, want %v", got, tt.want)
+			}
+			sum := 0.0
+			for v := range got {
+				sum += got[v]
+				if math.Abs(got[v]-tt.want[v]) > 1e-9 {
+					t.Errorf("PageRank = %v, want %v", got, tt.want)
+					break
+				}
+			}
+			if len(got) > 0 && math.Abs(sum-1) > 1e-9 {
+				t.Errorf("PageRank sums to %v, want 1", sum)
+			}
+		})
+	}
+}
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		edges [][2]int
+		want  [][]int
+	}{
+		{"empty", 0, nil, nil},
+		{"isolated nodes", 3, nil, [][]int{{0}, {1}, {2}}},
+		{"direction is ignored", 3, [][2]int{{2, 0}, {1, 0}}, [][]int{{0, 1, 2}}},
+		{"largest first", 5, [][2]int{{0, 1}, {2, 3}, {3, 4}}, [][]int{{2, 3, 4}, {0, 1}}},
+		{"ties by first node", 5, [][2]int{{3, 4}, {0, 2}}, [][]int{{0, 2}, {3, 4}, {1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.n, tt.edges).Components(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Components = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommunities(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		edges [][2]int
+		want  [][]int
+	}{
+		{"empty", 0, nil, [][]int{}},
+		{"isolated nodes", 2, nil, [][]int{{0}, {1}}},
+		{"pair", 2, [][2]int{{1, 0}}, [][]int{{0, 1}}},
+		{
+			"triangles joined by a bridge",
+			6, [][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 3}, {3, 4}, {4, 5}, {5, 3}},
+			[][]int{{0, 1, 2}, {3, 4, 5}},
+		},
+		{
+			"separate components stay apart",
+			5, [][2]int{{0, 1}, {1, 2}, {2, 0}, {3, 4}},
+			[][]int{{0, 1, 2}, {3, 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.n, tt.edges).Communities(20); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Communities = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
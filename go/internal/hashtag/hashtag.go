@@ -0,0 +1,195 @@
+package hashtag
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ref is a single #tag found in Markdown content.
+type Ref struct {
+	Name  string // Normalized tag name, e.g. "lang/go" for #Lang/Go
+	Start int    // Byte offset of the '#'
+	End   int    // Byte offset just past the tag
+}
+
+// Parse returns every #tag in content, in order of appearance.
+// A tag starts with '#' at the beginning of a line or after whitespace or '(',
+// and runs over letters, digits, '_', '-' and '/'; '/' separates nested tags.
+// Headings ("# Title"), all-digit tags such as issue numbers (#12) and tags
+// inside fenced code blocks or inline code spans are ignored.
+func Parse(content string) []Ref {
+	var refs []Ref
+	inFence := false
+	fence := ""
+
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+			} else if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		refs = append(refs, parseLine(line, lineStart)...)
+	}
+	return refs
+}
+
+// Names returns the distinct tag names in content, in order of first appearance.
+func Names(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, ref := range Parse(content) {
+		if !seen[ref.Name] {
+			seen[ref.Name] = true
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// Normalize returns the canonical form of a tag name: lower case, without the
+// leading '#' or surrounding slashes. It reports false if name is not a valid tag.
+func Normalize(name string) (string, bool) {
+	name = strings.Trim(strings.TrimPrefix(strings.TrimSpace(name), "#"), "/")
+	if name == "" {
+		return "", false
+	}
+
+	digitsOnly := true
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" {
+			return "", false
+		}
+		for _, r := range segment {
+			if !isTagRune(r) || r == '/' {
+				return "", false
+			}
+			if !unicode.IsDigit(r) {
+				digitsOnly = false
+			}
+		}
+	}
+	if digitsOnly {
+		return "", false
+	}
+	return strings.ToLower(name), true
+}
+
+// Ancestors returns the tags name is nested under, outermost first:
+// "lang/go/generics" has ancestors "lang" and "lang/go".
+func Ancestors(name string) []string {
+	var ancestors []string
+	for i := 0; i < len(name); i++ {
+		if name[i] == '/' {
+			ancestors = append(ancestors, name[:i])
+		}
+	}
+	return ancestors
+}
+
+// Within reports whether name is tag or nested under it.
+func Within(name, tag string) bool {
+	return name == tag || strings.HasPrefix(name, tag+"/")
+}
+
+// Rewrite returns content with each #tag replaced by replace's result.
+// Tags for which replace returns false are left as they are.
+func Rewrite(content string, replace func(Ref) (string, bool)) string {
+	var buf strings.Builder
+	last := 0
+	for _, ref := range Parse(content) {
+		text, ok := replace(ref)
+		if !ok {
+			continue
+		}
+		buf.WriteString(content[last:ref.Start])
+		buf.WriteString(text)
+		last = ref.End
+	}
+	if last == 0 {
+		return content
+	}
+	buf.WriteString(content[last:])
+	return buf.String()
+}
+
+// fenceMarker returns the ``` or ~~~ run that opens a fenced code block, if line starts with one.
+func fenceMarker(line string) string {
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == c {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+func parseLine(line string, lineStart int) []Ref {
+	var refs []Ref
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == '`':
+			// Skip an inline code span: a backtick run up to the next run of equal length.
+			n := 1
+			for i+n < len(line) && line[i+n] == '`' {
+				n++
+			}
+			closing := strings.Index(line[i+n:], line[i:i+n])
+			if closing < 0 {
+				i += n
+				continue
+			}
+			i += n + closing + n
+
+		case line[i] == '#' && startsTag(line, i):
+			end := i + 1
+			for end < len(line) {
+				r, size := utf8.DecodeRuneInString(line[end:])
+				if !isTagRune(r) {
+					break
+				}
+				end += size
+			}
+			// A trailing slash belongs to the surrounding text, not the tag.
+			for end > i+1 && line[end-1] == '/' {
+				end--
+			}
+			if name, ok := Normalize(line[i+1 : end]); ok {
+				refs = append(refs, Ref{Name: name, Start: lineStart + i, End: lineStart + end})
+			}
+			i = max(end, i+1)
+
+		default:
+			i++
+		}
+	}
+	return refs
+}
+
+// startsTag reports whether the '#' at line[i] may open a tag.
+func startsTag(line string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(line[:i])
+	return unicode.IsSpace(prev) || prev == '('
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '/'
+}
@@ -0,0 +1,137 @@
+package hashtag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Ref
+	}{
+		{"none", "plain text", nil},
+		{"tag", "#go", []Ref{{Name: "go", Start: 0, End: 3}}},
+		{"after space", "about #Go today", []Ref{{Name: "go", Start: 6, End: 9}}},
+		{"after parenthesis", "(#go)", []Ref{{Name: "go", Start: 1, End: 4}}},
+		{"inside a word", "C#go x#web", nil},
+		{"punctuation ends a tag", "#go, #web.", []Ref{{Name: "go", Start: 0, End: 3}, {Name: "web", Start: 5, End: 9}}},
+		{"nested", "#Lang/Go", []Ref{{Name: "lang/go", Start: 0, End: 8}}},
+		{"trailing slash", "#lang/go/ x", []Ref{{Name: "lang/go", Start: 0, End: 8}}},
+		{"empty segment", "#lang//go", nil},
+		{"dash and underscore", "#to-do_list", []Ref{{Name: "to-do_list", Start: 0, End: 11}}},
+		{"multi-byte", "é #Café", []Ref{{Name: "café", Start: 3, End: 9}}},
+		{"heading", "# Title\n## Section", nil},
+		{"issue number", "fixes #12", nil},
+		{"digits and letters", "#2024q1", []Ref{{Name: "2024q1", Start: 0, End: 7}}},
+		{"lone hash", "# #", nil},
+		{"later line", "one\n#go", []Ref{{Name: "go", Start: 4, End: 7}}},
+		{"inline code", "`#go` #web", []Ref{{Name: "web", Start: 6, End: 10}}},
+		{"fenced code", "```\n#go\n```\n#web", []Ref{{Name: "web", Start: 12, End: 16}}},
+		{"unclosed fence", "~~~\n#go", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	got := Names("#web #Go and #go #lang/go")
+	want := []string{"web", "go", "lang/go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %q, want %q", got, want)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"go", "go", true},
+		{" #Go ", "go", true},
+		{"/Lang/Go/", "lang/go", true},
+		{"1/a", "1/a", true},
+		{"", "", false},
+		{"#", "", false},
+		{"//", "", false},
+		{"lang//go", "", false},
+		{"12", "", false},
+		{"1/2", "", false},
+		{"go lang", "", false},
+		{"go!", "", false},
+		{"##go", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Normalize(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"go", nil},
+		{"lang/go", []string{"lang"}},
+		{"lang/go/generics", []string{"lang", "lang/go"}},
+	}
+	for _, tt := range tests {
+		if got := Ancestors(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ancestors(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		name, tag string
+		want      bool
+	}{
+		{"lang", "lang", true},
+		{"lang/go", "lang", true},
+		{"lang/go/generics", "lang", true},
+		{"language", "lang", false},
+		{"lang", "lang/go", false},
+	}
+	for _, tt := range tests {
+		if got := Within(tt.name, tt.tag); got != tt.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", tt.name, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	// rename renames lang and the tags nested under it to code, as RenameTag does.
+	rename := func(ref Ref) (string, bool) {
+		if !Within(ref.Name, "lang") {
+			return "", false
+		}
+		return "#code" + ref.Name[len("lang"):], true
+	}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"no tags", "no tags"},
+		{"#web", "#web"},
+		{"#lang", "#code"},
+		{"#Lang/Go, #language and #web", "#code/go, #language and #web"},
+		{"`#lang` #lang", "`#lang` #code"},
+	}
+	for _, tt := range tests {
+		if got := Rewrite(tt.content, rename); got != tt.want {
+			t.Errorf("Rewrite(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
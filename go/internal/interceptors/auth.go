@@ -0,0 +1,64 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"moss/go/internal/auth"
+)
+
+// NewAuthInterceptor returns a Connect interceptor that verifies the bearer token
+// in the Authorization header and stores the resulting Principal in the context.
+// Procedures listed in publicProcedures (e.g. "/moss.user.UserService/Login")
+// are served without a token. Personal access tokens may only call procedures
+// present in procedureScopes, and only if they were granted the mapped scope.
+func NewAuthInterceptor(authenticator auth.Authenticator, procedureScopes map[string]auth.Scope, publicProcedures ...string) connect.UnaryInterceptorFunc {
+	public := make(map[string]bool, len(publicProcedures))
+	for _, p := range publicProcedures {
+		public[p] = true
+	}
+
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if req.Spec().IsClient {
+				return next(ctx, req)
+			}
+			if public[req.Spec().Procedure] {
+				return next(ctx, req)
+			}
+
+			token, ok := bearerToken(req.Header().Get("Authorization"))
+			if !ok {
+				return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("missing bearer token"))
+			}
+
+			principal, err := authenticator.Authenticate(ctx, token)
+			if err != nil {
+				return nil, connect.NewError(connect.CodeUnauthenticated, err)
+			}
+
+			if principal.TokenID != "" {
+				scope, ok := procedureScopes[req.Spec().Procedure]
+				if !ok || !principal.HasScope(scope) {
+					return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("token lacks scope for %s", req.Spec().Procedure))
+				}
+			}
+
+			return next(auth.WithPrincipal(ctx, principal), req)
+		}
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header value.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,106 @@
+package interceptors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"moss/go/internal/auth"
+)
+
+const (
+	privateProcedure = "/moss.test.TestService/Private"
+	readProcedure    = "/moss.test.TestService/Read"
+	publicProcedure  = "/moss.test.TestService/Public"
+)
+
+// stubAuthenticator resolves the bearer tokens it has a Principal for.
+type stubAuthenticator map[string]*auth.Principal
+
+func (s stubAuthenticator) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	if p, ok := s[token]; ok {
+		return p, nil
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+// call serves procedure behind interceptor and calls it with the given
+// Authorization header. It returns the user the handler was called for.
+func call(t *testing.T, interceptor connect.Interceptor, procedure, authorization string) (string, error) {
+	t.Helper()
+	var userID string
+	mux := http.NewServeMux()
+	mux.Handle(procedure, connect.NewUnaryHandler(procedure,
+		func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+			if p, ok := auth.FromContext(ctx); ok {
+				userID = p.UserID
+			}
+			return connect.NewResponse(&emptypb.Empty{}), nil
+		},
+		connect.WithInterceptors(interceptor),
+	))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+procedure)
+	req := connect.NewRequest(&emptypb.Empty{})
+	if authorization != "" {
+		req.Header().Set("Authorization", authorization)
+	}
+	_, err := client.CallUnary(context.Background(), req)
+	return userID, err
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	authenticator := stubAuthenticator{
+		"good": {UserID: "user-1", SessionID: "session-1"},
+		"pat":  {UserID: "user-2", TokenID: "token-1", Scopes: []auth.Scope{auth.ScopeEntriesRead}},
+	}
+	procedureScopes := map[string]auth.Scope{
+		privateProcedure: auth.ScopeEntriesWrite,
+		readProcedure:    auth.ScopeEntriesRead,
+	}
+	interceptor := NewAuthInterceptor(authenticator, procedureScopes, publicProcedure)
+
+	tests := []struct {
+		name          string
+		procedure     string
+		authorization string
+		wantUser      string
+		wantCode      connect.Code // 0 if the call succeeds
+	}{
+		{"valid token", privateProcedure, "Bearer good", "user-1", 0},
+		{"scheme is case-insensitive", privateProcedure, "bearer  good ", "user-1", 0},
+		{"no header", privateProcedure, "", "", connect.CodeUnauthenticated},
+		{"other scheme", privateProcedure, "Basic good", "", connect.CodeUnauthenticated},
+		{"no token", privateProcedure, "Bearer ", "", connect.CodeUnauthenticated},
+		{"unknown token", privateProcedure, "Bearer bad", "", connect.CodeUnauthenticated},
+		{"session ignores scopes", privateProcedure, "Bearer good", "user-1", 0},
+		{"token with scope", readProcedure, "Bearer pat", "user-2", 0},
+		{"token without scope", privateProcedure, "Bearer pat", "", connect.CodePermissionDenied},
+		{"token on unmapped procedure", "/moss.test.TestService/Other", "Bearer pat", "", connect.CodePermissionDenied},
+		{"public without token", publicProcedure, "", "", 0},
+		{"public ignores token", publicProcedure, "Bearer good", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, err := call(t, interceptor, tt.procedure, tt.authorization)
+			if tt.wantCode != 0 {
+				if connect.CodeOf(err) != tt.wantCode {
+					t.Fatalf("call = %v, want code %v", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("call = %v", err)
+			}
+			if userID != tt.wantUser {
+				t.Errorf("handler saw user %q, want %q", userID, tt.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package interceptors
+
+import (
+	"net/http"
+
+	"moss/go/internal/auth"
+)
+
+// NewHTTPAuth returns middleware for plain HTTP endpoints that does what the
+// auth interceptor does for Connect procedures: it verifies the bearer token
+// in the Authorization header and stores the resulting Principal in the
+// request context. Personal access tokens must have been granted scope.
+func NewHTTPAuth(authenticator auth.Authenticator, scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r.Header.Get("Authorization"))
+			if !ok {
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			if !principal.HasScope(scope) {
+				http.Error(w, "token lacks scope "+string(scope), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
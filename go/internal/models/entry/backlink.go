@@ -0,0 +1,18 @@
+package models
+
+// Backlink is an entry that links to another, with excerpts of its content
+// around each reference to it. Contexts are HTML: the reference is wrapped in
+// <mark> and all other text is escaped.
+type Backlink struct {
+	Source   *Entry
+	Contexts []string // One per [[wiki-link]] to the target; empty if Source only has manual links
+}
+
+// Mention is a place where an entry names another in plain text without
+// linking to it. Start and End are byte offsets into Source.Content.
+type Mention struct {
+	Source  *Entry
+	Start   int
+	End     int
+	Context string // HTML excerpt around the mention, which is wrapped in <mark>
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -23,6 +24,13 @@ type Entry struct {
 	GrowthStage GrowthStage // Lifecycle stage of the entry
 	CreatedAt   time.Time   // Timestamp of creation
 	UpdatedAt   time.Time   // Timestamp of last update
+	Version     int64       // Starts at 1 and increases with every update
+	DeletedAt   *time.Time  // Set while the entry is in the trash
+	Aliases     []string    // Other names the entry goes by, unique among the user's aliases
+
+	// Set on entries the app returns, not stored with the entry
+	LinkCount     int64 // Live entries this one links to
+	BacklinkCount int64 // Live entries linking to this one
 }
 
 var ErrInvalidEntry = errors.New("invalid entry: missing required fields")
@@ -34,11 +42,79 @@ func (e *Entry) Validate() error {
 	if e.Content == "" {
 		return errors.New("entry must have Content")
 	}
-	//switch e.GrowthStage {
-	//case GrowthStageSeed, GrowthStageSprout, GrowthStageBloom, GrowthStageEvergreen:
-	//	// valid
-	//default:
-	//	return errors.New("entry has invalid GrowthStage")
-	//}
+	if err := validateAliases(e.Aliases); err != nil {
+		return err
+	}
+	if !e.GrowthStage.Valid() {
+		return errors.New("entry has invalid GrowthStage")
+	}
 	return nil
 }
+
+// Patch is a partial update of an entry. Nil fields are left unchanged.
+type Patch struct {
+	Title       *string
+	Content     *string
+	GrowthStage *GrowthStage
+	Aliases     *[]string // Replaces all aliases; an empty slice removes them
+	Version     int64     // Expected current version; 0 skips the check
+}
+
+// Validate ensures the patch does not clear a required field.
+func (p *Patch) Validate() error {
+	if p.Title != nil && *p.Title == "" {
+		return errors.New("entry must have a Title")
+	}
+	if p.Content != nil && *p.Content == "" {
+		return errors.New("entry must have Content")
+	}
+	if p.GrowthStage != nil && !p.GrowthStage.Valid() {
+		return errors.New("entry has invalid GrowthStage")
+	}
+	if p.Aliases != nil {
+		return validateAliases(*p.Aliases)
+	}
+	return nil
+}
+
+// NormalizeAliases trims the aliases and drops blank ones and case-insensitive
+// duplicates, keeping the first spelling of each.
+func NormalizeAliases(aliases []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		key := strings.ToLower(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, alias)
+	}
+	return normalized
+}
+
+// validateAliases rejects aliases that could not be written as a [[wiki-link]].
+func validateAliases(aliases []string) error {
+	for _, alias := range aliases {
+		if strings.ContainsAny(alias, "[]|#\r\n") {
+			return errors.New("entry alias must not contain '[', ']', '|', '#' or line breaks")
+		}
+	}
+	return nil
+}
+
+// DeletionPolicy decides what happens to an entry's links when it is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyKeep leaves the links in place, hidden while the entry is in the trash.
+	DeletionPolicyKeep DeletionPolicy = "keep"
+	// DeletionPolicyCascade removes every link into and out of the entry.
+	DeletionPolicyCascade DeletionPolicy = "cascade"
+	// DeletionPolicyReject refuses the deletion while other entries link to it.
+	DeletionPolicyReject DeletionPolicy = "reject"
+	// DeletionPolicyRewrite removes the links like DeletionPolicyCascade and turns
+	// other entries' [[wiki-links]] to it into plain text.
+	DeletionPolicyRewrite DeletionPolicy = "rewrite"
+)
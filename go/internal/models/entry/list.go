@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// SortField selects the key ListEntries orders by.
+type SortField string
+
+const (
+	SortByCreated   SortField = "created"
+	SortByUpdated   SortField = "updated"
+	SortByTitle     SortField = "title"
+	SortByLinkCount SortField = "link_count"
+)
+
+// ListFilter narrows down which entries ListEntries returns. Zero values match everything.
+type ListFilter struct {
+	GrowthStage   GrowthStage // Only entries at this stage, if set
+	CreatedAfter  *time.Time  // Inclusive lower bound on CreatedAt
+	CreatedBefore *time.Time  // Exclusive upper bound on CreatedAt
+	UpdatedAfter  *time.Time  // Inclusive lower bound on UpdatedAt
+	UpdatedBefore *time.Time  // Exclusive upper bound on UpdatedAt
+	TitlePrefix   string      // Case-insensitive prefix of the title or an alias
+	Tags          string      // Tag expression entries must match (see package tagexpr)
+}
+
+// Cursor is a keyset position: the sort key and ID of the last entry on a page.
+// Only the field matching the query's SortField is meaningful.
+type Cursor struct {
+	CreatedAt time.Time `json:"c,omitempty"`
+	UpdatedAt time.Time `json:"u,omitempty"`
+	Title     string    `json:"t,omitempty"`
+	LinkCount int64     `json:"l,omitempty"`
+	ID        string    `json:"id"`
+}
+
+// ListQuery describes one page of a filtered, sorted entry listing.
+type ListQuery struct {
+	Filter     ListFilter
+	SortBy     SortField
+	Descending bool
+	After      *Cursor // Start after this position; nil for the first page
+	Limit      int
+}
+
+// EntryPage is one page of entries and the position to continue from.
+type EntryPage struct {
+	Entries []*Entry
+	Next    *Cursor // nil when there are no more entries
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Revision is a snapshot of an entry after one of its writes.
+// Revision 1 is the entry as created; each update appends the next number.
+type Revision struct {
+	EntryID     string      // UUID of the entry this is a revision of
+	Number      int32       // 1-based, increasing with each write
+	Title       string      // Title at this revision
+	Content     string      // Markdown content at this revision
+	GrowthStage GrowthStage // Lifecycle stage at this revision
+	CreatedAt   time.Time   // When this revision was written
+}
@@ -0,0 +1,41 @@
+package models
+
+// SearchQuery describes one page of a full-text search over a user's entries.
+type SearchQuery struct {
+	Text        string        // As typed; see tsquery.FromSearch for the syntax
+	GrowthStage GrowthStage   // Only entries at this stage, if set
+	Tags        string        // Tag expression entries must match, if set
+	After       *SearchCursor // Start after this position; nil for the first page
+	Limit       int
+}
+
+// SearchCursor is the rank and ID of the last result on a search page.
+type SearchCursor struct {
+	Rank float32 `json:"r"`
+	ID   string  `json:"id"`
+}
+
+// SearchResult is an entry matching a search, with its matches highlighted.
+// TitleHighlight and Snippet are HTML: matches are wrapped in <mark> and all
+// other text is escaped.
+type SearchResult struct {
+	Entry          *Entry
+	Rank           float32
+	TitleHighlight string
+	Snippet        string // Up to two fragments of the content around the matches
+}
+
+// SearchPage is one page of search results and the position to continue from.
+type SearchPage struct {
+	Results []*SearchResult
+	Next    *SearchCursor // nil when there are no more results
+}
+
+// Suggestion is an entry whose title or an alias closely matches a partly
+// typed or misspelled name, for wiki-link completion and the quick switcher.
+type Suggestion struct {
+	Entry         *Entry
+	Score         float32 // Similarity, weighted with recency of edit and BacklinkCount
+	Similarity    float32 // Trigram word similarity of the query to the title or best alias, 0 to 1
+	BacklinkCount int64   // Number of live entries linking to Entry
+}
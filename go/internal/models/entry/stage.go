@@ -0,0 +1,128 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GrowthStages lists the growth stages in lifecycle order.
+var GrowthStages = []GrowthStage{GrowthStageSeed, GrowthStageSprout, GrowthStageBloom, GrowthStageEvergreen}
+
+// Valid reports whether s is one of GrowthStages.
+func (s GrowthStage) Valid() bool {
+	for _, stage := range GrowthStages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGrowthStage returns the growth stage named s, ignoring case.
+func ParseGrowthStage(s string) (GrowthStage, bool) {
+	stage := GrowthStage(strings.ToLower(strings.TrimSpace(s)))
+	return stage, stage.Valid()
+}
+
+// Lifecycle says which growth stage an entry may move to from each stage.
+// Staying at the same stage is always allowed.
+type Lifecycle struct {
+	next map[GrowthStage][]GrowthStage
+}
+
+// NewLifecycle returns a Lifecycle allowing the moves in transitions, which
+// maps each stage to the stages it may move to.
+func NewLifecycle(transitions map[GrowthStage][]GrowthStage) (*Lifecycle, error) {
+	l := &Lifecycle{next: make(map[GrowthStage][]GrowthStage)}
+	for from, targets := range transitions {
+		if !from.Valid() {
+			return nil, fmt.Errorf("unknown growth stage %q", from)
+		}
+		for _, to := range targets {
+			if !to.Valid() {
+				return nil, fmt.Errorf("unknown growth stage %q", to)
+			}
+			if to != from && !l.Allows(from, to) {
+				l.next[from] = append(l.next[from], to)
+			}
+		}
+	}
+	return l, nil
+}
+
+// DefaultLifecycle lets entries grow one stage at a time and be cut back to
+// any earlier stage: a seed must sprout before it can bloom.
+func DefaultLifecycle() *Lifecycle {
+	transitions := make(map[GrowthStage][]GrowthStage)
+	for i, from := range GrowthStages {
+		transitions[from] = append([]GrowthStage{}, GrowthStages[:i]...)
+		if i+1 < len(GrowthStages) {
+			transitions[from] = append(transitions[from], GrowthStages[i+1])
+		}
+	}
+	l, _ := NewLifecycle(transitions)
+	return l
+}
+
+// ParseLifecycle parses a Lifecycle from comma-separated moves written
+// from>to, such as "seed>sprout, sprout>bloom, bloom>seed". Stage names are
+// matched as by ParseGrowthStage.
+func ParseLifecycle(spec string) (*Lifecycle, error) {
+	transitions := make(map[GrowthStage][]GrowthStage)
+	for _, move := range strings.Split(spec, ",") {
+		if strings.TrimSpace(move) == "" {
+			continue
+		}
+		fromName, toName, ok := strings.Cut(move, ">")
+		if !ok {
+			return nil, fmt.Errorf("growth stage transition %q is not written from>to", strings.TrimSpace(move))
+		}
+		from, ok := ParseGrowthStage(fromName)
+		if !ok {
+			return nil, fmt.Errorf("unknown growth stage %q", strings.TrimSpace(fromName))
+		}
+		to, ok := ParseGrowthStage(toName)
+		if !ok {
+			return nil, fmt.Errorf("unknown growth stage %q", strings.TrimSpace(toName))
+		}
+		transitions[from] = append(transitions[from], to)
+	}
+	return NewLifecycle(transitions)
+}
+
+// Allows reports whether an entry may move from one growth stage to another.
+func (l *Lifecycle) Allows(from, to GrowthStage) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range l.next[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the stages an entry at stage from may move to, in lifecycle order.
+func (l *Lifecycle) Next(from GrowthStage) []GrowthStage {
+	next := []GrowthStage{}
+	for _, stage := range GrowthStages {
+		if stage != from && l.Allows(from, stage) {
+			next = append(next, stage)
+		}
+	}
+	return next
+}
+
+// StageTransition records an entry moving from one growth stage to another.
+type StageTransition struct {
+	ID        int64
+	EntryID   string
+	UserID    string // Who moved the entry
+	TokenID   string // The personal access token they used, if any
+	From      GrowthStage
+	To        GrowthStage
+	Reason    string // Why, in the user's words; may be empty
+	CreatedAt time.Time
+}
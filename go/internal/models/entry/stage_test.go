@@ -0,0 +1,127 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGrowthStage(t *testing.T) {
+	tests := []struct {
+		s    string
+		want GrowthStage
+		ok   bool
+	}{
+		{"seed", GrowthStageSeed, true},
+		{" Bloom ", GrowthStageBloom, true},
+		{"EVERGREEN", GrowthStageEvergreen, true},
+		{"", "", false},
+		{"tree", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseGrowthStage(tt.s)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseGrowthStage(%q) = %q, %v, want %q, %v", tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// nextByStage returns l.Next for every stage.
+func nextByStage(l *Lifecycle) map[GrowthStage][]GrowthStage {
+	next := make(map[GrowthStage][]GrowthStage)
+	for _, stage := range GrowthStages {
+		next[stage] = l.Next(stage)
+	}
+	return next
+}
+
+func TestDefaultLifecycle(t *testing.T) {
+	want := map[GrowthStage][]GrowthStage{
+		GrowthStageSeed:      {GrowthStageSprout},
+		GrowthStageSprout:    {GrowthStageSeed, GrowthStageBloom},
+		GrowthStageBloom:     {GrowthStageSeed, GrowthStageSprout, GrowthStageEvergreen},
+		GrowthStageEvergreen: {GrowthStageSeed, GrowthStageSprout, GrowthStageBloom},
+	}
+	l := DefaultLifecycle()
+	if got := nextByStage(l); !reflect.DeepEqual(got, want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+	if l.Allows(GrowthStageSeed, GrowthStageBloom) {
+		t.Error("Allows(seed, bloom) = true, want false")
+	}
+	if !l.Allows(GrowthStageBloom, GrowthStageBloom) {
+		t.Error("Allows(bloom, bloom) = false, want true")
+	}
+}
+
+func TestParseLifecycle(t *testing.T) {
+	none := []GrowthStage{}
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[GrowthStage][]GrowthStage
+		wantErr string
+	}{
+		{
+			"empty allows no moves", "",
+			map[GrowthStage][]GrowthStage{GrowthStageSeed: none, GrowthStageSprout: none, GrowthStageBloom: none, GrowthStageEvergreen: none},
+			"",
+		},
+		{
+			"moves", "seed>sprout, sprout>bloom, bloom>seed",
+			map[GrowthStage][]GrowthStage{
+				GrowthStageSeed:      {GrowthStageSprout},
+				GrowthStageSprout:    {GrowthStageBloom},
+				GrowthStageBloom:     {GrowthStageSeed},
+				GrowthStageEvergreen: none,
+			},
+			"",
+		},
+		{
+			"case, spaces and empty moves", " Seed > EVERGREEN ,, seed>bloom,",
+			map[GrowthStage][]GrowthStage{
+				GrowthStageSeed:      {GrowthStageBloom, GrowthStageEvergreen},
+				GrowthStageSprout:    none,
+				GrowthStageBloom:     none,
+				GrowthStageEvergreen: none,
+			},
+			"",
+		},
+		{
+			"duplicates and staying put", "seed>sprout, seed>sprout, seed>seed",
+			map[GrowthStage][]GrowthStage{GrowthStageSeed: {GrowthStageSprout}, GrowthStageSprout: none, GrowthStageBloom: none, GrowthStageEvergreen: none},
+			"",
+		},
+		{"no arrow", "seed>sprout, bloom", nil, `growth stage transition "bloom" is not written from>to`},
+		{"unknown from", "tree>seed", nil, `unknown growth stage "tree"`},
+		{"unknown to", "seed> tree ", nil, `unknown growth stage "tree"`},
+		{"empty stage", ">seed", nil, `unknown growth stage ""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ParseLifecycle(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseLifecycle(%q) = %v, want error %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLifecycle(%q) = %v", tt.spec, err)
+			}
+			if got := nextByStage(l); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLifecycle(%q).Next = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLifecycleRejectsUnknownStages(t *testing.T) {
+	for _, transitions := range []map[GrowthStage][]GrowthStage{
+		{"tree": {GrowthStageSeed}},
+		{GrowthStageSeed: {"Sprout"}},
+	} {
+		if _, err := NewLifecycle(transitions); err == nil {
+			t.Errorf("NewLifecycle(%v) = nil error, want one", transitions)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package link
+
+// Direction says which way links are followed when walking the link graph.
+type Direction string
+
+const (
+	DirectionOut  Direction = "out"  // From source to target
+	DirectionIn   Direction = "in"   // From target back to source
+	DirectionBoth Direction = "both" // Either way
+)
+
+// Reverse returns the opposite direction; DirectionBoth is its own reverse.
+func (d Direction) Reverse() Direction {
+	switch d {
+	case DirectionOut:
+		return DirectionIn
+	case DirectionIn:
+		return DirectionOut
+	default:
+		return d
+	}
+}
+
+// NeighborhoodQuery describes the part of a user's link graph around an entry.
+// Entries that fail the filters are left out and not walked through; the
+// start entry itself is always included.
+type NeighborhoodQuery struct {
+	EntryID     string
+	Depth       int // Number of hops to walk
+	Direction   Direction
+	Relation    *string // Only follow links with this relation, if set; "" for plain links
+	GrowthStage string  // Only entries at this stage, if set
+	Tags        string  // Tag expression entries must match, if set
+	MaxNodes    int
+	MaxEdges    int
+}
+
+// ExportQuery selects the part of a user's link graph to export: every live
+// entry, or the neighborhood of RootEntryID if it is set. Entries that fail
+// the filters are left out either way.
+type ExportQuery struct {
+	RootEntryID string
+	Depth       int       // Hops to walk from the root entry
+	Direction   Direction // Which way to walk from the root entry
+	GrowthStage string    // Only entries at this stage, if set
+	Tags        string    // Tag expression entries must match, if set
+	MaxNodes    int
+	MaxEdges    int
+}
+
+// Node is an entry in a Graph.
+type Node struct {
+	EntryID     string
+	Title       string
+	GrowthStage string
+	Depth       int // Fewest hops from the start entry
+
+	// Only set in exported graphs
+	Tags          []string
+	LinkCount     int64 // Entries this one links to
+	BacklinkCount int64 // Entries linking to this one
+}
+
+// Graph is a subgraph of a user's entries and the links between them.
+type Graph struct {
+	Nodes     []*Node // Nearest first
+	Edges     []*Link
+	Truncated bool // Whether nodes or edges were left out to stay within the limits
+}
+
+// PathQuery asks how two entries are connected through links.
+type PathQuery struct {
+	FromEntryID string
+	ToEntryID   string
+	Undirected  bool // Follow links backward as well as forward
+	MaxDepth    int  // Longest path, in hops
+	MaxPaths    int
+}
+
+// Path is a chain of links from one entry to another. Steps[0] is the start entry.
+type Path struct {
+	Steps []*PathStep
+}
+
+// PathStep is an entry on a Path and the link that leads to it from the previous step.
+type PathStep struct {
+	EntryID  string
+	Title    string
+	Relation string // Relation of the link from the previous step; "" for a plain link
+	Reversed bool   // Whether that link points from this entry back to the previous one
+}
@@ -0,0 +1,24 @@
+package link
+
+import "time"
+
+// Insights are metrics over a user's whole link graph, pointing out entries
+// that need tending. Node.Depth is unused.
+type Insights struct {
+	EntryCount     int
+	LinkCount      int     // Pairs of entries joined by at least one link
+	Orphans        []*Node // Entries without links in or out
+	DeadEnds       []*Node // Entries that are linked to but link nowhere
+	TopByInDegree  []*RankedNode
+	TopByOutDegree []*RankedNode
+	TopByPageRank  []*RankedNode
+	Components     [][]*Node // Groups of entries connected by links, largest first; orphans are left out
+	Communities    [][]*Node // Densely linked clusters within the components, largest first
+	ComputedAt     time.Time
+}
+
+// RankedNode is an entry with its score on some graph metric.
+type RankedNode struct {
+	Node  *Node
+	Score float64
+}
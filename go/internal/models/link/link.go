@@ -2,13 +2,23 @@ package link
 
 import (
 	"errors"
+	"strings"
 	"time"
+	"unicode"
 )
 
 var (
 	ErrInvalidLink = errors.New("invalid link: missing required fields")
 )
 
+// Origin records how a link came to exist.
+type Origin string
+
+const (
+	OriginManual  Origin = "manual"  // Created explicitly through LinkService
+	OriginContent Origin = "content" // Derived from a [[wiki-link]] in the source entry's content
+)
+
 // Link represents a directional connection between two Entries,
 // as stored in the `entry_links` table.
 type Link struct {
@@ -16,6 +26,52 @@ type Link struct {
 	TargetEntryID string    // UUID of the entry being pointed to
 	UserID        string    // UUID of the user who created/owns this link
 	CreatedAt     time.Time // Timestamp when the link was created
+	Origin        Origin    // Whether the link was created manually or parsed from content
+	Relation      string    // What the link means, from the user's Relation vocabulary; "" for a plain link
+}
+
+// Relation is a user-defined kind of link, such as "part-of". It reads as Name
+// from the source entry and as InverseName from the target ("has-part").
+type Relation struct {
+	UserID      string
+	Name        string
+	InverseName string // Empty if the relation is symmetric, like "contradicts"
+	CreatedAt   time.Time
+}
+
+// Inverse returns how the relation reads from the target entry.
+func (r *Relation) Inverse() string {
+	if r.InverseName == "" {
+		return r.Name
+	}
+	return r.InverseName
+}
+
+// maxRelationLength bounds relation names, which are stored on every typed link.
+const maxRelationLength = 64
+
+// NormalizeRelation returns the canonical, lower-case form of a relation name.
+// Names consist of letters, digits, '-' and '_'. It reports false if name is not valid.
+func NormalizeRelation(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > maxRelationLength {
+		return "", false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+// PendingLink is a wiki-link to a title that none of the user's entries has yet,
+// as stored in the `pending_links` table.
+type PendingLink struct {
+	SourceEntryID string    // UUID of the entry containing the wiki-link
+	UserID        string    // UUID of the user who owns the source entry
+	TargetTitle   string    // Title as written in the wiki-link
+	CreatedAt     time.Time // Timestamp when the wiki-link was first seen
 }
 
 // Validate ensures the Link has all required fields.
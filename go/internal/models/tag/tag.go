@@ -0,0 +1,24 @@
+package tag
+
+import (
+	"path"
+	"time"
+)
+
+// Tag is a #tag used in a user's entries, as stored in the `tags` table.
+// Nested tags are named with '/', e.g. "lang/go" under "lang".
+type Tag struct {
+	ID         string    // UUID, unique identifier
+	UserID     string    // UUID of the user who owns this tag
+	Name       string    // Lower case, without the leading '#'
+	EntryCount int64     // Live entries with this tag or a tag nested under it
+	CreatedAt  time.Time // Timestamp when the tag was first used
+}
+
+// Parent returns the name of the tag this one is nested under, or "" for a top-level tag.
+func (t *Tag) Parent() string {
+	if parent := path.Dir(t.Name); parent != "." {
+		return parent
+	}
+	return ""
+}
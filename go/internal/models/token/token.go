@@ -0,0 +1,55 @@
+package token
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"moss/go/internal/auth"
+)
+
+// SecretPrefix marks bearer tokens that are personal access tokens
+// rather than session access tokens.
+const SecretPrefix = "moss_pat_"
+
+// Token is a named personal access token used by scripts.
+// Only a hash of the secret is stored.
+type Token struct {
+	ID         string       // UUID, unique identifier
+	UserID     string       // UUID of the user who owns this token
+	Name       string       // Human-readable label, e.g. "nightly backup"
+	TokenHash  string       // SHA-256 hash of the secret
+	Scopes     []auth.Scope // What the token is allowed to do
+	CreatedAt  time.Time    // Timestamp of creation
+	ExpiresAt  *time.Time   // Optional expiry
+	LastUsedAt *time.Time   // Last time the token authenticated a request
+	RevokedAt  *time.Time   // Set once the token is revoked
+}
+
+// Validate ensures the Token has a name, at least one known scope,
+// and an expiry (if any) in the future.
+func (t *Token) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("token must have a Name")
+	}
+	if len(t.Scopes) == 0 {
+		return errors.New("token must have at least one Scope")
+	}
+	for _, s := range t.Scopes {
+		if !auth.IsKnownScope(s) {
+			return errors.New("token has unknown Scope " + string(s))
+		}
+	}
+	if t.ExpiresAt != nil && !t.ExpiresAt.After(time.Now()) {
+		return errors.New("token ExpiresAt must be in the future")
+	}
+	return nil
+}
+
+// Active reports whether the token can still be used at time now.
+func (t *Token) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,66 @@
+package user
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gardenSlugPattern restricts garden slugs to short, URL-safe names.
+var gardenSlugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{1,38}[a-z0-9])$`)
+
+// User is an account that owns a digital garden.
+type User struct {
+	ID           string    // UUID, unique identifier
+	Email        string    // Login email, stored lowercase
+	PasswordHash string    // bcrypt hash of the user's password
+	DisplayName  string    // Name shown in the UI
+	GardenSlug   string    // Unique, URL-safe name of the user's garden
+	CreatedAt    time.Time // Timestamp of creation
+	UpdatedAt    time.Time // Timestamp of last update
+}
+
+// Validate ensures the User's profile fields are well-formed.
+func (u *User) Validate() error {
+	if !strings.Contains(u.Email, "@") {
+		return errors.New("user must have a valid Email")
+	}
+	if strings.TrimSpace(u.DisplayName) == "" {
+		return errors.New("user must have a DisplayName")
+	}
+	if !gardenSlugPattern.MatchString(u.GardenSlug) {
+		return errors.New("user GardenSlug must be 3-40 lowercase letters, digits or dashes")
+	}
+	return nil
+}
+
+// NormalizeEmail canonicalizes an email address for storage and lookup.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Session is a login session. The refresh token itself is never stored,
+// only its hash.
+type Session struct {
+	ID               string     // UUID, unique identifier
+	UserID           string     // UUID of the user the session belongs to
+	RefreshTokenHash string     // SHA-256 hash of the current refresh token
+	CreatedAt        time.Time  // Timestamp of login
+	ExpiresAt        time.Time  // Expiry of the current refresh token
+	RevokedAt        *time.Time // Set once the session is logged out
+}
+
+// Active reports whether the session can still be used at time now.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SessionTokens are the credentials handed to a client on login or refresh.
+type SessionTokens struct {
+	SessionID             string
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
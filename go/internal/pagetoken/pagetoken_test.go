@@ -0,0 +1,68 @@
+package pagetoken
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+type state struct {
+	Query  string `json:"q"`
+	Offset int    `json:"o"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+	want := state{Query: "abc", Offset: 50}
+
+	token, err := c.Encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got state
+	if err := c.Decode(token, &got); err != nil {
+		t.Fatalf("Decode(%q) = %v", token, err)
+	}
+	if got != want {
+		t.Errorf("Decode(%q) = %+v, want %+v", token, got, want)
+	}
+}
+
+func TestDecodeRejectsTampering(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+	token, err := c.Encode(state{Query: "abc", Offset: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, sig, _ := strings.Cut(token, ".")
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"q":"abc","o":5000}`))
+	otherKey, err := NewCodec([]byte("other secret")).Encode(state{Query: "abc", Offset: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notJSON := base64.RawURLEncoding.EncodeToString([]byte("not json"))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", encoded},
+		{"empty signature", encoded + "."},
+		{"changed payload", forged + "." + sig},
+		{"changed signature", encoded + "." + strings.ToUpper(sig)},
+		{"truncated signature", encoded + "." + sig[:len(sig)-1]},
+		{"other key", otherKey},
+		{"signed but not base64", "!!!." + c.sign("!!!")},
+		{"signed but not JSON", notJSON + "." + c.sign(notJSON)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got state
+			if err := c.Decode(tt.token, &got); err != ErrInvalidToken {
+				t.Errorf("Decode(%q) = %v, want ErrInvalidToken", tt.token, err)
+			}
+		})
+	}
+}
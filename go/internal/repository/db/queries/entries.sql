@@ -5,8 +5,10 @@ INSERT INTO entries (id,
                      content,
                      growth_stage,
                      created_at,
-                     updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+                     updated_at,
+                     aliases)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (id) DO NOTHING
 RETURNING *;
 
 -- name: GetEntryByID :one
@@ -18,25 +20,372 @@ WHERE id = $1;
 SELECT *
 FROM entries
 WHERE user_id = $1
+  AND deleted_at IS NULL
 ORDER BY created_at;
 
 -- name: ListEntriesByUserSince :many
 SELECT *
 FROM entries
 WHERE user_id = $1
+  AND deleted_at IS NULL
   AND updated_at > $2
 ORDER BY updated_at;
 
+-- Returns no row if expected_version is set and no longer matches.
 -- name: UpdateEntry :one
 UPDATE entries
 SET title        = $2,
     content      = $3,
     growth_stage = $4,
-    updated_at   = $5
+    updated_at   = $5,
+    aliases      = $6,
+    version      = version + 1
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND (sqlc.narg(expected_version)::bigint IS NULL OR version = sqlc.narg(expected_version))
+RETURNING *;
+
+-- Updates only the non-null fields, so unchanged content is not sent or rewritten.
+-- Returns no row if expected_version is set and no longer matches.
+-- name: UpdateEntryFields :one
+UPDATE entries
+SET title        = COALESCE(sqlc.narg(title), title),
+    content      = COALESCE(sqlc.narg(content), content),
+    growth_stage = COALESCE(sqlc.narg(growth_stage), growth_stage),
+    aliases      = COALESCE(sqlc.narg(aliases)::text[], aliases),
+    updated_at   = sqlc.arg(updated_at),
+    version      = version + 1
+WHERE id = sqlc.arg(id)
+  AND deleted_at IS NULL
+  AND (sqlc.narg(expected_version)::bigint IS NULL OR version = sqlc.narg(expected_version))
 RETURNING *;
 
 -- name: DeleteEntry :exec
 DELETE
 FROM entries
 WHERE id = $1;
+
+-- Records that an idempotency key produced entry_id. Returns no row when the key
+-- is already held by an unexpired claim.
+-- name: ClaimIdempotencyKey :one
+INSERT INTO entry_idempotency_keys (user_id,
+                                    idempotency_key,
+                                    entry_id,
+                                    created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+    SET entry_id   = EXCLUDED.entry_id,
+        created_at = EXCLUDED.created_at
+WHERE entry_idempotency_keys.created_at < sqlc.arg(expired_before)
+RETURNING entry_id;
+
+-- name: GetIdempotencyKeyEntryID :one
+SELECT entry_id
+FROM entry_idempotency_keys
+WHERE user_id = $1
+  AND idempotency_key = $2;
+
+-- name: ListEntriesPageByCreated :many
+SELECT *
+FROM entries
+WHERE user_id = sqlc.arg(user_id)
+  AND deleted_at IS NULL
+  AND (sqlc.narg(growth_stage)::text IS NULL OR growth_stage = sqlc.narg(growth_stage))
+  AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after))
+  AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at < sqlc.narg(created_before))
+  AND (sqlc.narg(updated_after)::timestamp IS NULL OR updated_at >= sqlc.narg(updated_after))
+  AND (sqlc.narg(updated_before)::timestamp IS NULL OR updated_at < sqlc.narg(updated_before))
+  AND (starts_with(lower(title), lower(sqlc.arg(title_prefix)::text))
+    OR EXISTS (SELECT 1
+               FROM entry_aliases AS a
+               WHERE a.entry_id = entries.id
+                 AND starts_with(a.alias_key, lower(sqlc.arg(title_prefix)::text))))
+  AND (NOT sqlc.arg(filter_by_ids)::bool OR id = ANY (sqlc.arg(entry_ids)::text[]))
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (sqlc.arg(descending)::bool AND (created_at, id) < (sqlc.narg(cursor_created_at), sqlc.arg(cursor_id)::text))
+    OR (NOT sqlc.arg(descending)::bool AND (created_at, id) > (sqlc.narg(cursor_created_at), sqlc.arg(cursor_id)::text)))
+ORDER BY CASE WHEN sqlc.arg(descending)::bool THEN created_at END DESC,
+         CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+         created_at,
+         id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListEntriesPageByUpdated :many
+SELECT *
+FROM entries
+WHERE user_id = sqlc.arg(user_id)
+  AND deleted_at IS NULL
+  AND (sqlc.narg(growth_stage)::text IS NULL OR growth_stage = sqlc.narg(growth_stage))
+  AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after))
+  AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at < sqlc.narg(created_before))
+  AND (sqlc.narg(updated_after)::timestamp IS NULL OR updated_at >= sqlc.narg(updated_after))
+  AND (sqlc.narg(updated_before)::timestamp IS NULL OR updated_at < sqlc.narg(updated_before))
+  AND (starts_with(lower(title), lower(sqlc.arg(title_prefix)::text))
+    OR EXISTS (SELECT 1
+               FROM entry_aliases AS a
+               WHERE a.entry_id = entries.id
+                 AND starts_with(a.alias_key, lower(sqlc.arg(title_prefix)::text))))
+  AND (NOT sqlc.arg(filter_by_ids)::bool OR id = ANY (sqlc.arg(entry_ids)::text[]))
+  AND (sqlc.narg(cursor_updated_at)::timestamp IS NULL
+    OR (sqlc.arg(descending)::bool AND (updated_at, id) < (sqlc.narg(cursor_updated_at), sqlc.arg(cursor_id)::text))
+    OR (NOT sqlc.arg(descending)::bool AND (updated_at, id) > (sqlc.narg(cursor_updated_at), sqlc.arg(cursor_id)::text)))
+ORDER BY CASE WHEN sqlc.arg(descending)::bool THEN updated_at END DESC,
+         CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+         updated_at,
+         id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListEntriesPageByTitle :many
+SELECT *
+FROM entries
+WHERE user_id = sqlc.arg(user_id)
+  AND deleted_at IS NULL
+  AND (sqlc.narg(growth_stage)::text IS NULL OR growth_stage = sqlc.narg(growth_stage))
+  AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after))
+  AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at < sqlc.narg(created_before))
+  AND (sqlc.narg(updated_after)::timestamp IS NULL OR updated_at >= sqlc.narg(updated_after))
+  AND (sqlc.narg(updated_before)::timestamp IS NULL OR updated_at < sqlc.narg(updated_before))
+  AND (starts_with(lower(title), lower(sqlc.arg(title_prefix)::text))
+    OR EXISTS (SELECT 1
+               FROM entry_aliases AS a
+               WHERE a.entry_id = entries.id
+                 AND starts_with(a.alias_key, lower(sqlc.arg(title_prefix)::text))))
+  AND (NOT sqlc.arg(filter_by_ids)::bool OR id = ANY (sqlc.arg(entry_ids)::text[]))
+  AND (sqlc.narg(cursor_title)::text IS NULL
+    OR (sqlc.arg(descending)::bool AND (title, id) < (sqlc.narg(cursor_title), sqlc.arg(cursor_id)::text))
+    OR (NOT sqlc.arg(descending)::bool AND (title, id) > (sqlc.narg(cursor_title), sqlc.arg(cursor_id)::text)))
+ORDER BY CASE WHEN sqlc.arg(descending)::bool THEN title END DESC,
+         CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+         title,
+         id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListEntriesPageByLinkCount :many
+SELECT *
+FROM (SELECT e.*,
+             (SELECT COUNT(DISTINCT l.target_entry_id)
+              FROM entry_links AS l
+                       JOIN entries AS t ON t.id = l.target_entry_id AND t.deleted_at IS NULL
+              WHERE l.source_entry_id = e.id) AS link_count
+      FROM entries AS e
+      WHERE e.user_id = sqlc.arg(user_id)
+        AND e.deleted_at IS NULL
+        AND (sqlc.narg(growth_stage)::text IS NULL OR e.growth_stage = sqlc.narg(growth_stage))
+        AND (sqlc.narg(created_after)::timestamp IS NULL OR e.created_at >= sqlc.narg(created_after))
+        AND (sqlc.narg(created_before)::timestamp IS NULL OR e.created_at < sqlc.narg(created_before))
+        AND (sqlc.narg(updated_after)::timestamp IS NULL OR e.updated_at >= sqlc.narg(updated_after))
+        AND (sqlc.narg(updated_before)::timestamp IS NULL OR e.updated_at < sqlc.narg(updated_before))
+        AND (starts_with(lower(e.title), lower(sqlc.arg(title_prefix)::text))
+          OR EXISTS (SELECT 1
+                     FROM entry_aliases AS a
+                     WHERE a.entry_id = e.id
+                       AND starts_with(a.alias_key, lower(sqlc.arg(title_prefix)::text))))
+        AND (NOT sqlc.arg(filter_by_ids)::bool OR e.id = ANY (sqlc.arg(entry_ids)::text[]))) AS counted
+WHERE (sqlc.narg(cursor_link_count)::bigint IS NULL
+    OR (sqlc.arg(descending)::bool AND (link_count, id) < (sqlc.narg(cursor_link_count), sqlc.arg(cursor_id)::text))
+    OR (NOT sqlc.arg(descending)::bool AND (link_count, id) > (sqlc.narg(cursor_link_count), sqlc.arg(cursor_id)::text)))
+ORDER BY CASE WHEN sqlc.arg(descending)::bool THEN link_count END DESC,
+         CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+         link_count,
+         id
+LIMIT sqlc.arg(page_limit);
+
+-- Finds the user's entries whose titles or aliases match any of the given
+-- lowercased titles. A title wins over an alias; when several entries share
+-- a title, the oldest one wins.
+-- name: ResolveEntryTitles :many
+SELECT DISTINCT ON (names.title_key) names.id, names.title_key::text AS title_key
+FROM (SELECT id, lower(title) AS title_key, FALSE AS is_alias, created_at
+      FROM entries
+      WHERE user_id = sqlc.arg(user_id)
+        AND deleted_at IS NULL
+        AND lower(title) = ANY (sqlc.arg(title_keys)::text[])
+      UNION ALL
+      SELECT e.id, a.alias_key, TRUE, e.created_at
+      FROM entry_aliases AS a
+               JOIN entries AS e ON e.id = a.entry_id AND e.deleted_at IS NULL
+      WHERE a.user_id = sqlc.arg(user_id)
+        AND a.alias_key = ANY (sqlc.arg(title_keys)::text[])) AS names
+ORDER BY names.title_key, names.is_alias, names.created_at, names.id;
+
+-- Returns one of name_keys that is already an alias of another of the user's
+-- live entries or, with check_titles, the lowercased title of another live entry.
+-- Names of entries in the trash are free, as they are for wiki-links.
+-- name: FindTakenName :one
+SELECT names.name_key::text AS name_key, names.entry_id
+FROM (SELECT a.alias_key AS name_key, a.entry_id
+      FROM entry_aliases AS a
+               JOIN entries AS e ON e.id = a.entry_id AND e.deleted_at IS NULL
+      WHERE a.user_id = sqlc.arg(user_id)
+        AND a.entry_id <> sqlc.arg(entry_id)
+        AND a.alias_key = ANY (sqlc.arg(name_keys)::text[])
+      UNION ALL
+      SELECT lower(title), id
+      FROM entries
+      WHERE sqlc.arg(check_titles)::bool
+        AND user_id = sqlc.arg(user_id)
+        AND id <> sqlc.arg(entry_id)
+        AND deleted_at IS NULL
+        AND lower(title) = ANY (sqlc.arg(name_keys)::text[])) AS names
+LIMIT 1;
+
+-- name: DeleteEntryAliases :exec
+DELETE
+FROM entry_aliases
+WHERE entry_id = $1;
+
+-- name: CreateEntryAliases :exec
+INSERT INTO entry_aliases (user_id, alias_key, entry_id, alias)
+SELECT sqlc.arg(user_id), a.alias_key, sqlc.arg(entry_id), a.alias
+FROM unnest(sqlc.arg(aliases)::text[], sqlc.arg(alias_keys)::text[]) AS a(alias, alias_key);
+
+-- Returns the subset of the given entry IDs that belong to the user.
+-- name: ListOwnedEntryIDs :many
+SELECT id
+FROM entries
+WHERE user_id = sqlc.arg(user_id)
+  AND deleted_at IS NULL
+  AND id = ANY (sqlc.arg(ids)::text[]);
+
+-- Snapshots the entry's current state as its next revision.
+-- name: CreateEntryRevision :one
+INSERT INTO entry_revisions (entry_id, revision, title, content, growth_stage, created_at)
+SELECT id,
+       COALESCE((SELECT MAX(revision) FROM entry_revisions WHERE entry_id = $1), 0) + 1,
+       title,
+       content,
+       growth_stage,
+       updated_at
+FROM entries
+WHERE id = $1
+RETURNING *;
+
+-- name: GetEntryRevision :one
+SELECT *
+FROM entry_revisions
+WHERE entry_id = $1
+  AND revision = $2;
+
+-- name: ListEntryRevisions :many
+SELECT *
+FROM entry_revisions
+WHERE entry_id = $1
+ORDER BY revision DESC;
+
+-- name: CreateStageTransition :one
+INSERT INTO stage_transitions (entry_id, user_id, token_id, from_stage, to_stage, reason, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListStageTransitions :many
+SELECT *
+FROM stage_transitions
+WHERE entry_id = $1
+ORDER BY id DESC;
+
+-- name: TrashEntry :execrows
+UPDATE entries
+SET deleted_at = $2
+WHERE id = $1
+  AND deleted_at IS NULL;
+
+-- name: RestoreEntry :one
+UPDATE entries
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: ListTrashedEntries :many
+SELECT *
+FROM entries
+WHERE user_id = $1
+  AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- Entries of any user trashed before the cutoff, for the purger.
+-- name: ListEntriesTrashedBefore :many
+SELECT *
+FROM entries
+WHERE deleted_at < sqlc.arg(trashed_before)
+ORDER BY deleted_at;
+
+-- Full-text search over the user's live entries, best match first. query is in
+-- to_tsquery syntax. Highlights are computed only for the returned page.
+-- name: SearchEntries :many
+WITH search AS (SELECT to_tsquery('english', sqlc.arg(query)::text) AS query)
+SELECT page.id,
+       page.user_id,
+       page.title,
+       page.content,
+       page.growth_stage,
+       page.created_at,
+       page.updated_at,
+       page.version,
+       page.deleted_at,
+       page.aliases,
+       page.rank,
+       ts_headline('english', page.title, search.query,
+                   'HighlightAll=true, ' || sqlc.arg(headline_options)::text) AS title_highlight,
+       ts_headline('english', page.content, search.query,
+                   'MaxFragments=2, MinWords=8, MaxWords=24, ' || sqlc.arg(headline_options)::text) AS snippet
+FROM (SELECT *
+      FROM (SELECT e.id, e.user_id, e.title, e.content, e.growth_stage, e.created_at, e.updated_at, e.version, e.deleted_at, e.aliases,
+                   ts_rank_cd(e.search_vector, search.query) AS rank
+            FROM entries AS e, search
+            WHERE e.user_id = sqlc.arg(user_id)
+              AND e.deleted_at IS NULL
+              AND e.search_vector @@ search.query
+              AND (sqlc.narg(growth_stage)::text IS NULL OR e.growth_stage = sqlc.narg(growth_stage))
+              AND (NOT sqlc.arg(filter_by_ids)::bool OR e.id = ANY (sqlc.arg(entry_ids)::text[]))) AS ranked
+      WHERE (sqlc.narg(cursor_rank)::real IS NULL
+          OR rank < sqlc.narg(cursor_rank)
+          OR (rank = sqlc.narg(cursor_rank) AND id > sqlc.arg(cursor_id)::text))
+      ORDER BY rank DESC, id
+      LIMIT sqlc.arg(page_limit)) AS page, search
+ORDER BY page.rank DESC, page.id;
+
+-- Typo-tolerant title and alias lookup. Up to max_candidates of the closest matches
+-- are re-ranked by a score that also rewards recent edits (halving after 30
+-- days) and inbound links, counted as in CountLinksByTarget.
+-- name: SuggestEntries :many
+SELECT c.id,
+       c.user_id,
+       c.title,
+       c.content,
+       c.growth_stage,
+       c.created_at,
+       c.updated_at,
+       c.version,
+       c.deleted_at,
+       c.aliases,
+       c.similarity,
+       c.link_count,
+       (0.7 * c.similarity
+           + 0.15 / (1 + GREATEST(EXTRACT(EPOCH FROM sqlc.arg(now)::timestamp - c.updated_at), 0) / 2592000)
+           + 0.15 * c.link_count / (c.link_count + 5))::real AS score
+FROM (SELECT m.*,
+             (SELECT COUNT(DISTINCT l.source_entry_id)
+              FROM entry_links AS l
+              WHERE l.target_entry_id = m.id
+                AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = l.source_entry_id AND s.deleted_at IS NOT NULL)) AS link_count
+      FROM (SELECT e.id, e.user_id, e.title, e.content, e.growth_stage, e.created_at, e.updated_at, e.version, e.deleted_at, e.aliases,
+                   best.similarity
+            FROM (SELECT names.entry_id, MAX(word_similarity(sqlc.arg(query)::text, names.name)) AS similarity
+                  FROM (SELECT id AS entry_id, title AS name
+                        FROM entries
+                        WHERE user_id = sqlc.arg(user_id)
+                          AND deleted_at IS NULL
+                          AND sqlc.arg(query)::text <% title
+                        UNION ALL
+                        SELECT a.entry_id, a.alias
+                        FROM entry_aliases AS a
+                                 JOIN entries AS ae ON ae.id = a.entry_id AND ae.deleted_at IS NULL
+                        WHERE a.user_id = sqlc.arg(user_id)
+                          AND sqlc.arg(query)::text <% a.alias) AS names
+                  GROUP BY names.entry_id
+                  ORDER BY similarity DESC, names.entry_id
+                  LIMIT sqlc.arg(max_candidates)) AS best
+                     JOIN entries AS e ON e.id = best.entry_id) AS m) AS c
+ORDER BY score DESC, c.id
+LIMIT sqlc.arg(max_results);
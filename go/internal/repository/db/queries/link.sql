@@ -7,58 +7,78 @@ INSERT INTO entry_links (
     source_entry_id,
     target_entry_id,
     user_id,
-    created_at
+    created_at,
+    origin,
+    relation
 ) VALUES (
              $1,  -- source_entry_id
              $2,  -- target_entry_id
              $3,  -- user_id (who created/owns this link)
-             CURRENT_TIMESTAMP
+             CURRENT_TIMESTAMP,
+             'manual',
+             $4   -- relation ('' for a plain link)
          )
-RETURNING source_entry_id, target_entry_id, user_id, created_at;
+RETURNING source_entry_id, target_entry_id, user_id, created_at, origin, relation;
 
--- 2. Delete a link (unlink two entries)
+-- 2. Delete a manual link (unlink two entries)
+-- Content links follow the source entry's Markdown and cannot be deleted directly.
 -- name: DeleteEntryLink :exec
 DELETE FROM entry_links
 WHERE source_entry_id = $1
-  AND target_entry_id = $2;
+  AND target_entry_id = $2
+  AND relation = $3
+  AND origin = 'manual';
 
 -- 3. List all links where a given entry is the “source”
--- (i.e. all outgoing links from entry X)
+-- (i.e. all outgoing links from entry X), optionally only those with one relation
 -- name: ListLinksBySource :many
 SELECT
     source_entry_id,
     target_entry_id,
     user_id,
-    created_at
+    created_at,
+    origin,
+    relation
 FROM entry_links
-WHERE source_entry_id = $1
+WHERE source_entry_id = sqlc.arg(source_entry_id)
+  AND (sqlc.narg(relation)::text IS NULL OR relation = sqlc.narg(relation))
+  AND NOT EXISTS (SELECT 1 FROM entries AS t WHERE t.id = target_entry_id AND t.deleted_at IS NOT NULL)
 ORDER BY created_at;
 
 -- 4. List all links where a given entry is the “target”
--- (i.e. all incoming/backlinks to entry X)
+-- (i.e. all incoming/backlinks to entry X), optionally only those with one relation
 -- name: ListLinksByTarget :many
 SELECT
     source_entry_id,
     target_entry_id,
     user_id,
-    created_at
+    created_at,
+    origin,
+    relation
 FROM entry_links
-WHERE target_entry_id = $1
+WHERE target_entry_id = sqlc.arg(target_entry_id)
+  AND (sqlc.narg(relation)::text IS NULL OR relation = sqlc.narg(relation))
+  AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = source_entry_id AND s.deleted_at IS NOT NULL)
 ORDER BY created_at;
 
 -- 5. Count how many outgoing links a given entry has
--- (useful for setting “link_count” in your proto if you want outgoing count)
+-- (useful for setting “link_count” in your proto if you want outgoing count),
+-- optionally only through links with one relation
 -- name: CountLinksBySource :one
-SELECT COUNT(*) AS count
+SELECT COUNT(DISTINCT target_entry_id) AS count
 FROM entry_links
-WHERE source_entry_id = $1;
+WHERE source_entry_id = sqlc.arg(source_entry_id)
+  AND (sqlc.narg(relation)::text IS NULL OR relation = sqlc.narg(relation))
+  AND NOT EXISTS (SELECT 1 FROM entries AS t WHERE t.id = target_entry_id AND t.deleted_at IS NOT NULL);
 
 -- 6. Count how many incoming links a given entry has
--- (useful for backlink counts)
+-- (useful for backlink counts), optionally only through links with one relation
 -- name: CountLinksByTarget :one
-SELECT COUNT(*) AS count
+SELECT COUNT(DISTINCT source_entry_id) AS count
 FROM entry_links
-WHERE target_entry_id = $1;
+WHERE target_entry_id = sqlc.arg(target_entry_id)
+  AND (sqlc.narg(relation)::text IS NULL OR relation = sqlc.narg(relation))
+  AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = source_entry_id AND s.deleted_at IS NOT NULL);
 
 -- 7. (Optional) List the actual Entry rows that a given source is linked to,
 --     with pagination parameters (page size + offset). This is if you want to
@@ -71,17 +91,22 @@ SELECT
     e.content,
     e.growth_stage,
     e.created_at,
-    e.updated_at
+    e.updated_at,
+    e.version,
+    e.deleted_at,
+    e.aliases,
+    e.search_vector
 FROM entries AS e
          JOIN entry_links AS l
               ON l.target_entry_id = e.id
 WHERE l.source_entry_id = $1
+  AND e.deleted_at IS NULL
 ORDER BY e.created_at
 LIMIT $2      -- page_size
     OFFSET $3;    -- offset (page_token converted to integer)
 
--- 8. (Optional) List the actual Entry rows that link *into* a given entry,
---     with pagination. Adjust as above.
+-- 8. List the other live entries that link *into* a given entry, once each
+--     however many links they have, with pagination.
 -- name: ListBacklinkedEntries :many
 SELECT
     e.id,
@@ -90,11 +115,246 @@ SELECT
     e.content,
     e.growth_stage,
     e.created_at,
-    e.updated_at
+    e.updated_at,
+    e.version,
+    e.deleted_at,
+    e.aliases,
+    e.search_vector
 FROM entries AS e
-         JOIN entry_links AS l
-              ON l.source_entry_id = e.id
-WHERE l.target_entry_id = $1
-ORDER BY e.created_at
+WHERE e.id IN (SELECT l.source_entry_id FROM entry_links AS l WHERE l.target_entry_id = $1)
+  AND e.id <> $1
+  AND e.deleted_at IS NULL
+ORDER BY e.created_at, e.id
 LIMIT $2      -- page_size
     OFFSET $3;    -- offset (page_token)
+
+-- 9. Remove content links from a source entry whose targets are no longer
+--     referenced by its Markdown.
+-- name: DeleteStaleContentLinks :exec
+DELETE FROM entry_links
+WHERE source_entry_id = sqlc.arg(source_entry_id)
+  AND origin = 'content'
+  AND NOT (target_entry_id = ANY (sqlc.arg(keep_target_entry_ids)::text[]));
+
+-- 10. Add content links from a source entry, skipping ones that already exist.
+-- name: CreateContentLinks :exec
+INSERT INTO entry_links (source_entry_id, target_entry_id, user_id, created_at, origin)
+SELECT sqlc.arg(source_entry_id), target, sqlc.arg(user_id), CURRENT_TIMESTAMP, 'content'
+FROM unnest(sqlc.arg(target_entry_ids)::text[]) AS target
+ON CONFLICT DO NOTHING;
+
+-- 11. Remove pending links from a source entry whose titles are no longer
+--     referenced by its Markdown.
+-- name: DeleteStalePendingLinks :exec
+DELETE FROM pending_links
+WHERE source_entry_id = sqlc.arg(source_entry_id)
+  AND NOT (target_key = ANY (sqlc.arg(keep_target_keys)::text[]));
+
+-- 12. Record wiki-links from a source entry to titles that do not exist yet.
+-- name: CreatePendingLinks :exec
+INSERT INTO pending_links (source_entry_id, user_id, target_title, target_key, created_at)
+SELECT sqlc.arg(source_entry_id), sqlc.arg(user_id), t.title, t.key, CURRENT_TIMESTAMP
+FROM unnest(sqlc.arg(target_titles)::text[], sqlc.arg(target_keys)::text[]) AS t(title, key)
+ON CONFLICT DO NOTHING;
+
+-- 13. Turn the user's pending links to any of an entry's names (its title and
+--     aliases) into content links to the entry.
+-- name: RebindPendingLinks :execrows
+WITH bound AS (
+    DELETE FROM pending_links
+    WHERE user_id = sqlc.arg(user_id)
+      AND target_key = ANY (sqlc.arg(target_keys)::text[])
+      AND source_entry_id <> sqlc.arg(target_entry_id)
+    RETURNING source_entry_id, user_id
+)
+INSERT INTO entry_links (source_entry_id, target_entry_id, user_id, created_at, origin)
+SELECT source_entry_id, sqlc.arg(target_entry_id), user_id, CURRENT_TIMESTAMP, 'content'
+FROM bound
+ON CONFLICT DO NOTHING;
+
+-- 14. List a user's pending links, optionally only those from one source entry.
+-- name: ListPendingLinks :many
+SELECT *
+FROM pending_links
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(source_entry_id)::text = '' OR source_entry_id = sqlc.arg(source_entry_id))
+  AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = source_entry_id AND s.deleted_at IS NOT NULL)
+ORDER BY target_key, source_entry_id;
+
+-- 15. Delete every link into or out of an entry, returning the removed links.
+-- name: DeleteLinksByEntry :many
+DELETE FROM entry_links
+WHERE source_entry_id = sqlc.arg(entry_id)
+   OR target_entry_id = sqlc.arg(entry_id)
+RETURNING *;
+
+-- 16. Register a link relation in the user's vocabulary.
+-- name: CreateLinkRelation :one
+INSERT INTO link_relations (user_id, name, inverse_name, created_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(name), sqlc.arg(inverse_name), sqlc.arg(created_at))
+RETURNING *;
+
+-- 17. List the user's link relations.
+-- name: ListLinkRelations :many
+SELECT *
+FROM link_relations
+WHERE user_id = sqlc.arg(user_id)
+ORDER BY name;
+
+-- 18. Remove a link relation from the user's vocabulary.
+-- name: DeleteLinkRelation :execrows
+DELETE FROM link_relations
+WHERE user_id = sqlc.arg(user_id)
+  AND name = sqlc.arg(name);
+
+-- 19. Report whether any of the user's links uses one of the given relation names.
+-- name: LinkRelationInUse :one
+SELECT EXISTS (SELECT 1
+               FROM entry_links
+               WHERE user_id = sqlc.arg(user_id)
+                 AND relation = ANY (sqlc.arg(names)::text[]))::bool AS in_use;
+
+-- 20. List the user's other live entries whose content contains one of names,
+--     ignoring case, but that do not link to the entry yet, most recently
+--     updated first. Callers must still check for whole-word matches outside code.
+-- name: ListMentioningEntries :many
+SELECT
+    e.id,
+    e.user_id,
+    e.title,
+    e.content,
+    e.growth_stage,
+    e.created_at,
+    e.updated_at,
+    e.version,
+    e.deleted_at,
+    e.aliases,
+    e.search_vector
+FROM entries AS e
+WHERE e.user_id = sqlc.arg(user_id)
+  AND e.id <> sqlc.arg(entry_id)
+  AND e.deleted_at IS NULL
+  AND EXISTS (SELECT 1
+              FROM unnest(sqlc.arg(names)::text[]) AS n(name)
+              WHERE strpos(lower(e.content), lower(n.name)) > 0)
+  AND NOT EXISTS (SELECT 1
+                  FROM entry_links AS l
+                  WHERE l.source_entry_id = e.id
+                    AND l.target_entry_id = sqlc.arg(entry_id))
+ORDER BY e.updated_at DESC, e.id
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+
+-- 21. Walk entry_links out from an entry, following links forward (outgoing),
+--     backward (incoming) or both, up to max_depth hops. Only live entries that
+--     pass the filters are entered. Each reachable entry is returned once, at its
+--     shortest distance, nearest first; the start entry has depth 0.
+-- name: GetNeighborhoodNodes :many
+WITH RECURSIVE walk (entry_id, depth) AS (
+    SELECT sqlc.arg(entry_id)::text, 0
+    UNION
+    SELECT e.id, w.depth + 1
+    FROM walk AS w
+             JOIN entry_links AS l
+                  ON (sqlc.arg(outgoing)::bool AND l.source_entry_id = w.entry_id)
+                      OR (sqlc.arg(incoming)::bool AND l.target_entry_id = w.entry_id)
+             JOIN entries AS e
+                  ON e.id = CASE WHEN l.source_entry_id = w.entry_id THEN l.target_entry_id ELSE l.source_entry_id END
+    WHERE w.depth < sqlc.arg(max_depth)::int
+      AND (sqlc.narg(relation)::text IS NULL OR l.relation = sqlc.narg(relation))
+      AND e.deleted_at IS NULL
+      AND (sqlc.narg(growth_stage)::text IS NULL OR e.growth_stage = sqlc.narg(growth_stage))
+      AND (NOT sqlc.arg(filter_by_ids)::bool OR e.id = ANY (sqlc.arg(entry_ids)::text[]))
+)
+SELECT e.id, e.title, e.growth_stage, MIN(w.depth)::int AS depth
+FROM walk AS w
+         JOIN entries AS e ON e.id = w.entry_id
+GROUP BY e.id, e.title, e.growth_stage
+ORDER BY depth, e.id
+LIMIT sqlc.arg(max_nodes);
+
+-- 22. List the links between the given entries, optionally only those with one relation.
+-- name: ListLinksAmong :many
+SELECT source_entry_id, target_entry_id, user_id, created_at, origin, relation
+FROM entry_links
+WHERE source_entry_id = ANY (sqlc.arg(entry_ids)::text[])
+  AND target_entry_id = ANY (sqlc.arg(entry_ids)::text[])
+  AND (sqlc.narg(relation)::text IS NULL OR relation = sqlc.narg(relation))
+ORDER BY source_entry_id, target_entry_id, origin, relation
+LIMIT sqlc.arg(max_edges);
+
+-- 23. Find simple paths between two entries over the user's links between live
+--     entries, shortest first, at most max_depth hops long. Links are followed
+--     forward, and backward too if undirected. Paths through the same entries
+--     using different relations are distinct. Every path up to max_depth is
+--     found before the shortest are picked, so max_depth bounds the work.
+-- name: FindPaths :many
+WITH RECURSIVE edges AS (
+    SELECT DISTINCT l.source_entry_id, l.target_entry_id, l.relation
+    FROM entry_links AS l
+             JOIN entries AS s ON s.id = l.source_entry_id AND s.deleted_at IS NULL
+             JOIN entries AS t ON t.id = l.target_entry_id AND t.deleted_at IS NULL
+    WHERE l.user_id = sqlc.arg(user_id)
+), paths (entry_id, entry_ids, relations, reversed) AS (
+    SELECT sqlc.arg(from_entry_id)::text, ARRAY [sqlc.arg(from_entry_id)::text], ARRAY []::text[], ARRAY []::bool[]
+    UNION ALL
+    SELECT CASE WHEN e.source_entry_id = p.entry_id THEN e.target_entry_id ELSE e.source_entry_id END,
+           p.entry_ids || CASE WHEN e.source_entry_id = p.entry_id THEN e.target_entry_id ELSE e.source_entry_id END,
+           p.relations || e.relation,
+           p.reversed || (e.source_entry_id <> p.entry_id)
+    FROM paths AS p
+             JOIN edges AS e
+                  ON e.source_entry_id = p.entry_id
+                      OR (sqlc.arg(undirected)::bool AND e.target_entry_id = p.entry_id)
+    WHERE p.entry_id <> sqlc.arg(to_entry_id)
+      AND cardinality(p.entry_ids) <= sqlc.arg(max_depth)::int
+      AND NOT (CASE WHEN e.source_entry_id = p.entry_id THEN e.target_entry_id ELSE e.source_entry_id END) = ANY (p.entry_ids)
+)
+SELECT p.entry_ids::text[] AS entry_ids,
+       p.relations::text[] AS relations,
+       p.reversed::bool[]  AS reversed,
+       (SELECT array_agg(en.title ORDER BY u.ord)
+        FROM unnest(p.entry_ids) WITH ORDINALITY AS u(id, ord)
+                 JOIN entries AS en ON en.id = u.id)::text[] AS titles
+FROM paths AS p
+WHERE p.entry_id = sqlc.arg(to_entry_id)
+ORDER BY cardinality(p.entry_ids), p.entry_ids, p.relations, p.reversed
+LIMIT sqlc.arg(max_paths);
+
+-- 24. List the user's live entries as nodes of their link graph.
+-- name: ListGraphNodes :many
+SELECT id, title, growth_stage
+FROM entries
+WHERE user_id = sqlc.arg(user_id)
+  AND deleted_at IS NULL
+ORDER BY id;
+
+-- 25. List each pair of the user's live entries joined by at least one link, once,
+--     ignoring links from an entry to itself.
+-- name: ListGraphEdges :many
+SELECT DISTINCT l.source_entry_id, l.target_entry_id
+FROM entry_links AS l
+         JOIN entries AS s ON s.id = l.source_entry_id AND s.deleted_at IS NULL
+         JOIN entries AS t ON t.id = l.target_entry_id AND t.deleted_at IS NULL
+WHERE l.user_id = sqlc.arg(user_id)
+  AND l.source_entry_id <> l.target_entry_id
+ORDER BY l.source_entry_id, l.target_entry_id;
+
+-- 26. The version of the user's link graph, bumped by triggers whenever what
+--     ListGraphNodes and ListGraphEdges return may have changed; 0 if it never has.
+-- name: GetLinkGraphVersion :one
+SELECT COALESCE((SELECT version FROM link_graph_versions WHERE user_id = sqlc.arg(user_id)), 0)::bigint AS version;
+
+-- 27. Count the distinct entries each of the given entries links to and is linked
+--     from, leaving out trashed ones as CountLinksBySource and CountLinksByTarget do.
+-- name: CountLinksByEntries :many
+SELECT e.id AS entry_id,
+       (SELECT COUNT(DISTINCT l.target_entry_id)
+        FROM entry_links AS l
+        WHERE l.source_entry_id = e.id
+          AND NOT EXISTS (SELECT 1 FROM entries AS t WHERE t.id = l.target_entry_id AND t.deleted_at IS NOT NULL))::bigint AS link_count,
+       (SELECT COUNT(DISTINCT l.source_entry_id)
+        FROM entry_links AS l
+        WHERE l.target_entry_id = e.id
+          AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = l.source_entry_id AND s.deleted_at IS NOT NULL))::bigint AS backlink_count
+FROM entries AS e
+WHERE e.id = ANY (sqlc.arg(entry_ids)::text[]);
@@ -0,0 +1,78 @@
+-- 1. Create the user's tags that do not exist yet.
+-- name: CreateTags :exec
+INSERT INTO tags (id, user_id, name, created_at)
+SELECT t.id, sqlc.arg(user_id), t.name, CURRENT_TIMESTAMP
+FROM unnest(sqlc.arg(ids)::text[], sqlc.arg(names)::text[]) AS t(id, name)
+ON CONFLICT (user_id, name) DO NOTHING;
+
+-- 2. Tag an entry with the user's tags of the given names.
+-- name: CreateEntryTags :exec
+INSERT INTO entry_tags (entry_id, tag_id)
+SELECT sqlc.arg(entry_id), id
+FROM tags
+WHERE user_id = sqlc.arg(user_id)
+  AND name = ANY (sqlc.arg(names)::text[])
+ON CONFLICT DO NOTHING;
+
+-- 3. Untag an entry from every tag not in keep_names.
+-- name: DeleteStaleEntryTags :execrows
+DELETE FROM entry_tags
+USING tags
+WHERE entry_tags.tag_id = tags.id
+  AND entry_tags.entry_id = sqlc.arg(entry_id)
+  AND NOT (tags.name = ANY (sqlc.arg(keep_names)::text[]));
+
+-- 4. Delete tags that neither tag an entry nor have a nested tag that does.
+--    An empty user_id cleans up after every user.
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags AS t
+WHERE (sqlc.arg(user_id)::text = '' OR t.user_id = sqlc.arg(user_id))
+  AND NOT EXISTS (SELECT 1
+                  FROM entry_tags AS et
+                           JOIN tags AS d ON d.id = et.tag_id
+                  WHERE d.user_id = t.user_id
+                    AND (d.name = t.name OR starts_with(d.name, t.name || '/')));
+
+-- 5. Get one of the user's tags by name.
+-- name: GetTagByName :one
+SELECT *
+FROM tags
+WHERE user_id = sqlc.arg(user_id)
+  AND name = sqlc.arg(name);
+
+-- 6. List the user's tags with how many live entries have each tag or a tag nested under it.
+-- name: ListTags :many
+SELECT t.*,
+       (SELECT COUNT(DISTINCT et.entry_id)
+        FROM entry_tags AS et
+                 JOIN tags AS d ON d.id = et.tag_id
+                 JOIN entries AS e ON e.id = et.entry_id AND e.deleted_at IS NULL
+        WHERE d.user_id = t.user_id
+          AND (d.name = t.name OR starts_with(d.name, t.name || '/'))) AS entry_count
+FROM tags AS t
+WHERE t.user_id = sqlc.arg(user_id)
+ORDER BY t.name;
+
+-- 7. List every live entry of the user with its tags, one row per tag.
+--    Untagged entries have a single row with a NULL name.
+-- name: ListEntryTagNames :many
+SELECT e.id AS entry_id, t.name
+FROM entries AS e
+         LEFT JOIN entry_tags AS et ON et.entry_id = e.id
+         LEFT JOIN tags AS t ON t.id = et.tag_id
+WHERE e.user_id = sqlc.arg(user_id)
+  AND e.deleted_at IS NULL;
+
+-- 8. List the user's live entries tagged with any of the given tags or a tag nested under one.
+-- name: ListEntriesByTags :many
+SELECT e.*
+FROM entries AS e
+WHERE e.user_id = sqlc.arg(user_id)
+  AND e.deleted_at IS NULL
+  AND EXISTS (SELECT 1
+              FROM entry_tags AS et
+                       JOIN tags AS t ON t.id = et.tag_id
+                       JOIN unnest(sqlc.arg(names)::text[]) AS n(name)
+                            ON t.name = n.name OR starts_with(t.name, n.name || '/')
+              WHERE et.entry_id = e.id)
+ORDER BY e.id;
@@ -0,0 +1,84 @@
+-- name: CreateUser :one
+INSERT INTO users (id,
+                   email,
+                   password_hash,
+                   display_name,
+                   garden_slug,
+                   created_at,
+                   updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT *
+FROM users
+WHERE id = $1;
+
+-- name: GetUserByEmail :one
+SELECT *
+FROM users
+WHERE email = $1;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET display_name = $2,
+    garden_slug  = $3,
+    updated_at   = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateSession :one
+INSERT INTO sessions (id,
+                      user_id,
+                      refresh_token_hash,
+                      created_at,
+                      expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSessionByID :one
+SELECT *
+FROM sessions
+WHERE id = $1;
+
+-- name: GetSessionByRefreshTokenHash :one
+SELECT *
+FROM sessions
+WHERE refresh_token_hash = $1;
+
+-- Swap in a new refresh token, invalidating the previous one. Returns no row
+-- unless old_hash is still the session's refresh token and the session is
+-- live, so of two requests presenting the same token only one rotates it.
+-- name: RotateSessionRefreshToken :one
+UPDATE sessions
+SET refresh_token_hash = sqlc.arg(new_hash),
+    expires_at         = sqlc.arg(expires_at)
+WHERE id = sqlc.arg(id)
+  AND refresh_token_hash = sqlc.arg(old_hash)
+  AND revoked_at IS NULL
+  AND expires_at > sqlc.arg(now)
+RETURNING *;
+
+-- name: RetireRefreshToken :exec
+INSERT INTO retired_refresh_tokens (token_hash,
+                                    session_id,
+                                    retired_at)
+VALUES ($1, $2, $3);
+
+-- name: GetSessionByRetiredRefreshTokenHash :one
+SELECT sessions.*
+FROM sessions
+         JOIN retired_refresh_tokens r ON r.session_id = sessions.id
+WHERE r.token_hash = $1;
+
+-- name: RevokeSession :exec
+UPDATE sessions
+SET revoked_at = $2
+WHERE id = $1
+  AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions
+SET revoked_at = $2
+WHERE user_id = $1
+  AND revoked_at IS NULL;
@@ -1,10 +1,90 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE entries
 (
     id           TEXT PRIMARY KEY,
     user_id      TEXT      NOT NULL,
     title        TEXT      NOT NULL,
     content      TEXT      NOT NULL,
+    growth_stage TEXT      NOT NULL CHECK (growth_stage IN ('seed', 'sprout', 'bloom', 'evergreen')),
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version      BIGINT    NOT NULL DEFAULT 1, -- incremented by every update
+    deleted_at   TIMESTAMP,                     -- set while the entry is in the trash
+    aliases      TEXT[]    NOT NULL DEFAULT '{}', -- other names for the entry; see entry_aliases
+    -- Full-text search document; title matches rank above content matches.
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('english', content), 'B')
+    ) STORED
+);
+
+CREATE INDEX entry_user_idx ON entries (user_id);
+
+CREATE INDEX entry_search_idx ON entries USING GIN (search_vector);
+
+-- Trigram index for typo-tolerant title lookup (SuggestEntries).
+CREATE INDEX entry_title_trgm_idx ON entries USING GIN (title gin_trgm_ops);
+
+-- Indexes entries.aliases: every alias is unique among the user's aliases, and
+-- wiki-links, title filters and suggestions match it like the entry's title.
+CREATE TABLE entry_aliases
+(
+    user_id   TEXT NOT NULL,
+    alias_key TEXT NOT NULL, -- lower(alias), used for matching
+    entry_id  TEXT NOT NULL,
+    alias     TEXT NOT NULL, -- as written in entries.aliases
+
+    PRIMARY KEY (user_id, alias_key),
+    FOREIGN KEY (entry_id) REFERENCES entries (id) ON DELETE CASCADE
+);
+
+CREATE INDEX entry_alias_entry_idx ON entry_aliases (entry_id);
+
+CREATE INDEX entry_alias_trgm_idx ON entry_aliases USING GIN (alias gin_trgm_ops);
+
+-- Remembers which entry a client-supplied Idempotency-Key created,
+-- so a retried CreateEntry returns the original entry.
+CREATE TABLE entry_idempotency_keys
+(
+    user_id         TEXT      NOT NULL,
+    idempotency_key TEXT      NOT NULL,
+    entry_id        TEXT      NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, idempotency_key),
+    FOREIGN KEY (entry_id) REFERENCES entries (id) ON DELETE CASCADE
+);
+
+-- A snapshot of an entry after each write. Revision 1 is the entry as created;
+-- every update, including a restore, appends the next revision.
+CREATE TABLE entry_revisions
+(
+    entry_id     TEXT      NOT NULL,
+    revision     INTEGER   NOT NULL,
+    title        TEXT      NOT NULL,
+    content      TEXT      NOT NULL,
     growth_stage TEXT      NOT NULL,
     created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-);
\ No newline at end of file
+
+    PRIMARY KEY (entry_id, revision),
+    FOREIGN KEY (entry_id) REFERENCES entries (id) ON DELETE CASCADE
+);
+
+-- Every change of an entry's growth stage: who made it, when and why. Stages
+-- may only change as the configured lifecycle allows (see models.Lifecycle).
+CREATE TABLE stage_transitions
+(
+    id         BIGSERIAL PRIMARY KEY,
+    entry_id   TEXT      NOT NULL,
+    user_id    TEXT      NOT NULL,    -- who changed the stage
+    token_id   TEXT,                  -- the personal access token they used, if any
+    from_stage TEXT      NOT NULL,
+    to_stage   TEXT      NOT NULL,
+    reason     TEXT      NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (entry_id) REFERENCES entries (id) ON DELETE CASCADE
+);
+
+CREATE INDEX stage_transition_entry_idx ON stage_transitions (entry_id, id);
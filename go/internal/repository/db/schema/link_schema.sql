@@ -3,8 +3,14 @@ CREATE TABLE entry_links (
      target_entry_id TEXT,
      user_id TEXT NOT NULL,
      created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+     -- 'manual' links are created through LinkService; 'content' links are
+     -- derived from [[wiki-links]] in the source entry's Markdown.
+     origin TEXT NOT NULL DEFAULT 'manual' CHECK (origin IN ('manual', 'content')),
+     -- What the link means, e.g. 'part-of'; the name of one of the user's
+     -- link_relations. Plain links, including all content links, have ''.
+     relation TEXT NOT NULL DEFAULT '',
 
-     PRIMARY KEY (source_entry_id, target_entry_id),
+     PRIMARY KEY (source_entry_id, target_entry_id, origin, relation),
      FOREIGN KEY (source_entry_id) REFERENCES entries(id),
      FOREIGN KEY (target_entry_id) REFERENCES entries(id)
 );
@@ -14,3 +20,73 @@ CREATE INDEX source_idx ON entry_links(source_entry_id);
 CREATE INDEX target_idx ON entry_links(target_entry_id);
 
 CREATE INDEX user_idx ON entry_links(user_id);
+
+CREATE INDEX relation_idx ON entry_links(user_id, relation);
+
+-- A user's vocabulary of link relations. A relation reads as name from the
+-- source entry and as inverse_name from the target, e.g. 'part-of' and
+-- 'has-part'. An empty inverse_name means the relation is symmetric.
+CREATE TABLE link_relations (
+     user_id TEXT NOT NULL,
+     name TEXT NOT NULL,
+     inverse_name TEXT NOT NULL DEFAULT '',
+     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+     PRIMARY KEY (user_id, name)
+);
+
+-- Wiki-links whose title does not match any of the user's entries yet.
+-- They become content links in entry_links once a matching entry is created.
+CREATE TABLE pending_links (
+     source_entry_id TEXT NOT NULL,
+     user_id TEXT NOT NULL,
+     target_title TEXT NOT NULL, -- as written in the source entry
+     target_key TEXT NOT NULL,   -- lower(target_title), used for matching
+     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+     PRIMARY KEY (source_entry_id, target_key),
+     FOREIGN KEY (source_entry_id) REFERENCES entries(id) ON DELETE CASCADE
+);
+
+CREATE INDEX pending_user_target_idx ON pending_links(user_id, target_key);
+
+-- A counter per user that the triggers below bump whenever the user's link
+-- graph may have changed: entries added, removed, trashed, restored, renamed
+-- or moved to another growth stage, or links added or removed. Cached graph
+-- insights are kept while it stays the same.
+CREATE TABLE link_graph_versions (
+     user_id TEXT PRIMARY KEY,
+     version BIGINT NOT NULL
+);
+
+CREATE FUNCTION bump_link_graph_version() RETURNS trigger AS $$
+DECLARE
+    changed_user_id TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_user_id := OLD.user_id;
+    ELSE
+        changed_user_id := NEW.user_id;
+    END IF;
+    INSERT INTO link_graph_versions (user_id, version)
+    VALUES (changed_user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET version = link_graph_versions.version + 1;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER entries_link_graph_insert_delete
+    AFTER INSERT OR DELETE ON entries
+    FOR EACH ROW EXECUTE FUNCTION bump_link_graph_version();
+
+CREATE TRIGGER entries_link_graph_update
+    AFTER UPDATE ON entries
+    FOR EACH ROW
+    WHEN (OLD.title IS DISTINCT FROM NEW.title
+        OR OLD.growth_stage IS DISTINCT FROM NEW.growth_stage
+        OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at)
+    EXECUTE FUNCTION bump_link_graph_version();
+
+CREATE TRIGGER entry_links_link_graph
+    AFTER INSERT OR UPDATE OR DELETE ON entry_links
+    FOR EACH ROW EXECUTE FUNCTION bump_link_graph_version();
@@ -0,0 +1,24 @@
+-- Tags are written in entries' Markdown as #tag. Nested tags use '/' (#lang/go),
+-- and every ancestor of a tag in use (lang) has a row as well.
+CREATE TABLE tags
+(
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT      NOT NULL,
+    name       TEXT      NOT NULL, -- lower case, without the leading '#'
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (user_id, name)
+);
+
+-- The tags written in each entry's content. Ancestors are implied, not stored.
+CREATE TABLE entry_tags
+(
+    entry_id TEXT NOT NULL,
+    tag_id   TEXT NOT NULL,
+
+    PRIMARY KEY (entry_id, tag_id),
+    FOREIGN KEY (entry_id) REFERENCES entries (id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE INDEX entry_tags_tag_idx ON entry_tags (tag_id);
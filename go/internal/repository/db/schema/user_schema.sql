@@ -0,0 +1,61 @@
+CREATE TABLE users
+(
+    id            TEXT PRIMARY KEY,
+    email         TEXT      NOT NULL UNIQUE,
+    password_hash TEXT      NOT NULL,
+    display_name  TEXT      NOT NULL,
+    garden_slug   TEXT      NOT NULL UNIQUE,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE sessions
+(
+    id                 TEXT PRIMARY KEY,
+    user_id            TEXT      NOT NULL,
+    refresh_token_hash TEXT      NOT NULL UNIQUE,
+    created_at         TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at         TIMESTAMP NOT NULL,
+    revoked_at         TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX session_user_idx ON sessions (user_id);
+
+-- Refresh tokens a session has rotated away from. Presenting one again means
+-- the token was copied, so the session it belonged to is revoked.
+CREATE TABLE retired_refresh_tokens
+(
+    token_hash TEXT PRIMARY KEY,
+    session_id TEXT      NOT NULL,
+    retired_at TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+-- entries, entry_links, pending_links, link_graph_versions and tags are created
+-- by earlier schema files.
+ALTER TABLE entries
+    ADD CONSTRAINT entries_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE entry_links
+    ADD CONSTRAINT entry_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE pending_links
+    ADD CONSTRAINT pending_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE link_relations
+    ADD CONSTRAINT link_relations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE link_graph_versions
+    ADD CONSTRAINT link_graph_versions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE entry_aliases
+    ADD CONSTRAINT entry_aliases_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE tags
+    ADD CONSTRAINT tags_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE stage_transitions
+    ADD CONSTRAINT stage_transitions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id);
@@ -0,0 +1,16 @@
+CREATE TABLE personal_access_tokens
+(
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT      NOT NULL,
+    name         TEXT      NOT NULL,
+    token_hash   TEXT      NOT NULL UNIQUE,
+    scopes       TEXT[]    NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at   TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at   TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX token_user_idx ON personal_access_tokens (user_id);
@@ -7,9 +7,48 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :one
+INSERT INTO entry_idempotency_keys (user_id,
+                                    idempotency_key,
+                                    entry_id,
+                                    created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+    SET entry_id   = EXCLUDED.entry_id,
+        created_at = EXCLUDED.created_at
+WHERE entry_idempotency_keys.created_at < $5
+RETURNING entry_id
+`
+
+type ClaimIdempotencyKeyParams struct {
+	UserID         string    `json:"user_id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	EntryID        string    `json:"entry_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiredBefore  time.Time `json:"expired_before"`
+}
+
+// Records that an idempotency key produced entry_id. Returns no row when the key
+// is already held by an unexpired claim.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, claimIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.EntryID,
+		arg.CreatedAt,
+		arg.ExpiredBefore,
+	)
+	var entry_id string
+	err := row.Scan(&entry_id)
+	return entry_id, err
+}
+
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (id,
                      user_id,
@@ -17,9 +56,11 @@ INSERT INTO entries (id,
                      content,
                      growth_stage,
                      created_at,
-                     updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, user_id, title, content, growth_stage, created_at, updated_at
+                     updated_at,
+                     aliases)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (id) DO NOTHING
+RETURNING id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
 `
 
 type CreateEntryParams struct {
@@ -30,6 +71,7 @@ type CreateEntryParams struct {
 	GrowthStage string    `json:"growth_stage"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Aliases     []string  `json:"aliases"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
@@ -41,6 +83,7 @@ func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry
 		arg.GrowthStage,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		pq.Array(arg.Aliases),
 	)
 	var i Entry
 	err := row.Scan(
@@ -51,6 +94,101 @@ func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry
 		&i.GrowthStage,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		pq.Array(&i.Aliases),
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const createEntryAliases = `-- name: CreateEntryAliases :exec
+INSERT INTO entry_aliases (user_id, alias_key, entry_id, alias)
+SELECT $1, a.alias_key, $2, a.alias
+FROM unnest($3::text[], $4::text[]) AS a(alias, alias_key)
+`
+
+type CreateEntryAliasesParams struct {
+	UserID    string   `json:"user_id"`
+	EntryID   string   `json:"entry_id"`
+	Aliases   []string `json:"aliases"`
+	AliasKeys []string `json:"alias_keys"`
+}
+
+func (q *Queries) CreateEntryAliases(ctx context.Context, arg CreateEntryAliasesParams) error {
+	_, err := q.db.ExecContext(ctx, createEntryAliases,
+		arg.UserID,
+		arg.EntryID,
+		pq.Array(arg.Aliases),
+		pq.Array(arg.AliasKeys),
+	)
+	return err
+}
+
+const createEntryRevision = `-- name: CreateEntryRevision :one
+INSERT INTO entry_revisions (entry_id, revision, title, content, growth_stage, created_at)
+SELECT id,
+       COALESCE((SELECT MAX(revision) FROM entry_revisions WHERE entry_id = $1), 0) + 1,
+       title,
+       content,
+       growth_stage,
+       updated_at
+FROM entries
+WHERE id = $1
+RETURNING entry_id, revision, title, content, growth_stage, created_at
+`
+
+// Snapshots the entry's current state as its next revision.
+func (q *Queries) CreateEntryRevision(ctx context.Context, id string) (EntryRevision, error) {
+	row := q.db.QueryRowContext(ctx, createEntryRevision, id)
+	var i EntryRevision
+	err := row.Scan(
+		&i.EntryID,
+		&i.Revision,
+		&i.Title,
+		&i.Content,
+		&i.GrowthStage,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createStageTransition = `-- name: CreateStageTransition :one
+INSERT INTO stage_transitions (entry_id, user_id, token_id, from_stage, to_stage, reason, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *
+`
+
+type CreateStageTransitionParams struct {
+	EntryID   string         `json:"entry_id"`
+	UserID    string         `json:"user_id"`
+	TokenID   sql.NullString `json:"token_id"`
+	FromStage string         `json:"from_stage"`
+	ToStage   string         `json:"to_stage"`
+	Reason    string         `json:"reason"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (q *Queries) CreateStageTransition(ctx context.Context, arg CreateStageTransitionParams) (StageTransition, error) {
+	row := q.db.QueryRowContext(ctx, createStageTransition,
+		arg.EntryID,
+		arg.UserID,
+		arg.TokenID,
+		arg.FromStage,
+		arg.ToStage,
+		arg.Reason,
+		arg.CreatedAt,
+	)
+	var i StageTransition
+	err := row.Scan(
+		&i.ID,
+		&i.EntryID,
+		&i.UserID,
+		&i.TokenID,
+		&i.FromStage,
+		&i.ToStage,
+		&i.Reason,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -66,8 +204,67 @@ func (q *Queries) DeleteEntry(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteEntryAliases = `-- name: DeleteEntryAliases :exec
+DELETE
+FROM entry_aliases
+WHERE entry_id = $1
+`
+
+func (q *Queries) DeleteEntryAliases(ctx context.Context, entryID string) error {
+	_, err := q.db.ExecContext(ctx, deleteEntryAliases, entryID)
+	return err
+}
+
+const findTakenName = `-- name: FindTakenName :one
+SELECT names.name_key::text AS name_key, names.entry_id
+FROM (SELECT a.alias_key AS name_key, a.entry_id
+      FROM entry_aliases AS a
+               JOIN entries AS e ON e.id = a.entry_id AND e.deleted_at IS NULL
+      WHERE a.user_id = $1
+        AND a.entry_id <> $2
+        AND a.alias_key = ANY ($3::text[])
+      UNION ALL
+      SELECT lower(title), id
+      FROM entries
+      WHERE $4::bool
+        AND user_id = $1
+        AND id <> $2
+        AND deleted_at IS NULL
+        AND lower(title) = ANY ($3::text[])) AS names
+LIMIT 1
+`
+
+type FindTakenNameParams struct {
+	UserID      string   `json:"user_id"`
+	EntryID     string   `json:"entry_id"`
+	NameKeys    []string `json:"name_keys"`
+	CheckTitles bool     `json:"check_titles"`
+}
+
+type FindTakenNameRow struct {
+	NameKey string `json:"name_key"`
+	EntryID string `json:"entry_id"`
+}
+
+// Returns one of name_keys that is already an alias of another of the user's
+// entries or, with check_titles, the lowercased title of another live entry.
+func (q *Queries) FindTakenName(ctx context.Context, arg FindTakenNameParams) (FindTakenNameRow, error) {
+	row := q.db.QueryRowContext(ctx, findTakenName,
+		arg.UserID,
+		arg.EntryID,
+		pq.Array(arg.NameKeys),
+		arg.CheckTitles,
+	)
+	var i FindTakenNameRow
+	err := row.Scan(
+		&i.NameKey,
+		&i.EntryID,
+	)
+	return i, err
+}
+
 const getEntryByID = `-- name: GetEntryByID :one
-SELECT id, user_id, title, content, growth_stage, created_at, updated_at
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
 FROM entries
 WHERE id = $1
 `
@@ -83,14 +280,64 @@ func (q *Queries) GetEntryByID(ctx context.Context, id string) (Entry, error) {
 		&i.GrowthStage,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		pq.Array(&i.Aliases),
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const getEntryRevision = `-- name: GetEntryRevision :one
+SELECT entry_id, revision, title, content, growth_stage, created_at
+FROM entry_revisions
+WHERE entry_id = $1
+  AND revision = $2
+`
+
+type GetEntryRevisionParams struct {
+	EntryID  string `json:"entry_id"`
+	Revision int32  `json:"revision"`
+}
+
+func (q *Queries) GetEntryRevision(ctx context.Context, arg GetEntryRevisionParams) (EntryRevision, error) {
+	row := q.db.QueryRowContext(ctx, getEntryRevision, arg.EntryID, arg.Revision)
+	var i EntryRevision
+	err := row.Scan(
+		&i.EntryID,
+		&i.Revision,
+		&i.Title,
+		&i.Content,
+		&i.GrowthStage,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const getIdempotencyKeyEntryID = `-- name: GetIdempotencyKeyEntryID :one
+SELECT entry_id
+FROM entry_idempotency_keys
+WHERE user_id = $1
+  AND idempotency_key = $2
+`
+
+type GetIdempotencyKeyEntryIDParams struct {
+	UserID         string `json:"user_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) GetIdempotencyKeyEntryID(ctx context.Context, arg GetIdempotencyKeyEntryIDParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKeyEntryID, arg.UserID, arg.IdempotencyKey)
+	var entry_id string
+	err := row.Scan(&entry_id)
+	return entry_id, err
+}
+
 const listEntriesByUser = `-- name: ListEntriesByUser :many
-SELECT id, user_id, title, content, growth_stage, created_at, updated_at
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
 FROM entries
 WHERE user_id = $1
+  AND deleted_at IS NULL
 ORDER BY created_at
 `
 
@@ -111,6 +358,10 @@ func (q *Queries) ListEntriesByUser(ctx context.Context, userID string) ([]Entry
 			&i.GrowthStage,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
 		); err != nil {
 			return nil, err
 		}
@@ -126,9 +377,10 @@ func (q *Queries) ListEntriesByUser(ctx context.Context, userID string) ([]Entry
 }
 
 const listEntriesByUserSince = `-- name: ListEntriesByUserSince :many
-SELECT id, user_id, title, content, growth_stage, created_at, updated_at
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
 FROM entries
 WHERE user_id = $1
+  AND deleted_at IS NULL
   AND updated_at > $2
 ORDER BY updated_at
 `
@@ -155,6 +407,10 @@ func (q *Queries) ListEntriesByUserSince(ctx context.Context, arg ListEntriesByU
 			&i.GrowthStage,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
 		); err != nil {
 			return nil, err
 		}
@@ -169,31 +425,1003 @@ func (q *Queries) ListEntriesByUserSince(ctx context.Context, arg ListEntriesByU
 	return items, nil
 }
 
-const updateEntry = `-- name: UpdateEntry :one
-UPDATE entries
-SET title        = $2,
-    content      = $3,
-    growth_stage = $4,
-    updated_at   = $5
-WHERE id = $1
-RETURNING id, user_id, title, content, growth_stage, created_at, updated_at
+const listEntriesPageByCreated = `-- name: ListEntriesPageByCreated :many
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+FROM entries
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND ($2::text IS NULL OR growth_stage = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+  AND ($5::timestamp IS NULL OR updated_at >= $5)
+  AND ($6::timestamp IS NULL OR updated_at < $6)
+  AND (starts_with(lower(title), lower($7::text))
+    OR EXISTS (SELECT 1
+               FROM entry_aliases AS a
+               WHERE a.entry_id = entries.id
+                 AND starts_with(a.alias_key, lower($7::text))))
+  AND (NOT $8::bool OR id = ANY ($9::text[]))
+  AND ($10::timestamp IS NULL
+    OR ($11::bool AND (created_at, id) < ($10, $12::text))
+    OR (NOT $11::bool AND (created_at, id) > ($10, $12::text)))
+ORDER BY CASE WHEN $11::bool THEN created_at END DESC,
+         CASE WHEN $11::bool THEN id END DESC,
+         created_at,
+         id
+LIMIT $13
 `
 
-type UpdateEntryParams struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Content     string    `json:"content"`
-	GrowthStage string    `json:"growth_stage"`
-	UpdatedAt   time.Time `json:"updated_at"`
+type ListEntriesPageByCreatedParams struct {
+	UserID          string         `json:"user_id"`
+	GrowthStage     sql.NullString `json:"growth_stage"`
+	CreatedAfter    sql.NullTime   `json:"created_after"`
+	CreatedBefore   sql.NullTime   `json:"created_before"`
+	UpdatedAfter    sql.NullTime   `json:"updated_after"`
+	UpdatedBefore   sql.NullTime   `json:"updated_before"`
+	TitlePrefix     string         `json:"title_prefix"`
+	FilterByIds     bool           `json:"filter_by_ids"`
+	EntryIds        []string       `json:"entry_ids"`
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	Descending      bool           `json:"descending"`
+	CursorID        string         `json:"cursor_id"`
+	PageLimit       int32          `json:"page_limit"`
 }
 
-func (q *Queries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, updateEntry,
-		arg.ID,
-		arg.Title,
-		arg.Content,
+func (q *Queries) ListEntriesPageByCreated(ctx context.Context, arg ListEntriesPageByCreatedParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesPageByCreated,
+		arg.UserID,
 		arg.GrowthStage,
-		arg.UpdatedAt,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.TitlePrefix,
+		arg.FilterByIds,
+		pq.Array(arg.EntryIds),
+		arg.CursorCreatedAt,
+		arg.Descending,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesPageByLinkCount = `-- name: ListEntriesPageByLinkCount :many
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector, link_count
+FROM (SELECT e.id, e.user_id, e.title, e.content, e.growth_stage, e.created_at, e.updated_at, e.version, e.deleted_at, e.aliases, e.search_vector,
+             (SELECT COUNT(DISTINCT l.target_entry_id)
+              FROM entry_links AS l
+                       JOIN entries AS t ON t.id = l.target_entry_id AND t.deleted_at IS NULL
+              WHERE l.source_entry_id = e.id) AS link_count
+      FROM entries AS e
+      WHERE e.user_id = $1
+        AND e.deleted_at IS NULL
+        AND ($2::text IS NULL OR e.growth_stage = $2)
+        AND ($3::timestamp IS NULL OR e.created_at >= $3)
+        AND ($4::timestamp IS NULL OR e.created_at < $4)
+        AND ($5::timestamp IS NULL OR e.updated_at >= $5)
+        AND ($6::timestamp IS NULL OR e.updated_at < $6)
+        AND (starts_with(lower(e.title), lower($7::text))
+          OR EXISTS (SELECT 1
+                     FROM entry_aliases AS a
+                     WHERE a.entry_id = e.id
+                       AND starts_with(a.alias_key, lower($7::text))))
+        AND (NOT $8::bool OR e.id = ANY ($9::text[]))) AS counted
+WHERE ($10::bigint IS NULL
+    OR ($11::bool AND (link_count, id) < ($10, $12::text))
+    OR (NOT $11::bool AND (link_count, id) > ($10, $12::text)))
+ORDER BY CASE WHEN $11::bool THEN link_count END DESC,
+         CASE WHEN $11::bool THEN id END DESC,
+         link_count,
+         id
+LIMIT $13
+`
+
+type ListEntriesPageByLinkCountParams struct {
+	UserID          string         `json:"user_id"`
+	GrowthStage     sql.NullString `json:"growth_stage"`
+	CreatedAfter    sql.NullTime   `json:"created_after"`
+	CreatedBefore   sql.NullTime   `json:"created_before"`
+	UpdatedAfter    sql.NullTime   `json:"updated_after"`
+	UpdatedBefore   sql.NullTime   `json:"updated_before"`
+	TitlePrefix     string         `json:"title_prefix"`
+	FilterByIds     bool           `json:"filter_by_ids"`
+	EntryIds        []string       `json:"entry_ids"`
+	CursorLinkCount sql.NullInt64  `json:"cursor_link_count"`
+	Descending      bool           `json:"descending"`
+	CursorID        string         `json:"cursor_id"`
+	PageLimit       int32          `json:"page_limit"`
+}
+
+type ListEntriesPageByLinkCountRow struct {
+	ID           string       `json:"id"`
+	UserID       string       `json:"user_id"`
+	Title        string       `json:"title"`
+	Content      string       `json:"content"`
+	GrowthStage  string       `json:"growth_stage"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Version      int64        `json:"version"`
+	DeletedAt    sql.NullTime `json:"deleted_at"`
+	Aliases      []string     `json:"aliases"`
+	SearchVector interface{}  `json:"search_vector"`
+	LinkCount    int64        `json:"link_count"`
+}
+
+func (q *Queries) ListEntriesPageByLinkCount(ctx context.Context, arg ListEntriesPageByLinkCountParams) ([]ListEntriesPageByLinkCountRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesPageByLinkCount,
+		arg.UserID,
+		arg.GrowthStage,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.TitlePrefix,
+		arg.FilterByIds,
+		pq.Array(arg.EntryIds),
+		arg.CursorLinkCount,
+		arg.Descending,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEntriesPageByLinkCountRow
+	for rows.Next() {
+		var i ListEntriesPageByLinkCountRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+			&i.LinkCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesPageByTitle = `-- name: ListEntriesPageByTitle :many
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+FROM entries
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND ($2::text IS NULL OR growth_stage = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+  AND ($5::timestamp IS NULL OR updated_at >= $5)
+  AND ($6::timestamp IS NULL OR updated_at < $6)
+  AND (starts_with(lower(title), lower($7::text))
+    OR EXISTS (SELECT 1
+               FROM entry_aliases AS a
+               WHERE a.entry_id = entries.id
+                 AND starts_with(a.alias_key, lower($7::text))))
+  AND (NOT $8::bool OR id = ANY ($9::text[]))
+  AND ($10::text IS NULL
+    OR ($11::bool AND (title, id) < ($10, $12::text))
+    OR (NOT $11::bool AND (title, id) > ($10, $12::text)))
+ORDER BY CASE WHEN $11::bool THEN title END DESC,
+         CASE WHEN $11::bool THEN id END DESC,
+         title,
+         id
+LIMIT $13
+`
+
+type ListEntriesPageByTitleParams struct {
+	UserID        string         `json:"user_id"`
+	GrowthStage   sql.NullString `json:"growth_stage"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	UpdatedAfter  sql.NullTime   `json:"updated_after"`
+	UpdatedBefore sql.NullTime   `json:"updated_before"`
+	TitlePrefix   string         `json:"title_prefix"`
+	FilterByIds   bool           `json:"filter_by_ids"`
+	EntryIds      []string       `json:"entry_ids"`
+	CursorTitle   sql.NullString `json:"cursor_title"`
+	Descending    bool           `json:"descending"`
+	CursorID      string         `json:"cursor_id"`
+	PageLimit     int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListEntriesPageByTitle(ctx context.Context, arg ListEntriesPageByTitleParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesPageByTitle,
+		arg.UserID,
+		arg.GrowthStage,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.TitlePrefix,
+		arg.FilterByIds,
+		pq.Array(arg.EntryIds),
+		arg.CursorTitle,
+		arg.Descending,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesPageByUpdated = `-- name: ListEntriesPageByUpdated :many
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+FROM entries
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND ($2::text IS NULL OR growth_stage = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+  AND ($5::timestamp IS NULL OR updated_at >= $5)
+  AND ($6::timestamp IS NULL OR updated_at < $6)
+  AND (starts_with(lower(title), lower($7::text))
+    OR EXISTS (SELECT 1
+               FROM entry_aliases AS a
+               WHERE a.entry_id = entries.id
+                 AND starts_with(a.alias_key, lower($7::text))))
+  AND (NOT $8::bool OR id = ANY ($9::text[]))
+  AND ($10::timestamp IS NULL
+    OR ($11::bool AND (updated_at, id) < ($10, $12::text))
+    OR (NOT $11::bool AND (updated_at, id) > ($10, $12::text)))
+ORDER BY CASE WHEN $11::bool THEN updated_at END DESC,
+         CASE WHEN $11::bool THEN id END DESC,
+         updated_at,
+         id
+LIMIT $13
+`
+
+type ListEntriesPageByUpdatedParams struct {
+	UserID          string         `json:"user_id"`
+	GrowthStage     sql.NullString `json:"growth_stage"`
+	CreatedAfter    sql.NullTime   `json:"created_after"`
+	CreatedBefore   sql.NullTime   `json:"created_before"`
+	UpdatedAfter    sql.NullTime   `json:"updated_after"`
+	UpdatedBefore   sql.NullTime   `json:"updated_before"`
+	TitlePrefix     string         `json:"title_prefix"`
+	FilterByIds     bool           `json:"filter_by_ids"`
+	EntryIds        []string       `json:"entry_ids"`
+	CursorUpdatedAt sql.NullTime   `json:"cursor_updated_at"`
+	Descending      bool           `json:"descending"`
+	CursorID        string         `json:"cursor_id"`
+	PageLimit       int32          `json:"page_limit"`
+}
+
+func (q *Queries) ListEntriesPageByUpdated(ctx context.Context, arg ListEntriesPageByUpdatedParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesPageByUpdated,
+		arg.UserID,
+		arg.GrowthStage,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.TitlePrefix,
+		arg.FilterByIds,
+		pq.Array(arg.EntryIds),
+		arg.CursorUpdatedAt,
+		arg.Descending,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesTrashedBefore = `-- name: ListEntriesTrashedBefore :many
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+FROM entries
+WHERE deleted_at < $1
+ORDER BY deleted_at
+`
+
+// Entries of any user trashed before the cutoff, for the purger.
+func (q *Queries) ListEntriesTrashedBefore(ctx context.Context, trashedBefore time.Time) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesTrashedBefore, trashedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntryRevisions = `-- name: ListEntryRevisions :many
+SELECT entry_id, revision, title, content, growth_stage, created_at
+FROM entry_revisions
+WHERE entry_id = $1
+ORDER BY revision DESC
+`
+
+func (q *Queries) ListEntryRevisions(ctx context.Context, entryID string) ([]EntryRevision, error) {
+	rows, err := q.db.QueryContext(ctx, listEntryRevisions, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EntryRevision
+	for rows.Next() {
+		var i EntryRevision
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.Revision,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnedEntryIDs = `-- name: ListOwnedEntryIDs :many
+SELECT id
+FROM entries
+WHERE user_id = $1
+  AND deleted_at IS NULL
+  AND id = ANY ($2::text[])
+`
+
+type ListOwnedEntryIDsParams struct {
+	UserID string   `json:"user_id"`
+	Ids    []string `json:"ids"`
+}
+
+// Returns the subset of the given entry IDs that belong to the user.
+func (q *Queries) ListOwnedEntryIDs(ctx context.Context, arg ListOwnedEntryIDsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnedEntryIDs, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStageTransitions = `-- name: ListStageTransitions :many
+SELECT *
+FROM stage_transitions
+WHERE entry_id = $1
+ORDER BY id DESC
+`
+
+func (q *Queries) ListStageTransitions(ctx context.Context, entryID string) ([]StageTransition, error) {
+	rows, err := q.db.QueryContext(ctx, listStageTransitions, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StageTransition
+	for rows.Next() {
+		var i StageTransition
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntryID,
+			&i.UserID,
+			&i.TokenID,
+			&i.FromStage,
+			&i.ToStage,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedEntries = `-- name: ListTrashedEntries :many
+SELECT id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+FROM entries
+WHERE user_id = $1
+  AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListTrashedEntries(ctx context.Context, userID string) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedEntries, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveEntryTitles = `-- name: ResolveEntryTitles :many
+SELECT DISTINCT ON (names.title_key) names.id, names.title_key::text AS title_key
+FROM (SELECT id, lower(title) AS title_key, FALSE AS is_alias, created_at
+      FROM entries
+      WHERE user_id = $1
+        AND deleted_at IS NULL
+        AND lower(title) = ANY ($2::text[])
+      UNION ALL
+      SELECT e.id, a.alias_key, TRUE, e.created_at
+      FROM entry_aliases AS a
+               JOIN entries AS e ON e.id = a.entry_id AND e.deleted_at IS NULL
+      WHERE a.user_id = $1
+        AND a.alias_key = ANY ($2::text[])) AS names
+ORDER BY names.title_key, names.is_alias, names.created_at, names.id
+`
+
+type ResolveEntryTitlesParams struct {
+	UserID    string   `json:"user_id"`
+	TitleKeys []string `json:"title_keys"`
+}
+
+type ResolveEntryTitlesRow struct {
+	ID       string `json:"id"`
+	TitleKey string `json:"title_key"`
+}
+
+// Finds the user's entries whose titles or aliases match any of the given
+// lowercased titles. A title wins over an alias; when several entries share
+// a title, the oldest one wins.
+func (q *Queries) ResolveEntryTitles(ctx context.Context, arg ResolveEntryTitlesParams) ([]ResolveEntryTitlesRow, error) {
+	rows, err := q.db.QueryContext(ctx, resolveEntryTitles, arg.UserID, pq.Array(arg.TitleKeys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ResolveEntryTitlesRow
+	for rows.Next() {
+		var i ResolveEntryTitlesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TitleKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreEntry = `-- name: RestoreEntry :one
+UPDATE entries
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+`
+
+func (q *Queries) RestoreEntry(ctx context.Context, id string) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, restoreEntry, id)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Content,
+		&i.GrowthStage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		pq.Array(&i.Aliases),
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const searchEntries = `-- name: SearchEntries :many
+WITH search AS (SELECT to_tsquery('english', $1::text) AS query)
+SELECT page.id,
+       page.user_id,
+       page.title,
+       page.content,
+       page.growth_stage,
+       page.created_at,
+       page.updated_at,
+       page.version,
+       page.deleted_at,
+       page.aliases,
+       page.rank,
+       ts_headline('english', page.title, search.query,
+                   'HighlightAll=true, ' || $2::text) AS title_highlight,
+       ts_headline('english', page.content, search.query,
+                   'MaxFragments=2, MinWords=8, MaxWords=24, ' || $2::text) AS snippet
+FROM (SELECT *
+      FROM (SELECT e.id, e.user_id, e.title, e.content, e.growth_stage, e.created_at, e.updated_at, e.version, e.deleted_at, e.aliases,
+                   ts_rank_cd(e.search_vector, search.query) AS rank
+            FROM entries AS e, search
+            WHERE e.user_id = $3
+              AND e.deleted_at IS NULL
+              AND e.search_vector @@ search.query
+              AND ($4::text IS NULL OR e.growth_stage = $4)
+              AND (NOT $5::bool OR e.id = ANY ($6::text[]))) AS ranked
+      WHERE ($7::real IS NULL
+          OR rank < $7
+          OR (rank = $7 AND id > $8::text))
+      ORDER BY rank DESC, id
+      LIMIT $9) AS page, search
+ORDER BY page.rank DESC, page.id
+`
+
+type SearchEntriesParams struct {
+	Query           string          `json:"query"`
+	HeadlineOptions string          `json:"headline_options"`
+	UserID          string          `json:"user_id"`
+	GrowthStage     sql.NullString  `json:"growth_stage"`
+	FilterByIds     bool            `json:"filter_by_ids"`
+	EntryIds        []string        `json:"entry_ids"`
+	CursorRank      sql.NullFloat64 `json:"cursor_rank"`
+	CursorID        string          `json:"cursor_id"`
+	PageLimit       int32           `json:"page_limit"`
+}
+
+type SearchEntriesRow struct {
+	ID             string       `json:"id"`
+	UserID         string       `json:"user_id"`
+	Title          string       `json:"title"`
+	Content        string       `json:"content"`
+	GrowthStage    string       `json:"growth_stage"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Version        int64        `json:"version"`
+	DeletedAt      sql.NullTime `json:"deleted_at"`
+	Aliases        []string     `json:"aliases"`
+	Rank           float32      `json:"rank"`
+	TitleHighlight string       `json:"title_highlight"`
+	Snippet        string       `json:"snippet"`
+}
+
+// Full-text search over the user's live entries, best match first. query is in
+// to_tsquery syntax. Highlights are computed only for the returned page.
+func (q *Queries) SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]SearchEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntries,
+		arg.Query,
+		arg.HeadlineOptions,
+		arg.UserID,
+		arg.GrowthStage,
+		arg.FilterByIds,
+		pq.Array(arg.EntryIds),
+		arg.CursorRank,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchEntriesRow
+	for rows.Next() {
+		var i SearchEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.Rank,
+			&i.TitleHighlight,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const suggestEntries = `-- name: SuggestEntries :many
+SELECT c.id,
+       c.user_id,
+       c.title,
+       c.content,
+       c.growth_stage,
+       c.created_at,
+       c.updated_at,
+       c.version,
+       c.deleted_at,
+       c.aliases,
+       c.similarity,
+       c.link_count,
+       (0.7 * c.similarity
+           + 0.15 / (1 + GREATEST(EXTRACT(EPOCH FROM $1::timestamp - c.updated_at), 0) / 2592000)
+           + 0.15 * c.link_count / (c.link_count + 5))::real AS score
+FROM (SELECT m.*,
+             (SELECT COUNT(DISTINCT l.source_entry_id)
+              FROM entry_links AS l
+              WHERE l.target_entry_id = m.id
+                AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = l.source_entry_id AND s.deleted_at IS NOT NULL)) AS link_count
+      FROM (SELECT e.id, e.user_id, e.title, e.content, e.growth_stage, e.created_at, e.updated_at, e.version, e.deleted_at, e.aliases,
+                   best.similarity
+            FROM (SELECT names.entry_id, MAX(word_similarity($2::text, names.name)) AS similarity
+                  FROM (SELECT id AS entry_id, title AS name
+                        FROM entries
+                        WHERE user_id = $3
+                          AND deleted_at IS NULL
+                          AND $2::text <% title
+                        UNION ALL
+                        SELECT a.entry_id, a.alias
+                        FROM entry_aliases AS a
+                                 JOIN entries AS ae ON ae.id = a.entry_id AND ae.deleted_at IS NULL
+                        WHERE a.user_id = $3
+                          AND $2::text <% a.alias) AS names
+                  GROUP BY names.entry_id
+                  ORDER BY similarity DESC, names.entry_id
+                  LIMIT $4) AS best
+                     JOIN entries AS e ON e.id = best.entry_id) AS m) AS c
+ORDER BY score DESC, c.id
+LIMIT $5
+`
+
+type SuggestEntriesParams struct {
+	Now           time.Time `json:"now"`
+	Query         string    `json:"query"`
+	UserID        string    `json:"user_id"`
+	MaxCandidates int32     `json:"max_candidates"`
+	MaxResults    int32     `json:"max_results"`
+}
+
+type SuggestEntriesRow struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"user_id"`
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	GrowthStage string       `json:"growth_stage"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Version     int64        `json:"version"`
+	DeletedAt   sql.NullTime `json:"deleted_at"`
+	Aliases     []string     `json:"aliases"`
+	Similarity  float32      `json:"similarity"`
+	LinkCount   int64        `json:"link_count"`
+	Score       float32      `json:"score"`
+}
+
+// Typo-tolerant title and alias lookup. Up to max_candidates of the closest matches
+// are re-ranked by a score that also rewards recent edits (halving after 30
+// days) and inbound links, counted as in CountLinksByTarget.
+func (q *Queries) SuggestEntries(ctx context.Context, arg SuggestEntriesParams) ([]SuggestEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, suggestEntries,
+		arg.Now,
+		arg.Query,
+		arg.UserID,
+		arg.MaxCandidates,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SuggestEntriesRow
+	for rows.Next() {
+		var i SuggestEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.Similarity,
+			&i.LinkCount,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const trashEntry = `-- name: TrashEntry :execrows
+UPDATE entries
+SET deleted_at = $2
+WHERE id = $1
+  AND deleted_at IS NULL
+`
+
+type TrashEntryParams struct {
+	ID        string       `json:"id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) TrashEntry(ctx context.Context, arg TrashEntryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, trashEntry, arg.ID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateEntry = `-- name: UpdateEntry :one
+UPDATE entries
+SET title        = $2,
+    content      = $3,
+    growth_stage = $4,
+    updated_at   = $5,
+    aliases      = $6,
+    version      = version + 1
+WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($7::bigint IS NULL OR version = $7)
+RETURNING id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+`
+
+type UpdateEntryParams struct {
+	ID              string        `json:"id"`
+	Title           string        `json:"title"`
+	Content         string        `json:"content"`
+	GrowthStage     string        `json:"growth_stage"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	Aliases         []string      `json:"aliases"`
+	ExpectedVersion sql.NullInt64 `json:"expected_version"`
+}
+
+// Returns no row if expected_version is set and no longer matches.
+func (q *Queries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, updateEntry,
+		arg.ID,
+		arg.Title,
+		arg.Content,
+		arg.GrowthStage,
+		arg.UpdatedAt,
+		pq.Array(arg.Aliases),
+		arg.ExpectedVersion,
+	)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Content,
+		&i.GrowthStage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		pq.Array(&i.Aliases),
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const updateEntryFields = `-- name: UpdateEntryFields :one
+UPDATE entries
+SET title        = COALESCE($1, title),
+    content      = COALESCE($2, content),
+    growth_stage = COALESCE($3, growth_stage),
+    aliases      = COALESCE($4::text[], aliases),
+    updated_at   = $5,
+    version      = version + 1
+WHERE id = $6
+  AND deleted_at IS NULL
+  AND ($7::bigint IS NULL OR version = $7)
+RETURNING id, user_id, title, content, growth_stage, created_at, updated_at, version, deleted_at, aliases, search_vector
+`
+
+type UpdateEntryFieldsParams struct {
+	Title           sql.NullString `json:"title"`
+	Content         sql.NullString `json:"content"`
+	GrowthStage     sql.NullString `json:"growth_stage"`
+	Aliases         []string       `json:"aliases"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	ID              string         `json:"id"`
+	ExpectedVersion sql.NullInt64  `json:"expected_version"`
+}
+
+// Updates only the non-null fields, so unchanged content is not sent or rewritten.
+// Returns no row if expected_version is set and no longer matches.
+func (q *Queries) UpdateEntryFields(ctx context.Context, arg UpdateEntryFieldsParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, updateEntryFields,
+		arg.Title,
+		arg.Content,
+		arg.GrowthStage,
+		pq.Array(arg.Aliases),
+		arg.UpdatedAt,
+		arg.ID,
+		arg.ExpectedVersion,
 	)
 	var i Entry
 	err := row.Scan(
@@ -204,6 +1432,10 @@ func (q *Queries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry
 		&i.GrowthStage,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		pq.Array(&i.Aliases),
+		&i.SearchVector,
 	)
 	return i, err
 }
@@ -7,92 +7,555 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const countLinksByEntries = `-- name: CountLinksByEntries :many
+SELECT e.id AS entry_id,
+       (SELECT COUNT(DISTINCT l.target_entry_id)
+        FROM entry_links AS l
+        WHERE l.source_entry_id = e.id
+          AND NOT EXISTS (SELECT 1 FROM entries AS t WHERE t.id = l.target_entry_id AND t.deleted_at IS NOT NULL))::bigint AS link_count,
+       (SELECT COUNT(DISTINCT l.source_entry_id)
+        FROM entry_links AS l
+        WHERE l.target_entry_id = e.id
+          AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = l.source_entry_id AND s.deleted_at IS NOT NULL))::bigint AS backlink_count
+FROM entries AS e
+WHERE e.id = ANY ($1::text[])
+`
+
+type CountLinksByEntriesRow struct {
+	EntryID       string `json:"entry_id"`
+	LinkCount     int64  `json:"link_count"`
+	BacklinkCount int64  `json:"backlink_count"`
+}
+
+//  27. Count the distinct entries each of the given entries links to and is linked
+//     from, leaving out trashed ones as CountLinksBySource and CountLinksByTarget do.
+func (q *Queries) CountLinksByEntries(ctx context.Context, entryIds []string) ([]CountLinksByEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, countLinksByEntries, pq.Array(entryIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountLinksByEntriesRow
+	for rows.Next() {
+		var i CountLinksByEntriesRow
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.LinkCount,
+			&i.BacklinkCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countLinksBySource = `-- name: CountLinksBySource :one
-SELECT COUNT(*) AS count
+SELECT COUNT(DISTINCT target_entry_id) AS count
 FROM entry_links
 WHERE source_entry_id = $1
+  AND ($2::text IS NULL OR relation = $2)
+  AND NOT EXISTS (SELECT 1 FROM entries AS t WHERE t.id = target_entry_id AND t.deleted_at IS NOT NULL)
 `
 
+type CountLinksBySourceParams struct {
+	SourceEntryID string         `json:"source_entry_id"`
+	Relation      sql.NullString `json:"relation"`
+}
+
 // 5. Count how many outgoing links a given entry has
-// (useful for setting “link_count” in your proto if you want outgoing count)
-func (q *Queries) CountLinksBySource(ctx context.Context, sourceEntryID string) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countLinksBySource, sourceEntryID)
+// (useful for setting “link_count” in your proto if you want outgoing count),
+// optionally only through links with one relation
+func (q *Queries) CountLinksBySource(ctx context.Context, arg CountLinksBySourceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countLinksBySource, arg.SourceEntryID, arg.Relation)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
 const countLinksByTarget = `-- name: CountLinksByTarget :one
-SELECT COUNT(*) AS count
+SELECT COUNT(DISTINCT source_entry_id) AS count
 FROM entry_links
 WHERE target_entry_id = $1
+  AND ($2::text IS NULL OR relation = $2)
+  AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = source_entry_id AND s.deleted_at IS NOT NULL)
 `
 
+type CountLinksByTargetParams struct {
+	TargetEntryID string         `json:"target_entry_id"`
+	Relation      sql.NullString `json:"relation"`
+}
+
 // 6. Count how many incoming links a given entry has
-// (useful for backlink counts)
-func (q *Queries) CountLinksByTarget(ctx context.Context, targetEntryID string) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countLinksByTarget, targetEntryID)
+// (useful for backlink counts), optionally only through links with one relation
+func (q *Queries) CountLinksByTarget(ctx context.Context, arg CountLinksByTargetParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countLinksByTarget, arg.TargetEntryID, arg.Relation)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
+const createContentLinks = `-- name: CreateContentLinks :exec
+INSERT INTO entry_links (source_entry_id, target_entry_id, user_id, created_at, origin)
+SELECT $1, target, $2, CURRENT_TIMESTAMP, 'content'
+FROM unnest($3::text[]) AS target
+ON CONFLICT DO NOTHING
+`
+
+type CreateContentLinksParams struct {
+	SourceEntryID  string   `json:"source_entry_id"`
+	UserID         string   `json:"user_id"`
+	TargetEntryIds []string `json:"target_entry_ids"`
+}
+
+// 10. Add content links from a source entry, skipping ones that already exist.
+func (q *Queries) CreateContentLinks(ctx context.Context, arg CreateContentLinksParams) error {
+	_, err := q.db.ExecContext(ctx, createContentLinks, arg.SourceEntryID, arg.UserID, pq.Array(arg.TargetEntryIds))
+	return err
+}
+
 const createEntryLink = `-- name: CreateEntryLink :one
 
 INSERT INTO entry_links (
     source_entry_id,
     target_entry_id,
     user_id,
-    created_at
+    created_at,
+    origin,
+    relation
 ) VALUES (
              $1,  -- source_entry_id
              $2,  -- target_entry_id
              $3,  -- user_id (who created/owns this link)
-             CURRENT_TIMESTAMP
+             CURRENT_TIMESTAMP,
+             'manual',
+             $4   -- relation ('' for a plain link)
          )
-RETURNING source_entry_id, target_entry_id, user_id, created_at
+RETURNING source_entry_id, target_entry_id, user_id, created_at, origin, relation
 `
 
 type CreateEntryLinkParams struct {
 	SourceEntryID string `json:"source_entry_id"`
 	TargetEntryID string `json:"target_entry_id"`
 	UserID        string `json:"user_id"`
+	Relation      string `json:"relation"`
 }
 
 // go/internal/link/repository/db/queries/entry_links.sql
 // 1. Insert a new link between two entries
 // Returns the inserted row (so SQLC can map it to an EntryLink struct).
 func (q *Queries) CreateEntryLink(ctx context.Context, arg CreateEntryLinkParams) (EntryLink, error) {
-	row := q.db.QueryRowContext(ctx, createEntryLink, arg.SourceEntryID, arg.TargetEntryID, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createEntryLink,
+		arg.SourceEntryID,
+		arg.TargetEntryID,
+		arg.UserID,
+		arg.Relation,
+	)
 	var i EntryLink
 	err := row.Scan(
 		&i.SourceEntryID,
 		&i.TargetEntryID,
 		&i.UserID,
 		&i.CreatedAt,
+		&i.Origin,
+		&i.Relation,
 	)
 	return i, err
 }
 
+const createLinkRelation = `-- name: CreateLinkRelation :one
+INSERT INTO link_relations (user_id, name, inverse_name, created_at)
+VALUES ($1, $2, $3, $4)
+RETURNING user_id, name, inverse_name, created_at
+`
+
+type CreateLinkRelationParams struct {
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	InverseName string    `json:"inverse_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// 16. Register a link relation in the user's vocabulary.
+func (q *Queries) CreateLinkRelation(ctx context.Context, arg CreateLinkRelationParams) (LinkRelation, error) {
+	row := q.db.QueryRowContext(ctx, createLinkRelation,
+		arg.UserID,
+		arg.Name,
+		arg.InverseName,
+		arg.CreatedAt,
+	)
+	var i LinkRelation
+	err := row.Scan(
+		&i.UserID,
+		&i.Name,
+		&i.InverseName,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPendingLinks = `-- name: CreatePendingLinks :exec
+INSERT INTO pending_links (source_entry_id, user_id, target_title, target_key, created_at)
+SELECT $1, $2, t.title, t.key, CURRENT_TIMESTAMP
+FROM unnest($3::text[], $4::text[]) AS t(title, key)
+ON CONFLICT DO NOTHING
+`
+
+type CreatePendingLinksParams struct {
+	SourceEntryID string   `json:"source_entry_id"`
+	UserID        string   `json:"user_id"`
+	TargetTitles  []string `json:"target_titles"`
+	TargetKeys    []string `json:"target_keys"`
+}
+
+// 12. Record wiki-links from a source entry to titles that do not exist yet.
+func (q *Queries) CreatePendingLinks(ctx context.Context, arg CreatePendingLinksParams) error {
+	_, err := q.db.ExecContext(ctx, createPendingLinks,
+		arg.SourceEntryID,
+		arg.UserID,
+		pq.Array(arg.TargetTitles),
+		pq.Array(arg.TargetKeys),
+	)
+	return err
+}
+
 const deleteEntryLink = `-- name: DeleteEntryLink :exec
 DELETE FROM entry_links
 WHERE source_entry_id = $1
   AND target_entry_id = $2
+  AND relation = $3
+  AND origin = 'manual'
 `
 
 type DeleteEntryLinkParams struct {
 	SourceEntryID string `json:"source_entry_id"`
 	TargetEntryID string `json:"target_entry_id"`
+	Relation      string `json:"relation"`
 }
 
-// 2. Delete a link (unlink two entries)
+// 2. Delete a manual link (unlink two entries)
+// Content links follow the source entry's Markdown and cannot be deleted directly.
 func (q *Queries) DeleteEntryLink(ctx context.Context, arg DeleteEntryLinkParams) error {
-	_, err := q.db.ExecContext(ctx, deleteEntryLink, arg.SourceEntryID, arg.TargetEntryID)
+	_, err := q.db.ExecContext(ctx, deleteEntryLink, arg.SourceEntryID, arg.TargetEntryID, arg.Relation)
 	return err
 }
 
+const deleteLinkRelation = `-- name: DeleteLinkRelation :execrows
+DELETE FROM link_relations
+WHERE user_id = $1
+  AND name = $2
+`
+
+type DeleteLinkRelationParams struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+// 18. Remove a link relation from the user's vocabulary.
+func (q *Queries) DeleteLinkRelation(ctx context.Context, arg DeleteLinkRelationParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLinkRelation, arg.UserID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteLinksByEntry = `-- name: DeleteLinksByEntry :many
+DELETE FROM entry_links
+WHERE source_entry_id = $1
+   OR target_entry_id = $1
+RETURNING source_entry_id, target_entry_id, user_id, created_at, origin, relation
+`
+
+// 15. Delete every link into or out of an entry, returning the removed links.
+func (q *Queries) DeleteLinksByEntry(ctx context.Context, entryID string) ([]EntryLink, error) {
+	rows, err := q.db.QueryContext(ctx, deleteLinksByEntry, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EntryLink
+	for rows.Next() {
+		var i EntryLink
+		if err := rows.Scan(
+			&i.SourceEntryID,
+			&i.TargetEntryID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.Origin,
+			&i.Relation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteStaleContentLinks = `-- name: DeleteStaleContentLinks :exec
+DELETE FROM entry_links
+WHERE source_entry_id = $1
+  AND origin = 'content'
+  AND NOT (target_entry_id = ANY ($2::text[]))
+`
+
+type DeleteStaleContentLinksParams struct {
+	SourceEntryID      string   `json:"source_entry_id"`
+	KeepTargetEntryIds []string `json:"keep_target_entry_ids"`
+}
+
+//  9. Remove content links from a source entry whose targets are no longer
+//     referenced by its Markdown.
+func (q *Queries) DeleteStaleContentLinks(ctx context.Context, arg DeleteStaleContentLinksParams) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleContentLinks, arg.SourceEntryID, pq.Array(arg.KeepTargetEntryIds))
+	return err
+}
+
+const deleteStalePendingLinks = `-- name: DeleteStalePendingLinks :exec
+DELETE FROM pending_links
+WHERE source_entry_id = $1
+  AND NOT (target_key = ANY ($2::text[]))
+`
+
+type DeleteStalePendingLinksParams struct {
+	SourceEntryID  string   `json:"source_entry_id"`
+	KeepTargetKeys []string `json:"keep_target_keys"`
+}
+
+//  11. Remove pending links from a source entry whose titles are no longer
+//     referenced by its Markdown.
+func (q *Queries) DeleteStalePendingLinks(ctx context.Context, arg DeleteStalePendingLinksParams) error {
+	_, err := q.db.ExecContext(ctx, deleteStalePendingLinks, arg.SourceEntryID, pq.Array(arg.KeepTargetKeys))
+	return err
+}
+
+const findPaths = `-- name: FindPaths :many
+WITH RECURSIVE edges AS (
+    SELECT DISTINCT l.source_entry_id, l.target_entry_id, l.relation
+    FROM entry_links AS l
+             JOIN entries AS s ON s.id = l.source_entry_id AND s.deleted_at IS NULL
+             JOIN entries AS t ON t.id = l.target_entry_id AND t.deleted_at IS NULL
+    WHERE l.user_id = $1
+), paths (entry_id, entry_ids, relations, reversed) AS (
+    SELECT $2::text, ARRAY [$2::text], ARRAY []::text[], ARRAY []::bool[]
+    UNION ALL
+    SELECT CASE WHEN e.source_entry_id = p.entry_id THEN e.target_entry_id ELSE e.source_entry_id END,
+           p.entry_ids || CASE WHEN e.source_entry_id = p.entry_id THEN e.target_entry_id ELSE e.source_entry_id END,
+           p.relations || e.relation,
+           p.reversed || (e.source_entry_id <> p.entry_id)
+    FROM paths AS p
+             JOIN edges AS e
+                  ON e.source_entry_id = p.entry_id
+                      OR ($3::bool AND e.target_entry_id = p.entry_id)
+    WHERE p.entry_id <> $4
+      AND cardinality(p.entry_ids) <= $5::int
+      AND NOT (CASE WHEN e.source_entry_id = p.entry_id THEN e.target_entry_id ELSE e.source_entry_id END) = ANY (p.entry_ids)
+)
+SELECT p.entry_ids::text[] AS entry_ids,
+       p.relations::text[] AS relations,
+       p.reversed::bool[]  AS reversed,
+       (SELECT array_agg(en.title ORDER BY u.ord)
+        FROM unnest(p.entry_ids) WITH ORDINALITY AS u(id, ord)
+                 JOIN entries AS en ON en.id = u.id)::text[] AS titles
+FROM paths AS p
+WHERE p.entry_id = $4
+ORDER BY cardinality(p.entry_ids), p.entry_ids, p.relations, p.reversed
+LIMIT $6
+`
+
+type FindPathsParams struct {
+	UserID      string `json:"user_id"`
+	FromEntryID string `json:"from_entry_id"`
+	Undirected  bool   `json:"undirected"`
+	ToEntryID   string `json:"to_entry_id"`
+	MaxDepth    int32  `json:"max_depth"`
+	MaxPaths    int32  `json:"max_paths"`
+}
+
+type FindPathsRow struct {
+	EntryIds  []string `json:"entry_ids"`
+	Relations []string `json:"relations"`
+	Reversed  []bool   `json:"reversed"`
+	Titles    []string `json:"titles"`
+}
+
+//  23. Find simple paths between two entries over the user's links between live
+//     entries, shortest first, at most max_depth hops long. Links are followed
+//     forward, and backward too if undirected. Paths through the same entries
+//     using different relations are distinct. Every path up to max_depth is
+//     found before the shortest are picked, so max_depth bounds the work.
+func (q *Queries) FindPaths(ctx context.Context, arg FindPathsParams) ([]FindPathsRow, error) {
+	rows, err := q.db.QueryContext(ctx, findPaths,
+		arg.UserID,
+		arg.FromEntryID,
+		arg.Undirected,
+		arg.ToEntryID,
+		arg.MaxDepth,
+		arg.MaxPaths,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindPathsRow
+	for rows.Next() {
+		var i FindPathsRow
+		if err := rows.Scan(
+			pq.Array(&i.EntryIds),
+			pq.Array(&i.Relations),
+			pq.Array(&i.Reversed),
+			pq.Array(&i.Titles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLinkGraphVersion = `-- name: GetLinkGraphVersion :one
+SELECT COALESCE((SELECT version FROM link_graph_versions WHERE user_id = $1), 0)::bigint AS version
+`
+
+//  26. The version of the user's link graph, bumped by triggers whenever what
+//     ListGraphNodes and ListGraphEdges return may have changed; 0 if it never has.
+func (q *Queries) GetLinkGraphVersion(ctx context.Context, userID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLinkGraphVersion, userID)
+	var version int64
+	err := row.Scan(&version)
+	return version, err
+}
+
+const getNeighborhoodNodes = `-- name: GetNeighborhoodNodes :many
+WITH RECURSIVE walk (entry_id, depth) AS (
+    SELECT $1::text, 0
+    UNION
+    SELECT e.id, w.depth + 1
+    FROM walk AS w
+             JOIN entry_links AS l
+                  ON ($2::bool AND l.source_entry_id = w.entry_id)
+                      OR ($3::bool AND l.target_entry_id = w.entry_id)
+             JOIN entries AS e
+                  ON e.id = CASE WHEN l.source_entry_id = w.entry_id THEN l.target_entry_id ELSE l.source_entry_id END
+    WHERE w.depth < $4::int
+      AND ($5::text IS NULL OR l.relation = $5)
+      AND e.deleted_at IS NULL
+      AND ($6::text IS NULL OR e.growth_stage = $6)
+      AND (NOT $7::bool OR e.id = ANY ($8::text[]))
+)
+SELECT e.id, e.title, e.growth_stage, MIN(w.depth)::int AS depth
+FROM walk AS w
+         JOIN entries AS e ON e.id = w.entry_id
+GROUP BY e.id, e.title, e.growth_stage
+ORDER BY depth, e.id
+LIMIT $9
+`
+
+type GetNeighborhoodNodesParams struct {
+	EntryID     string         `json:"entry_id"`
+	Outgoing    bool           `json:"outgoing"`
+	Incoming    bool           `json:"incoming"`
+	MaxDepth    int32          `json:"max_depth"`
+	Relation    sql.NullString `json:"relation"`
+	GrowthStage sql.NullString `json:"growth_stage"`
+	FilterByIds bool           `json:"filter_by_ids"`
+	EntryIds    []string       `json:"entry_ids"`
+	MaxNodes    int32          `json:"max_nodes"`
+}
+
+type GetNeighborhoodNodesRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	GrowthStage string `json:"growth_stage"`
+	Depth       int32  `json:"depth"`
+}
+
+//  21. Walk entry_links out from an entry, following links forward (outgoing),
+//     backward (incoming) or both, up to max_depth hops. Only live entries that
+//     pass the filters are entered. Each reachable entry is returned once, at its
+//     shortest distance, nearest first; the start entry has depth 0.
+func (q *Queries) GetNeighborhoodNodes(ctx context.Context, arg GetNeighborhoodNodesParams) ([]GetNeighborhoodNodesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getNeighborhoodNodes,
+		arg.EntryID,
+		arg.Outgoing,
+		arg.Incoming,
+		arg.MaxDepth,
+		arg.Relation,
+		arg.GrowthStage,
+		arg.FilterByIds,
+		pq.Array(arg.EntryIds),
+		arg.MaxNodes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetNeighborhoodNodesRow
+	for rows.Next() {
+		var i GetNeighborhoodNodesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.GrowthStage,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const linkRelationInUse = `-- name: LinkRelationInUse :one
+SELECT EXISTS (SELECT 1
+               FROM entry_links
+               WHERE user_id = $1
+                 AND relation = ANY ($2::text[]))::bool AS in_use
+`
+
+type LinkRelationInUseParams struct {
+	UserID string   `json:"user_id"`
+	Names  []string `json:"names"`
+}
+
+// 19. Report whether any of the user's links uses one of the given relation names.
+func (q *Queries) LinkRelationInUse(ctx context.Context, arg LinkRelationInUseParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, linkRelationInUse, arg.UserID, pq.Array(arg.Names))
+	var in_use bool
+	err := row.Scan(&in_use)
+	return in_use, err
+}
+
 const listBacklinkedEntries = `-- name: ListBacklinkedEntries :many
 
 SELECT
@@ -102,12 +565,16 @@ SELECT
     e.content,
     e.growth_stage,
     e.created_at,
-    e.updated_at
+    e.updated_at,
+    e.version,
+    e.deleted_at,
+    e.aliases,
+    e.search_vector
 FROM entries AS e
-         JOIN entry_links AS l
-              ON l.source_entry_id = e.id
-WHERE l.target_entry_id = $1
-ORDER BY e.created_at
+WHERE e.id IN (SELECT l.source_entry_id FROM entry_links AS l WHERE l.target_entry_id = $1)
+  AND e.id <> $1
+  AND e.deleted_at IS NULL
+ORDER BY e.created_at, e.id
 LIMIT $2      -- page_size
     OFFSET $3
 `
@@ -119,8 +586,8 @@ type ListBacklinkedEntriesParams struct {
 }
 
 // offset (page_token converted to integer)
-//  8. (Optional) List the actual Entry rows that link *into* a given entry,
-//     with pagination. Adjust as above.
+//  8. List the other live entries that link *into* a given entry, once each
+//     however many links they have, with pagination.
 func (q *Queries) ListBacklinkedEntries(ctx context.Context, arg ListBacklinkedEntriesParams) ([]Entry, error) {
 	rows, err := q.db.QueryContext(ctx, listBacklinkedEntries, arg.TargetEntryID, arg.Limit, arg.Offset)
 	if err != nil {
@@ -138,6 +605,95 @@ func (q *Queries) ListBacklinkedEntries(ctx context.Context, arg ListBacklinkedE
 			&i.GrowthStage,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGraphEdges = `-- name: ListGraphEdges :many
+SELECT DISTINCT l.source_entry_id, l.target_entry_id
+FROM entry_links AS l
+         JOIN entries AS s ON s.id = l.source_entry_id AND s.deleted_at IS NULL
+         JOIN entries AS t ON t.id = l.target_entry_id AND t.deleted_at IS NULL
+WHERE l.user_id = $1
+  AND l.source_entry_id <> l.target_entry_id
+ORDER BY l.source_entry_id, l.target_entry_id
+`
+
+type ListGraphEdgesRow struct {
+	SourceEntryID string `json:"source_entry_id"`
+	TargetEntryID string `json:"target_entry_id"`
+}
+
+//  25. List each pair of the user's live entries joined by at least one link, once,
+//     ignoring links from an entry to itself.
+func (q *Queries) ListGraphEdges(ctx context.Context, userID string) ([]ListGraphEdgesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGraphEdges, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGraphEdgesRow
+	for rows.Next() {
+		var i ListGraphEdgesRow
+		if err := rows.Scan(
+			&i.SourceEntryID,
+			&i.TargetEntryID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGraphNodes = `-- name: ListGraphNodes :many
+SELECT id, title, growth_stage
+FROM entries
+WHERE user_id = $1
+  AND deleted_at IS NULL
+ORDER BY id
+`
+
+type ListGraphNodesRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	GrowthStage string `json:"growth_stage"`
+}
+
+// 24. List the user's live entries as nodes of their link graph.
+func (q *Queries) ListGraphNodes(ctx context.Context, userID string) ([]ListGraphNodesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGraphNodes, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGraphNodesRow
+	for rows.Next() {
+		var i ListGraphNodesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.GrowthStage,
 		); err != nil {
 			return nil, err
 		}
@@ -160,11 +716,16 @@ SELECT
     e.content,
     e.growth_stage,
     e.created_at,
-    e.updated_at
+    e.updated_at,
+    e.version,
+    e.deleted_at,
+    e.aliases,
+    e.search_vector
 FROM entries AS e
          JOIN entry_links AS l
               ON l.target_entry_id = e.id
 WHERE l.source_entry_id = $1
+  AND e.deleted_at IS NULL
 ORDER BY e.created_at
 LIMIT $2      -- page_size
     OFFSET $3
@@ -196,6 +757,93 @@ func (q *Queries) ListLinkedEntries(ctx context.Context, arg ListLinkedEntriesPa
 			&i.GrowthStage,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLinkRelations = `-- name: ListLinkRelations :many
+SELECT user_id, name, inverse_name, created_at
+FROM link_relations
+WHERE user_id = $1
+ORDER BY name
+`
+
+// 17. List the user's link relations.
+func (q *Queries) ListLinkRelations(ctx context.Context, userID string) ([]LinkRelation, error) {
+	rows, err := q.db.QueryContext(ctx, listLinkRelations, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LinkRelation
+	for rows.Next() {
+		var i LinkRelation
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Name,
+			&i.InverseName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLinksAmong = `-- name: ListLinksAmong :many
+SELECT source_entry_id, target_entry_id, user_id, created_at, origin, relation
+FROM entry_links
+WHERE source_entry_id = ANY ($1::text[])
+  AND target_entry_id = ANY ($1::text[])
+  AND ($2::text IS NULL OR relation = $2)
+ORDER BY source_entry_id, target_entry_id, origin, relation
+LIMIT $3
+`
+
+type ListLinksAmongParams struct {
+	EntryIds []string       `json:"entry_ids"`
+	Relation sql.NullString `json:"relation"`
+	MaxEdges int32          `json:"max_edges"`
+}
+
+// 22. List the links between the given entries, optionally only those with one relation.
+func (q *Queries) ListLinksAmong(ctx context.Context, arg ListLinksAmongParams) ([]EntryLink, error) {
+	rows, err := q.db.QueryContext(ctx, listLinksAmong, pq.Array(arg.EntryIds), arg.Relation, arg.MaxEdges)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EntryLink
+	for rows.Next() {
+		var i EntryLink
+		if err := rows.Scan(
+			&i.SourceEntryID,
+			&i.TargetEntryID,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.Origin,
+			&i.Relation,
 		); err != nil {
 			return nil, err
 		}
@@ -215,16 +863,25 @@ SELECT
     source_entry_id,
     target_entry_id,
     user_id,
-    created_at
+    created_at,
+    origin,
+    relation
 FROM entry_links
 WHERE source_entry_id = $1
+  AND ($2::text IS NULL OR relation = $2)
+  AND NOT EXISTS (SELECT 1 FROM entries AS t WHERE t.id = target_entry_id AND t.deleted_at IS NOT NULL)
 ORDER BY created_at
 `
 
+type ListLinksBySourceParams struct {
+	SourceEntryID string         `json:"source_entry_id"`
+	Relation      sql.NullString `json:"relation"`
+}
+
 // 3. List all links where a given entry is the “source”
-// (i.e. all outgoing links from entry X)
-func (q *Queries) ListLinksBySource(ctx context.Context, sourceEntryID string) ([]EntryLink, error) {
-	rows, err := q.db.QueryContext(ctx, listLinksBySource, sourceEntryID)
+// (i.e. all outgoing links from entry X), optionally only those with one relation
+func (q *Queries) ListLinksBySource(ctx context.Context, arg ListLinksBySourceParams) ([]EntryLink, error) {
+	rows, err := q.db.QueryContext(ctx, listLinksBySource, arg.SourceEntryID, arg.Relation)
 	if err != nil {
 		return nil, err
 	}
@@ -237,6 +894,8 @@ func (q *Queries) ListLinksBySource(ctx context.Context, sourceEntryID string) (
 			&i.TargetEntryID,
 			&i.UserID,
 			&i.CreatedAt,
+			&i.Origin,
+			&i.Relation,
 		); err != nil {
 			return nil, err
 		}
@@ -256,16 +915,25 @@ SELECT
     source_entry_id,
     target_entry_id,
     user_id,
-    created_at
+    created_at,
+    origin,
+    relation
 FROM entry_links
 WHERE target_entry_id = $1
+  AND ($2::text IS NULL OR relation = $2)
+  AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = source_entry_id AND s.deleted_at IS NOT NULL)
 ORDER BY created_at
 `
 
+type ListLinksByTargetParams struct {
+	TargetEntryID string         `json:"target_entry_id"`
+	Relation      sql.NullString `json:"relation"`
+}
+
 // 4. List all links where a given entry is the “target”
-// (i.e. all incoming/backlinks to entry X)
-func (q *Queries) ListLinksByTarget(ctx context.Context, targetEntryID string) ([]EntryLink, error) {
-	rows, err := q.db.QueryContext(ctx, listLinksByTarget, targetEntryID)
+// (i.e. all incoming/backlinks to entry X), optionally only those with one relation
+func (q *Queries) ListLinksByTarget(ctx context.Context, arg ListLinksByTargetParams) ([]EntryLink, error) {
+	rows, err := q.db.QueryContext(ctx, listLinksByTarget, arg.TargetEntryID, arg.Relation)
 	if err != nil {
 		return nil, err
 	}
@@ -278,6 +946,88 @@ func (q *Queries) ListLinksByTarget(ctx context.Context, targetEntryID string) (
 			&i.TargetEntryID,
 			&i.UserID,
 			&i.CreatedAt,
+			&i.Origin,
+			&i.Relation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMentioningEntries = `-- name: ListMentioningEntries :many
+SELECT
+    e.id,
+    e.user_id,
+    e.title,
+    e.content,
+    e.growth_stage,
+    e.created_at,
+    e.updated_at,
+    e.version,
+    e.deleted_at,
+    e.aliases,
+    e.search_vector
+FROM entries AS e
+WHERE e.user_id = $1
+  AND e.id <> $2
+  AND e.deleted_at IS NULL
+  AND EXISTS (SELECT 1
+              FROM unnest($3::text[]) AS n(name)
+              WHERE strpos(lower(e.content), lower(n.name)) > 0)
+  AND NOT EXISTS (SELECT 1
+                  FROM entry_links AS l
+                  WHERE l.source_entry_id = e.id
+                    AND l.target_entry_id = $2)
+ORDER BY e.updated_at DESC, e.id
+LIMIT $4 OFFSET $5
+`
+
+type ListMentioningEntriesParams struct {
+	UserID     string   `json:"user_id"`
+	EntryID    string   `json:"entry_id"`
+	Names      []string `json:"names"`
+	MaxResults int32    `json:"max_results"`
+	Skip       int32    `json:"skip"`
+}
+
+//  20. List the user's other live entries whose content contains one of names,
+//     ignoring case, but that do not link to the entry yet, most recently
+//     updated first. Callers must still check for whole-word matches outside code.
+func (q *Queries) ListMentioningEntries(ctx context.Context, arg ListMentioningEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listMentioningEntries,
+		arg.UserID,
+		arg.EntryID,
+		pq.Array(arg.Names),
+		arg.MaxResults,
+		arg.Skip,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.GrowthStage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			pq.Array(&i.Aliases),
+			&i.SearchVector,
 		); err != nil {
 			return nil, err
 		}
@@ -291,3 +1041,77 @@ func (q *Queries) ListLinksByTarget(ctx context.Context, targetEntryID string) (
 	}
 	return items, nil
 }
+
+const listPendingLinks = `-- name: ListPendingLinks :many
+SELECT source_entry_id, user_id, target_title, target_key, created_at
+FROM pending_links
+WHERE user_id = $1
+  AND ($2::text = '' OR source_entry_id = $2)
+  AND NOT EXISTS (SELECT 1 FROM entries AS s WHERE s.id = source_entry_id AND s.deleted_at IS NOT NULL)
+ORDER BY target_key, source_entry_id
+`
+
+type ListPendingLinksParams struct {
+	UserID        string `json:"user_id"`
+	SourceEntryID string `json:"source_entry_id"`
+}
+
+// 14. List a user's pending links, optionally only those from one source entry.
+func (q *Queries) ListPendingLinks(ctx context.Context, arg ListPendingLinksParams) ([]PendingLink, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingLinks, arg.UserID, arg.SourceEntryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PendingLink
+	for rows.Next() {
+		var i PendingLink
+		if err := rows.Scan(
+			&i.SourceEntryID,
+			&i.UserID,
+			&i.TargetTitle,
+			&i.TargetKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rebindPendingLinks = `-- name: RebindPendingLinks :execrows
+WITH bound AS (
+    DELETE FROM pending_links
+    WHERE user_id = $1
+      AND target_key = ANY ($2::text[])
+      AND source_entry_id <> $3
+    RETURNING source_entry_id, user_id
+)
+INSERT INTO entry_links (source_entry_id, target_entry_id, user_id, created_at, origin)
+SELECT source_entry_id, $3, user_id, CURRENT_TIMESTAMP, 'content'
+FROM bound
+ON CONFLICT DO NOTHING
+`
+
+type RebindPendingLinksParams struct {
+	UserID        string   `json:"user_id"`
+	TargetKeys    []string `json:"target_keys"`
+	TargetEntryID string   `json:"target_entry_id"`
+}
+
+//  13. Turn the user's pending links to any of an entry's names (its title and
+//     aliases) into content links to the entry.
+func (q *Queries) RebindPendingLinks(ctx context.Context, arg RebindPendingLinksParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rebindPendingLinks, arg.UserID, pq.Array(arg.TargetKeys), arg.TargetEntryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -5,22 +5,132 @@
 package sqlc
 
 import (
+	"database/sql"
 	"time"
 )
 
 type Entry struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
+	ID           string       `json:"id"`
+	UserID       string       `json:"user_id"`
+	Title        string       `json:"title"`
+	Content      string       `json:"content"`
+	GrowthStage  string       `json:"growth_stage"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Version      int64        `json:"version"`
+	DeletedAt    sql.NullTime `json:"deleted_at"`
+	Aliases      []string     `json:"aliases"`
+	SearchVector interface{}  `json:"search_vector"`
+}
+
+type EntryAlias struct {
+	UserID   string `json:"user_id"`
+	AliasKey string `json:"alias_key"`
+	EntryID  string `json:"entry_id"`
+	Alias    string `json:"alias"`
+}
+
+type EntryIdempotencyKey struct {
+	UserID         string    `json:"user_id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	EntryID        string    `json:"entry_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type EntryLink struct {
+	SourceEntryID string    `json:"source_entry_id"`
+	TargetEntryID string    `json:"target_entry_id"`
+	UserID        string    `json:"user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	Origin        string    `json:"origin"`
+	Relation      string    `json:"relation"`
+}
+
+type EntryRevision struct {
+	EntryID     string    `json:"entry_id"`
+	Revision    int32     `json:"revision"`
 	Title       string    `json:"title"`
 	Content     string    `json:"content"`
 	GrowthStage string    `json:"growth_stage"`
 	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-type EntryLink struct {
+type EntryTag struct {
+	EntryID string `json:"entry_id"`
+	TagID   string `json:"tag_id"`
+}
+
+type LinkGraphVersion struct {
+	UserID  string `json:"user_id"`
+	Version int64  `json:"version"`
+}
+
+type LinkRelation struct {
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	InverseName string    `json:"inverse_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PendingLink struct {
 	SourceEntryID string    `json:"source_entry_id"`
-	TargetEntryID string    `json:"target_entry_id"`
 	UserID        string    `json:"user_id"`
+	TargetTitle   string    `json:"target_title"`
+	TargetKey     string    `json:"target_key"`
 	CreatedAt     time.Time `json:"created_at"`
 }
+
+type PersonalAccessToken struct {
+	ID         string       `json:"id"`
+	UserID     string       `json:"user_id"`
+	Name       string       `json:"name"`
+	TokenHash  string       `json:"token_hash"`
+	Scopes     []string     `json:"scopes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+}
+
+type RetiredRefreshToken struct {
+	TokenHash string    `json:"token_hash"`
+	SessionID string    `json:"session_id"`
+	RetiredAt time.Time `json:"retired_at"`
+}
+
+type Session struct {
+	ID               string       `json:"id"`
+	UserID           string       `json:"user_id"`
+	RefreshTokenHash string       `json:"refresh_token_hash"`
+	CreatedAt        time.Time    `json:"created_at"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RevokedAt        sql.NullTime `json:"revoked_at"`
+}
+
+type StageTransition struct {
+	ID        int64          `json:"id"`
+	EntryID   string         `json:"entry_id"`
+	UserID    string         `json:"user_id"`
+	TokenID   sql.NullString `json:"token_id"`
+	FromStage string         `json:"from_stage"`
+	ToStage   string         `json:"to_stage"`
+	Reason    string         `json:"reason"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type Tag struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type User struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"password_hash"`
+	DisplayName  string    `json:"display_name"`
+	GardenSlug   string    `json:"garden_slug"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -6,41 +6,176 @@ package sqlc
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	// Records that an idempotency key produced entry_id. Returns no row when the key
+	// is already held by an unexpired claim.
+	ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (string, error)
+	// 27. Count the distinct entries each of the given entries links to and is linked
+	//     from, leaving out trashed ones as CountLinksBySource and CountLinksByTarget do.
+	CountLinksByEntries(ctx context.Context, entryIds []string) ([]CountLinksByEntriesRow, error)
 	// 5. Count how many outgoing links a given entry has
-	// (useful for setting “link_count” in your proto if you want outgoing count)
-	CountLinksBySource(ctx context.Context, sourceEntryID string) (int64, error)
+	// (useful for setting “link_count” in your proto if you want outgoing count),
+	// optionally only through links with one relation
+	CountLinksBySource(ctx context.Context, arg CountLinksBySourceParams) (int64, error)
 	// 6. Count how many incoming links a given entry has
-	// (useful for backlink counts)
-	CountLinksByTarget(ctx context.Context, targetEntryID string) (int64, error)
+	// (useful for backlink counts), optionally only through links with one relation
+	CountLinksByTarget(ctx context.Context, arg CountLinksByTargetParams) (int64, error)
+	CreateAccessToken(ctx context.Context, arg CreateAccessTokenParams) (PersonalAccessToken, error)
+	// 10. Add content links from a source entry, skipping ones that already exist.
+	CreateContentLinks(ctx context.Context, arg CreateContentLinksParams) error
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateEntryAliases(ctx context.Context, arg CreateEntryAliasesParams) error
 	// go/internal/link/repository/db/queries/entry_links.sql
 	// 1. Insert a new link between two entries
 	// Returns the inserted row (so SQLC can map it to an EntryLink struct).
 	CreateEntryLink(ctx context.Context, arg CreateEntryLinkParams) (EntryLink, error)
+	// Snapshots the entry's current state as its next revision.
+	CreateEntryRevision(ctx context.Context, id string) (EntryRevision, error)
+	// 2. Tag an entry with the user's tags of the given names.
+	CreateEntryTags(ctx context.Context, arg CreateEntryTagsParams) error
+	// 16. Register a link relation in the user's vocabulary.
+	CreateLinkRelation(ctx context.Context, arg CreateLinkRelationParams) (LinkRelation, error)
+	// 12. Record wiki-links from a source entry to titles that do not exist yet.
+	CreatePendingLinks(ctx context.Context, arg CreatePendingLinksParams) error
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateStageTransition(ctx context.Context, arg CreateStageTransitionParams) (StageTransition, error)
+	// 1. Create the user's tags that do not exist yet.
+	CreateTags(ctx context.Context, arg CreateTagsParams) error
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteEntry(ctx context.Context, id string) error
-	// 2. Delete a link (unlink two entries)
+	DeleteEntryAliases(ctx context.Context, entryID string) error
+	// 2. Delete a manual link (unlink two entries)
+	// Content links follow the source entry's Markdown and cannot be deleted directly.
 	DeleteEntryLink(ctx context.Context, arg DeleteEntryLinkParams) error
+	// 18. Remove a link relation from the user's vocabulary.
+	DeleteLinkRelation(ctx context.Context, arg DeleteLinkRelationParams) (int64, error)
+	// 15. Delete every link into or out of an entry, returning the removed links.
+	DeleteLinksByEntry(ctx context.Context, entryID string) ([]EntryLink, error)
+	// 9. Remove content links from a source entry whose targets are no longer
+	//     referenced by its Markdown.
+	DeleteStaleContentLinks(ctx context.Context, arg DeleteStaleContentLinksParams) error
+	// 3. Untag an entry from every tag not in keep_names.
+	DeleteStaleEntryTags(ctx context.Context, arg DeleteStaleEntryTagsParams) (int64, error)
+	// 11. Remove pending links from a source entry whose titles are no longer
+	//     referenced by its Markdown.
+	DeleteStalePendingLinks(ctx context.Context, arg DeleteStalePendingLinksParams) error
+	// 4. Delete tags that neither tag an entry nor have a nested tag that does.
+	//     An empty user_id cleans up after every user.
+	DeleteUnusedTags(ctx context.Context, userID string) error
+	// 23. Find simple paths between two entries over the user's links between live
+	//     entries, shortest first, at most max_depth hops long. Links are followed
+	//     forward, and backward too if undirected. Paths through the same entries
+	//     using different relations are distinct. Every path up to max_depth is
+	//     found before the shortest are picked, so max_depth bounds the work.
+	FindPaths(ctx context.Context, arg FindPathsParams) ([]FindPathsRow, error)
+	// Returns one of name_keys that is already an alias of another of the user's
+	// entries or, with check_titles, the lowercased title of another live entry.
+	FindTakenName(ctx context.Context, arg FindTakenNameParams) (FindTakenNameRow, error)
+	GetAccessTokenByHash(ctx context.Context, tokenHash string) (PersonalAccessToken, error)
 	GetEntryByID(ctx context.Context, id string) (Entry, error)
+	GetEntryRevision(ctx context.Context, arg GetEntryRevisionParams) (EntryRevision, error)
+	GetIdempotencyKeyEntryID(ctx context.Context, arg GetIdempotencyKeyEntryIDParams) (string, error)
+	// 26. The version of the user's link graph, bumped by triggers whenever what
+	//     ListGraphNodes and ListGraphEdges return may have changed; 0 if it never has.
+	GetLinkGraphVersion(ctx context.Context, userID string) (int64, error)
+	// 21. Walk entry_links out from an entry, following links forward (outgoing),
+	//     backward (incoming) or both, up to max_depth hops. Only live entries that
+	//     pass the filters are entered. Each reachable entry is returned once, at its
+	//     shortest distance, nearest first; the start entry has depth 0.
+	GetNeighborhoodNodes(ctx context.Context, arg GetNeighborhoodNodesParams) ([]GetNeighborhoodNodesRow, error)
+	GetSessionByID(ctx context.Context, id string) (Session, error)
+	GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (Session, error)
+	GetSessionByRetiredRefreshTokenHash(ctx context.Context, tokenHash string) (Session, error)
+	// 5. Get one of the user's tags by name.
+	GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id string) (User, error)
+	// 19. Report whether any of the user's links uses one of the given relation names.
+	LinkRelationInUse(ctx context.Context, arg LinkRelationInUseParams) (bool, error)
+	ListAccessTokensByUser(ctx context.Context, userID string) ([]PersonalAccessToken, error)
 	// offset (page_token converted to integer)
-	// 8. (Optional) List the actual Entry rows that link *into* a given entry,
-	//     with pagination. Adjust as above.
+	// 8. List the other live entries that link *into* a given entry, once each
+	//     however many links they have, with pagination.
 	ListBacklinkedEntries(ctx context.Context, arg ListBacklinkedEntriesParams) ([]Entry, error)
+	// 8. List the user's live entries tagged with any of the given tags or a tag nested under one.
+	ListEntriesByTags(ctx context.Context, arg ListEntriesByTagsParams) ([]Entry, error)
 	ListEntriesByUser(ctx context.Context, userID string) ([]Entry, error)
 	ListEntriesByUserSince(ctx context.Context, arg ListEntriesByUserSinceParams) ([]Entry, error)
+	ListEntriesPageByCreated(ctx context.Context, arg ListEntriesPageByCreatedParams) ([]Entry, error)
+	ListEntriesPageByLinkCount(ctx context.Context, arg ListEntriesPageByLinkCountParams) ([]ListEntriesPageByLinkCountRow, error)
+	ListEntriesPageByTitle(ctx context.Context, arg ListEntriesPageByTitleParams) ([]Entry, error)
+	ListEntriesPageByUpdated(ctx context.Context, arg ListEntriesPageByUpdatedParams) ([]Entry, error)
+	// Entries of any user trashed before the cutoff, for the purger.
+	ListEntriesTrashedBefore(ctx context.Context, trashedBefore time.Time) ([]Entry, error)
+	ListEntryRevisions(ctx context.Context, entryID string) ([]EntryRevision, error)
+	// 7. List every live entry of the user with its tags, one row per tag.
+	//     Untagged entries have a single row with a NULL name.
+	ListEntryTagNames(ctx context.Context, userID string) ([]ListEntryTagNamesRow, error)
+	// 25. List each pair of the user's live entries joined by at least one link, once,
+	//     ignoring links from an entry to itself.
+	ListGraphEdges(ctx context.Context, userID string) ([]ListGraphEdgesRow, error)
+	// 24. List the user's live entries as nodes of their link graph.
+	ListGraphNodes(ctx context.Context, userID string) ([]ListGraphNodesRow, error)
+	// 17. List the user's link relations.
+	ListLinkRelations(ctx context.Context, userID string) ([]LinkRelation, error)
 	// 7. (Optional) List the actual Entry rows that a given source is linked to,
 	//     with pagination parameters (page size + offset). This is if you want to
 	//     fetch full Entry data in one go. Adjust the SELECT columns as needed.
 	ListLinkedEntries(ctx context.Context, arg ListLinkedEntriesParams) ([]Entry, error)
+	// 22. List the links between the given entries, optionally only those with one relation.
+	ListLinksAmong(ctx context.Context, arg ListLinksAmongParams) ([]EntryLink, error)
 	// 3. List all links where a given entry is the “source”
-	// (i.e. all outgoing links from entry X)
-	ListLinksBySource(ctx context.Context, sourceEntryID string) ([]EntryLink, error)
+	// (i.e. all outgoing links from entry X), optionally only those with one relation
+	ListLinksBySource(ctx context.Context, arg ListLinksBySourceParams) ([]EntryLink, error)
 	// 4. List all links where a given entry is the “target”
-	// (i.e. all incoming/backlinks to entry X)
-	ListLinksByTarget(ctx context.Context, targetEntryID string) ([]EntryLink, error)
+	// (i.e. all incoming/backlinks to entry X), optionally only those with one relation
+	ListLinksByTarget(ctx context.Context, arg ListLinksByTargetParams) ([]EntryLink, error)
+	// 20. List the user's other live entries whose content contains one of names,
+	//     ignoring case, but that do not link to the entry yet, most recently
+	//     updated first. Callers must still check for whole-word matches outside code.
+	ListMentioningEntries(ctx context.Context, arg ListMentioningEntriesParams) ([]Entry, error)
+	// Returns the subset of the given entry IDs that belong to the user.
+	ListOwnedEntryIDs(ctx context.Context, arg ListOwnedEntryIDsParams) ([]string, error)
+	// 14. List a user's pending links, optionally only those from one source entry.
+	ListPendingLinks(ctx context.Context, arg ListPendingLinksParams) ([]PendingLink, error)
+	ListStageTransitions(ctx context.Context, entryID string) ([]StageTransition, error)
+	// 6. List the user's tags with how many live entries have each tag or a tag nested under it.
+	ListTags(ctx context.Context, userID string) ([]ListTagsRow, error)
+	ListTrashedEntries(ctx context.Context, userID string) ([]Entry, error)
+	// 13. Turn the user's pending links to any of an entry's names (its title and
+	//     aliases) into content links to the entry.
+	RebindPendingLinks(ctx context.Context, arg RebindPendingLinksParams) (int64, error)
+	// Finds the user's entries whose titles or aliases match any of the given
+	// lowercased titles. A title wins over an alias; when several entries share
+	// a title, the oldest one wins.
+	ResolveEntryTitles(ctx context.Context, arg ResolveEntryTitlesParams) ([]ResolveEntryTitlesRow, error)
+	RestoreEntry(ctx context.Context, id string) (Entry, error)
+	RetireRefreshToken(ctx context.Context, arg RetireRefreshTokenParams) error
+	RevokeAccessToken(ctx context.Context, arg RevokeAccessTokenParams) (int64, error)
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) error
+	RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) error
+	// Swap in a new refresh token, invalidating the previous one. Returns no row
+	// unless old_hash is still the session's refresh token and the session is
+	// live, so of two requests presenting the same token only one rotates it.
+	RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (Session, error)
+	// Full-text search over the user's live entries, best match first. query is in
+	// to_tsquery syntax. Highlights are computed only for the returned page.
+	SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]SearchEntriesRow, error)
+	// Typo-tolerant title and alias lookup. Up to max_candidates of the closest matches
+	// are re-ranked by a score that also rewards recent edits (halving after 30
+	// days) and inbound links, counted as in CountLinksByTarget.
+	SuggestEntries(ctx context.Context, arg SuggestEntriesParams) ([]SuggestEntriesRow, error)
+	TouchAccessToken(ctx context.Context, arg TouchAccessTokenParams) error
+	TrashEntry(ctx context.Context, arg TrashEntryParams) (int64, error)
+	// Returns no row if expected_version is set and no longer matches.
 	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
+	// Updates only the non-null fields, so unchanged content is not sent or rewritten.
+	// Returns no row if expected_version is set and no longer matches.
+	UpdateEntryFields(ctx context.Context, arg UpdateEntryFieldsParams) (Entry, error)
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,90 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+// NameTakenError means an entry's title is already an alias of another of the
+// owner's entries, or one of its aliases is already another entry's alias or title.
+type NameTakenError struct {
+	Name    string // The title or alias as given; empty if not known
+	EntryID string // The entry that already has the name; empty if not known
+}
+
+func (e *NameTakenError) Error() string {
+	if e.Name == "" {
+		return "title or alias is already used by another entry"
+	}
+	return fmt.Sprintf("%q is already the title or an alias of another entry", e.Name)
+}
+
+// syncAliases makes entry_aliases match e.Aliases, after checking that neither
+// e's title nor its aliases are taken (see NameTakenError).
+func syncAliases(ctx context.Context, q *db.Queries, e *models.Entry) error {
+	if err := checkNamesFree(ctx, q, e, []string{e.Title}, false); err != nil {
+		return err
+	}
+	if err := q.DeleteEntryAliases(ctx, e.ID); err != nil {
+		return err
+	}
+	if len(e.Aliases) == 0 {
+		return nil
+	}
+	if err := checkNamesFree(ctx, q, e, e.Aliases, true); err != nil {
+		return err
+	}
+
+	err := q.CreateEntryAliases(ctx, db.CreateEntryAliasesParams{
+		UserID:    e.UserID,
+		EntryID:   e.ID,
+		Aliases:   e.Aliases,
+		AliasKeys: titleKeys(e.Aliases),
+	})
+	// A concurrent write can claim an alias between the check and the insert.
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "entry_aliases_pkey" {
+		return &NameTakenError{}
+	}
+	return err
+}
+
+// checkNamesFree returns a *NameTakenError if one of names is an alias of
+// another of e's owner's entries or, with checkTitles, another live entry's title.
+func checkNamesFree(ctx context.Context, q *db.Queries, e *models.Entry, names []string, checkTitles bool) error {
+	taken, err := q.FindTakenName(ctx, db.FindTakenNameParams{
+		UserID:      e.UserID,
+		EntryID:     e.ID,
+		NameKeys:    titleKeys(names),
+		CheckTitles: checkTitles,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if titleKey(name) == taken.NameKey {
+			return &NameTakenError{Name: name, EntryID: taken.EntryID}
+		}
+	}
+	return &NameTakenError{Name: taken.NameKey, EntryID: taken.EntryID}
+}
+
+// titleKeys returns the titleKey of each of names.
+func titleKeys(names []string) []string {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = titleKey(name)
+	}
+	return keys
+}
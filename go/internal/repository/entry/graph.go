@@ -0,0 +1,251 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+
+	linkModels "moss/go/internal/models/link"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+func (r *repository) Neighborhood(ctx context.Context, userID string, q linkModels.NeighborhoodQuery) (*linkModels.Graph, error) {
+	var tagged []string
+	if q.Tags != "" {
+		var err error
+		tagged, err = r.matchTags(ctx, userID, q.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+	relation := sql.NullString{}
+	if q.Relation != nil {
+		relation = sql.NullString{String: *q.Relation, Valid: true}
+	}
+
+	// Fetch one node and edge over each limit to tell whether any were left out.
+	rows, err := r.queries.GetNeighborhoodNodes(ctx, db.GetNeighborhoodNodesParams{
+		EntryID:     q.EntryID,
+		Outgoing:    q.Direction != linkModels.DirectionIn,
+		Incoming:    q.Direction != linkModels.DirectionOut,
+		MaxDepth:    int32(q.Depth),
+		Relation:    relation,
+		GrowthStage: toNullString(q.GrowthStage),
+		FilterByIds: q.Tags != "",
+		EntryIds:    tagged,
+		MaxNodes:    int32(q.MaxNodes + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &linkModels.Graph{}
+	if len(rows) > q.MaxNodes {
+		rows, graph.Truncated = rows[:q.MaxNodes], true
+	}
+	ids := make([]string, len(rows))
+	graph.Nodes = make([]*linkModels.Node, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+		graph.Nodes[i] = &linkModels.Node{
+			EntryID:     row.ID,
+			Title:       row.Title,
+			GrowthStage: row.GrowthStage,
+			Depth:       int(row.Depth),
+		}
+	}
+
+	links, err := r.queries.ListLinksAmong(ctx, db.ListLinksAmongParams{
+		EntryIds: ids,
+		Relation: relation,
+		MaxEdges: int32(q.MaxEdges + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(links) > q.MaxEdges {
+		links, graph.Truncated = links[:q.MaxEdges], true
+	}
+	graph.Edges = fromDBLinks(links)
+	return graph, nil
+}
+
+func (r *repository) FindPaths(ctx context.Context, userID string, q linkModels.PathQuery) ([]*linkModels.Path, error) {
+	rows, err := r.queries.FindPaths(ctx, db.FindPathsParams{
+		UserID:      userID,
+		FromEntryID: q.FromEntryID,
+		Undirected:  q.Undirected,
+		ToEntryID:   q.ToEntryID,
+		MaxDepth:    int32(q.MaxDepth),
+		MaxPaths:    int32(q.MaxPaths),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]*linkModels.Path, len(rows))
+	for i, row := range rows {
+		steps := make([]*linkModels.PathStep, len(row.EntryIds))
+		for j, id := range row.EntryIds {
+			steps[j] = &linkModels.PathStep{EntryID: id, Title: row.Titles[j]}
+			if j > 0 {
+				steps[j].Relation = row.Relations[j-1]
+				steps[j].Reversed = row.Reversed[j-1]
+			}
+		}
+		paths[i] = &linkModels.Path{Steps: steps}
+	}
+	return paths, nil
+}
+
+func (r *repository) LinkGraph(ctx context.Context, userID string) (*linkModels.Graph, error) {
+	nodes, err := r.queries.ListGraphNodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.queries.ListGraphEdges(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &linkModels.Graph{
+		Nodes: make([]*linkModels.Node, len(nodes)),
+		Edges: make([]*linkModels.Link, len(edges)),
+	}
+	for i, n := range nodes {
+		graph.Nodes[i] = &linkModels.Node{EntryID: n.ID, Title: n.Title, GrowthStage: n.GrowthStage}
+	}
+	for i, e := range edges {
+		graph.Edges[i] = &linkModels.Link{SourceEntryID: e.SourceEntryID, TargetEntryID: e.TargetEntryID, UserID: userID}
+	}
+	return graph, nil
+}
+
+func (r *repository) LinkGraphFingerprint(ctx context.Context, userID string) (string, error) {
+	version, err := r.queries.GetLinkGraphVersion(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(version, 10), nil
+}
+
+func (r *repository) ExportGraph(ctx context.Context, userID string, q linkModels.ExportQuery) (*linkModels.Graph, error) {
+	var graph *linkModels.Graph
+	var err error
+	if q.RootEntryID != "" {
+		graph, err = r.Neighborhood(ctx, userID, linkModels.NeighborhoodQuery{
+			EntryID:     q.RootEntryID,
+			Depth:       q.Depth,
+			Direction:   q.Direction,
+			GrowthStage: q.GrowthStage,
+			Tags:        q.Tags,
+			MaxNodes:    q.MaxNodes,
+			MaxEdges:    q.MaxEdges,
+		})
+	} else {
+		graph, err = r.filteredGraph(ctx, userID, q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	graph.Edges = distinctLinks(graph.Edges)
+
+	ids := make([]string, len(graph.Nodes))
+	byID := make(map[string]*linkModels.Node, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		ids[i] = n.EntryID
+		byID[n.EntryID] = n
+		n.Tags = []string{}
+	}
+
+	tags, err := r.queries.ListEntryTagNames(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range tags {
+		if n, ok := byID[row.EntryID]; ok && row.Name.Valid {
+			n.Tags = append(n.Tags, row.Name.String)
+		}
+	}
+	for _, n := range graph.Nodes {
+		sort.Strings(n.Tags)
+	}
+
+	counts, err := r.queries.CountLinksByEntries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range counts {
+		if n, ok := byID[row.EntryID]; ok {
+			n.LinkCount, n.BacklinkCount = row.LinkCount, row.BacklinkCount
+		}
+	}
+	return graph, nil
+}
+
+// filteredGraph returns userID's live entries that pass the filters in q, in
+// order of ID, and the links between them.
+func (r *repository) filteredGraph(ctx context.Context, userID string, q linkModels.ExportQuery) (*linkModels.Graph, error) {
+	var tagged map[string]bool
+	if q.Tags != "" {
+		ids, err := r.matchTags(ctx, userID, q.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tagged = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			tagged[id] = true
+		}
+	}
+
+	rows, err := r.queries.ListGraphNodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	graph := &linkModels.Graph{Nodes: []*linkModels.Node{}}
+	ids := []string{}
+	for _, row := range rows {
+		if q.GrowthStage != "" && row.GrowthStage != q.GrowthStage {
+			continue
+		}
+		if tagged != nil && !tagged[row.ID] {
+			continue
+		}
+		if len(graph.Nodes) == q.MaxNodes {
+			graph.Truncated = true
+			break
+		}
+		ids = append(ids, row.ID)
+		graph.Nodes = append(graph.Nodes, &linkModels.Node{EntryID: row.ID, Title: row.Title, GrowthStage: row.GrowthStage})
+	}
+
+	links, err := r.queries.ListLinksAmong(ctx, db.ListLinksAmongParams{
+		EntryIds: ids,
+		MaxEdges: int32(q.MaxEdges + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(links) > q.MaxEdges {
+		links, graph.Truncated = links[:q.MaxEdges], true
+	}
+	graph.Edges = fromDBLinks(links)
+	return graph, nil
+}
+
+// distinctLinks drops links that repeat an earlier one's source, target and
+// relation, such as a manual link that is also written in the content.
+func distinctLinks(links []*linkModels.Link) []*linkModels.Link {
+	type key struct{ source, target, relation string }
+	seen := make(map[key]bool, len(links))
+	distinct := make([]*linkModels.Link, 0, len(links))
+	for _, l := range links {
+		k := key{l.SourceEntryID, l.TargetEntryID, l.Relation}
+		if !seen[k] {
+			seen[k] = true
+			distinct = append(distinct, l)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,307 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+	"moss/go/internal/wikilink"
+)
+
+// syncContentLinks makes e's content links match the wiki-links in its Markdown.
+// Title references that match none of the owner's entries are recorded as pending
+// links, or, if createPlaceholders is set, pointed at new seed entries created for
+// them. Unknown IDs and links from an entry to itself are skipped. Manual links
+// are left untouched.
+func syncContentLinks(ctx context.Context, q *db.Queries, e *models.Entry, createPlaceholders bool) error {
+	targets, unresolved, err := resolveWikiLinks(ctx, q, e.UserID, wikilink.Parse(e.Content))
+	if err != nil {
+		return err
+	}
+
+	if createPlaceholders {
+		for _, title := range unresolved {
+			id, err := createPlaceholder(ctx, q, e.UserID, title)
+			if err != nil {
+				return err
+			}
+			targets[id] = true
+		}
+		unresolved = nil
+	}
+
+	targetIDs := make([]string, 0, len(targets))
+	for id := range targets {
+		if id != e.ID {
+			targetIDs = append(targetIDs, id)
+		}
+	}
+
+	if err := q.DeleteStaleContentLinks(ctx, db.DeleteStaleContentLinksParams{
+		SourceEntryID:      e.ID,
+		KeepTargetEntryIds: targetIDs,
+	}); err != nil {
+		return err
+	}
+	if len(targetIDs) > 0 {
+		if err := q.CreateContentLinks(ctx, db.CreateContentLinksParams{
+			SourceEntryID:  e.ID,
+			UserID:         e.UserID,
+			TargetEntryIds: targetIDs,
+		}); err != nil {
+			return err
+		}
+	}
+
+	pendingKeys := make([]string, len(unresolved))
+	for i, title := range unresolved {
+		pendingKeys[i] = titleKey(title)
+	}
+	if err := q.DeleteStalePendingLinks(ctx, db.DeleteStalePendingLinksParams{
+		SourceEntryID:  e.ID,
+		KeepTargetKeys: pendingKeys,
+	}); err != nil {
+		return err
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+	return q.CreatePendingLinks(ctx, db.CreatePendingLinksParams{
+		SourceEntryID: e.ID,
+		UserID:        e.UserID,
+		TargetTitles:  unresolved,
+		TargetKeys:    pendingKeys,
+	})
+}
+
+// rebindPendingLinks turns other entries' pending links to e's title or aliases into content links to e.
+func rebindPendingLinks(ctx context.Context, q *db.Queries, e *models.Entry) error {
+	_, err := q.RebindPendingLinks(ctx, db.RebindPendingLinksParams{
+		UserID:        e.UserID,
+		TargetKeys:    titleKeys(append([]string{e.Title}, e.Aliases...)),
+		TargetEntryID: e.ID,
+	})
+	return err
+}
+
+// createPlaceholder creates an empty seed entry titled title and returns its ID.
+func createPlaceholder(ctx context.Context, q *db.Queries, userID string, title string) (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	entry, err := q.CreateEntry(ctx, db.CreateEntryParams{
+		ID:          id.String(),
+		UserID:      userID,
+		Title:       title,
+		GrowthStage: string(models.GrowthStageSeed),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Aliases:     []string{},
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := q.CreateEntryRevision(ctx, entry.ID); err != nil {
+		return "", err
+	}
+
+	if err := rebindPendingLinks(ctx, q, fromDBEntry(entry)); err != nil {
+		return "", err
+	}
+	return entry.ID, nil
+}
+
+// resolveWikiLinks returns the set of userID's entry IDs that refs point to, and
+// the titles that matched no entry (one spelling per title). Titles match
+// case-insensitively.
+func resolveWikiLinks(ctx context.Context, q *db.Queries, userID string, refs []wikilink.Ref) (map[string]bool, []string, error) {
+	var ids, titleKeys []string
+	titles := make(map[string]string) // title key -> first spelling seen
+	for _, ref := range refs {
+		if ref.ID != "" {
+			ids = append(ids, ref.ID)
+			continue
+		}
+		key := titleKey(ref.Title)
+		if _, ok := titles[key]; !ok {
+			titles[key] = ref.Title
+			titleKeys = append(titleKeys, key)
+		}
+	}
+
+	targets := make(map[string]bool)
+	if len(ids) > 0 {
+		owned, err := q.ListOwnedEntryIDs(ctx, db.ListOwnedEntryIDsParams{
+			UserID: userID,
+			Ids:    ids,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range owned {
+			targets[id] = true
+		}
+	}
+
+	var unresolved []string
+	if len(titleKeys) > 0 {
+		rows, err := q.ResolveEntryTitles(ctx, db.ResolveEntryTitlesParams{
+			UserID:    userID,
+			TitleKeys: titleKeys,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		resolved := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			targets[row.ID] = true
+			resolved[row.TitleKey] = true
+		}
+		for _, key := range titleKeys {
+			if !resolved[key] {
+				unresolved = append(unresolved, titles[key])
+			}
+		}
+	}
+	return targets, unresolved, nil
+}
+
+// titleKey is the case-insensitive form titles are matched by.
+func titleKey(title string) string {
+	return strings.ToLower(title)
+}
+
+func (r *repository) ListBacklinkedEntries(ctx context.Context, id string, limit, offset int) ([]*models.Entry, error) {
+	dbEntries, err := r.queries.ListBacklinkedEntries(ctx, db.ListBacklinkedEntriesParams{
+		TargetEntryID: id,
+		Limit:         int32(limit),
+		Offset:        int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromDBEntries(dbEntries), nil
+}
+
+func (r *repository) CountLinks(ctx context.Context, entries []*models.Entry) error {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	rows, err := r.queries.CountLinksByEntries(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]db.CountLinksByEntriesRow, len(rows))
+	for _, row := range rows {
+		counts[row.EntryID] = row
+	}
+	for _, e := range entries {
+		e.LinkCount, e.BacklinkCount = counts[e.ID].LinkCount, counts[e.ID].BacklinkCount
+	}
+	return nil
+}
+
+func (r *repository) ListMentioning(ctx context.Context, userID, id string, names []string, limit, offset int) ([]*models.Entry, error) {
+	dbEntries, err := r.queries.ListMentioningEntries(ctx, db.ListMentioningEntriesParams{
+		UserID:     userID,
+		EntryID:    id,
+		Names:      names,
+		MaxResults: int32(limit),
+		Skip:       int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromDBEntries(dbEntries), nil
+}
+
+func (r *repository) LinkMention(ctx context.Context, sourceID, targetID string, start, end int, version int64) (*models.Entry, error) {
+	var entry db.Entry
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		source, err := q.GetEntryByID(ctx, sourceID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEntryNotFound
+			}
+			return err
+		}
+		if source.DeletedAt.Valid {
+			return ErrEntryNotFound
+		}
+		if source.Version != version {
+			return ErrVersionConflict
+		}
+
+		// Link by name if that resolves to the target, which it does unless
+		// another entry has the same title; otherwise by ID, keeping the text.
+		text := source.Content[start:end]
+		resolved, err := q.ResolveEntryTitles(ctx, db.ResolveEntryTitlesParams{
+			UserID:    source.UserID,
+			TitleKeys: []string{titleKey(text)},
+		})
+		if err != nil {
+			return err
+		}
+		link := mentionLink(targetID, text, len(resolved) == 1 && resolved[0].ID == targetID)
+
+		entry, err = q.UpdateEntryFields(ctx, db.UpdateEntryFieldsParams{
+			ID:              sourceID,
+			Content:         sql.NullString{String: source.Content[:start] + link + source.Content[end:], Valid: true},
+			UpdatedAt:       time.Now().UTC(),
+			ExpectedVersion: sql.NullInt64{Int64: version, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrVersionConflict
+			}
+			return err
+		}
+		if _, err := q.CreateEntryRevision(ctx, entry.ID); err != nil {
+			return err
+		}
+		return syncContentLinks(ctx, q, fromDBEntry(entry), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromDBEntry(entry), nil
+}
+
+// mentionLink returns a [[wiki-link]] to targetID that reads as text, which
+// is the target's title or an alias: [[text]] if byName, else [[id:…|text]].
+// Text that would not parse back, such as a title containing '|', '#' or
+// "]]", falls back to the ID form, or to a bare [[id:…]] if even an alias
+// cannot hold it; that still displays as the target's title.
+func mentionLink(targetID, text string, byName bool) string {
+	if byName {
+		link := "[[" + text + "]]"
+		if ref, ok := parseWholeLink(link); ok && ref.Title == text && ref.Alias == "" {
+			return link
+		}
+	}
+	link := "[[id:" + targetID + "|" + text + "]]"
+	if ref, ok := parseWholeLink(link); ok && ref.ID == targetID && ref.Alias == text {
+		return link
+	}
+	return "[[id:" + targetID + "]]"
+}
+
+// parseWholeLink parses link as a single wiki-link spanning all of it.
+func parseWholeLink(link string) (wikilink.Ref, bool) {
+	refs := wikilink.Parse(link)
+	if len(refs) != 1 || refs[0].Start != 0 || refs[0].End != len(link) {
+		return wikilink.Ref{}, false
+	}
+	return refs[0], true
+}
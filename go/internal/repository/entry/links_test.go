@@ -0,0 +1,29 @@
+package entry
+
+import "testing"
+
+func TestMentionLink(t *testing.T) {
+	const id = "0190a1b2"
+	tests := []struct {
+		name   string
+		text   string
+		byName bool
+		want   string
+	}{
+		{"by name", "Moss", true, "[[Moss]]"},
+		{"by id", "Moss", false, "[[id:0190a1b2|Moss]]"},
+		{"pipe", "Rock|Paper", true, "[[id:0190a1b2|Rock|Paper]]"},
+		{"hash", "C#", true, "[[id:0190a1b2|C#]]"},
+		{"id prefix", "id:Moss", true, "[[id:0190a1b2|id:Moss]]"},
+		{"closing brackets", "Moss]]", true, "[[id:0190a1b2]]"},
+		{"opening brackets", "[[Moss", true, "[[id:0190a1b2]]"},
+		{"closing brackets by id", "a]]b", false, "[[id:0190a1b2]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mentionLink(id, tt.text, tt.byName); got != tt.want {
+				t.Errorf("mentionLink(%q, %v) = %q, want %q", tt.text, tt.byName, got, tt.want)
+			}
+		})
+	}
+}
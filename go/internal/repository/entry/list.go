@@ -0,0 +1,168 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+// ListPage returns up to q.Limit entries after q.After in the requested order.
+// One extra row is fetched to tell whether another page follows.
+func (r *repository) ListPage(ctx context.Context, userID string, q models.ListQuery) (*models.EntryPage, error) {
+	f := q.Filter
+	after := q.After
+	if after == nil {
+		after = &models.Cursor{}
+	}
+	limit := int32(q.Limit + 1)
+
+	var tagged []string
+	if f.Tags != "" {
+		var err error
+		tagged, err = r.matchTags(ctx, userID, f.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var entries []*models.Entry
+	var linkCounts []int64
+
+	switch q.SortBy {
+	case models.SortByCreated, "":
+		rows, err := r.queries.ListEntriesPageByCreated(ctx, db.ListEntriesPageByCreatedParams{
+			UserID:          userID,
+			GrowthStage:     toNullString(string(f.GrowthStage)),
+			CreatedAfter:    toNullTime(f.CreatedAfter),
+			CreatedBefore:   toNullTime(f.CreatedBefore),
+			UpdatedAfter:    toNullTime(f.UpdatedAfter),
+			UpdatedBefore:   toNullTime(f.UpdatedBefore),
+			TitlePrefix:     f.TitlePrefix,
+			FilterByIds:     f.Tags != "",
+			EntryIds:        tagged,
+			CursorCreatedAt: sql.NullTime{Time: after.CreatedAt, Valid: q.After != nil},
+			Descending:      q.Descending,
+			CursorID:        after.ID,
+			PageLimit:       limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = fromDBEntries(rows)
+
+	case models.SortByUpdated:
+		rows, err := r.queries.ListEntriesPageByUpdated(ctx, db.ListEntriesPageByUpdatedParams{
+			UserID:          userID,
+			GrowthStage:     toNullString(string(f.GrowthStage)),
+			CreatedAfter:    toNullTime(f.CreatedAfter),
+			CreatedBefore:   toNullTime(f.CreatedBefore),
+			UpdatedAfter:    toNullTime(f.UpdatedAfter),
+			UpdatedBefore:   toNullTime(f.UpdatedBefore),
+			TitlePrefix:     f.TitlePrefix,
+			FilterByIds:     f.Tags != "",
+			EntryIds:        tagged,
+			CursorUpdatedAt: sql.NullTime{Time: after.UpdatedAt, Valid: q.After != nil},
+			Descending:      q.Descending,
+			CursorID:        after.ID,
+			PageLimit:       limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = fromDBEntries(rows)
+
+	case models.SortByTitle:
+		rows, err := r.queries.ListEntriesPageByTitle(ctx, db.ListEntriesPageByTitleParams{
+			UserID:        userID,
+			GrowthStage:   toNullString(string(f.GrowthStage)),
+			CreatedAfter:  toNullTime(f.CreatedAfter),
+			CreatedBefore: toNullTime(f.CreatedBefore),
+			UpdatedAfter:  toNullTime(f.UpdatedAfter),
+			UpdatedBefore: toNullTime(f.UpdatedBefore),
+			TitlePrefix:   f.TitlePrefix,
+			FilterByIds:   f.Tags != "",
+			EntryIds:      tagged,
+			CursorTitle:   sql.NullString{String: after.Title, Valid: q.After != nil},
+			Descending:    q.Descending,
+			CursorID:      after.ID,
+			PageLimit:     limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = fromDBEntries(rows)
+
+	case models.SortByLinkCount:
+		rows, err := r.queries.ListEntriesPageByLinkCount(ctx, db.ListEntriesPageByLinkCountParams{
+			UserID:          userID,
+			GrowthStage:     toNullString(string(f.GrowthStage)),
+			CreatedAfter:    toNullTime(f.CreatedAfter),
+			CreatedBefore:   toNullTime(f.CreatedBefore),
+			UpdatedAfter:    toNullTime(f.UpdatedAfter),
+			UpdatedBefore:   toNullTime(f.UpdatedBefore),
+			TitlePrefix:     f.TitlePrefix,
+			FilterByIds:     f.Tags != "",
+			EntryIds:        tagged,
+			CursorLinkCount: sql.NullInt64{Int64: after.LinkCount, Valid: q.After != nil},
+			Descending:      q.Descending,
+			CursorID:        after.ID,
+			PageLimit:       limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = make([]*models.Entry, len(rows))
+		linkCounts = make([]int64, len(rows))
+		for i, row := range rows {
+			entries[i] = fromDBEntry(db.Entry{
+				ID:          row.ID,
+				UserID:      row.UserID,
+				Title:       row.Title,
+				Content:     row.Content,
+				GrowthStage: row.GrowthStage,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Version:     row.Version,
+				DeletedAt:   row.DeletedAt,
+				Aliases:     row.Aliases,
+			})
+			linkCounts[i] = row.LinkCount
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown sort field %q", q.SortBy)
+	}
+
+	page := &models.EntryPage{Entries: entries}
+	if len(entries) > q.Limit {
+		page.Entries = entries[:q.Limit]
+		last := page.Entries[q.Limit-1]
+		page.Next = &models.Cursor{ID: last.ID}
+		switch q.SortBy {
+		case models.SortByCreated, "":
+			page.Next.CreatedAt = last.CreatedAt
+		case models.SortByUpdated:
+			page.Next.UpdatedAt = last.UpdatedAt
+		case models.SortByTitle:
+			page.Next.Title = last.Title
+		case models.SortByLinkCount:
+			page.Next.LinkCount = linkCounts[q.Limit-1]
+		}
+	}
+	return page, nil
+}
+
+func toNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
@@ -8,43 +8,183 @@ import (
 	"time"
 
 	models "moss/go/internal/models/entry"
+	linkModels "moss/go/internal/models/link"
 )
 
-var ErrEntryNotFound = errors.New("entry not found")
+var (
+	ErrEntryNotFound = errors.New("entry not found")
+	ErrEntryExists   = errors.New("entry already exists")
+	// ErrVersionConflict means Update was given an expected version that is no longer current.
+	ErrVersionConflict = errors.New("entry version conflict")
+)
+
+// idempotencyKeyTTL is how long a CreateEntry idempotency key replays the original entry.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// errIdempotentReplay aborts the create transaction when the idempotency key was already used.
+var errIdempotentReplay = errors.New("idempotency key already used")
 
+// Create and Update record a new revision of e and keep its content links (see
+// syncContentLinks) and tags (see syncTags) in step with the wiki-links and #tags
+// in its Markdown, within the same transaction as the write. They also rebind
+// other entries' pending links to e's title and aliases, and fail with a
+// *NameTakenError if one of those is already another entry's name (see syncAliases).
 type Repository interface {
-	Create(ctx context.Context, e *models.Entry) (*models.Entry, error)
+	// Create inserts e. If idempotencyKey is non-empty and was already used by
+	// the same user within idempotencyKeyTTL, the originally created entry is
+	// returned instead. If e.ID is already taken, ErrEntryExists is returned.
+	Create(ctx context.Context, e *models.Entry, idempotencyKey string, createPlaceholders bool) (*models.Entry, error)
+	// GetByID returns ErrEntryNotFound for entries in the trash; see GetTrashedByID.
 	GetByID(ctx context.Context, id string) (*models.Entry, error)
 	ListByUser(ctx context.Context, userID string) ([]*models.Entry, error)
 	ListByUserSince(ctx context.Context, userID string, since time.Time) ([]*models.Entry, error)
-	Update(ctx context.Context, e *models.Entry) (*models.Entry, error)
-	Delete(ctx context.Context, id string) error
+	// ListPage returns one page of userID's live entries. q.Filter.Tags must be a valid tag expression.
+	ListPage(ctx context.Context, userID string, q models.ListQuery) (*models.EntryPage, error)
+	// Search runs a full-text search over userID's live entries. q.Text must contain
+	// at least one word and q.Tags, if set, must be a valid tag expression.
+	Search(ctx context.Context, userID string, q models.SearchQuery) (*models.SearchPage, error)
+	// Suggest returns up to limit of userID's live entries whose titles or aliases
+	// closely match text, even if misspelled or partly typed, best first.
+	Suggest(ctx context.Context, userID string, text string, limit int) ([]*models.Suggestion, error)
+	// Update overwrites e. If e.Version is non-zero, the write only succeeds while it is
+	// still the stored version; otherwise ErrVersionConflict is returned.
+	// If transition is non-nil it is recorded with the write, and its ID and
+	// CreatedAt are filled in; the caller must have checked that it is allowed
+	// and set e.Version so that it applies to the stored stage.
+	Update(ctx context.Context, e *models.Entry, createPlaceholders bool, transition *models.StageTransition) (*models.Entry, error)
+	// Patch updates only the fields set in p, checking p.Version and recording
+	// transition like Update.
+	Patch(ctx context.Context, id string, p models.Patch, createPlaceholders bool, transition *models.StageTransition) (*models.Entry, error)
+	// ListStageTransitions returns an entry's growth stage changes, newest first.
+	ListStageTransitions(ctx context.Context, entryID string) ([]*models.StageTransition, error)
+	// Trash soft-deletes an entry and applies policy to its links, returning the
+	// links it removed. The entry's title and aliases are free for other entries
+	// until it is restored. With DeletionPolicyKeep the links are kept but hidden until
+	// the entry is restored or purged. DeletionPolicyReject must be checked by the
+	// caller (see ListBacklinks); here it behaves like DeletionPolicyKeep.
+	Trash(ctx context.Context, id string, policy models.DeletionPolicy) ([]*linkModels.Link, error)
+	// ListBacklinks returns the links into an entry from other live entries.
+	ListBacklinks(ctx context.Context, id string) ([]*linkModels.Link, error)
+	// ListBacklinkedEntries returns the other live entries linking to an entry,
+	// oldest first, skipping the first offset.
+	ListBacklinkedEntries(ctx context.Context, id string, limit, offset int) ([]*models.Entry, error)
+	// CountLinks sets the LinkCount and BacklinkCount of entries, with one query for all of them.
+	CountLinks(ctx context.Context, entries []*models.Entry) error
+	// ListMentioning returns userID's other live entries that do not link to an
+	// entry but whose content contains one of names, most recently updated first,
+	// skipping the first offset. The names may occur inside words or code.
+	ListMentioning(ctx context.Context, userID, id string, names []string, limit, offset int) ([]*models.Entry, error)
+	// LinkMention turns sourceID's content[start:end] into a wiki-link to targetID
+	// and links the entries, provided the source is still at version.
+	LinkMention(ctx context.Context, sourceID, targetID string, start, end int, version int64) (*models.Entry, error)
+	// Neighborhood walks userID's link graph out from q.EntryID. The limits in q
+	// must be set; nodes beyond them are dropped farthest first.
+	Neighborhood(ctx context.Context, userID string, q linkModels.NeighborhoodQuery) (*linkModels.Graph, error)
+	// FindPaths returns up to q.MaxPaths simple paths between two of userID's
+	// entries, shortest first. The search can be costly; bound it with ctx.
+	FindPaths(ctx context.Context, userID string, q linkModels.PathQuery) ([]*linkModels.Path, error)
+	// LinkGraph returns all of userID's live entries and each linked pair of them
+	// once, as Links with only the entry and user IDs set.
+	LinkGraph(ctx context.Context, userID string) (*linkModels.Graph, error)
+	// LinkGraphFingerprint returns a string that changes whenever userID's link
+	// graph, as returned by LinkGraph, may have changed.
+	LinkGraphFingerprint(ctx context.Context, userID string) (string, error)
+	// ExportGraph returns the part of userID's link graph that q selects, with
+	// each node's tags and link counts and each distinct link once. The limits
+	// in q must be set.
+	ExportGraph(ctx context.Context, userID string, q linkModels.ExportQuery) (*linkModels.Graph, error)
+	GetTrashedByID(ctx context.Context, id string) (*models.Entry, error)
+	ListTrash(ctx context.Context, userID string) ([]*models.Entry, error)
+	// Restore takes an entry out of the trash, failing with a *NameTakenError if
+	// another entry has meanwhile taken one of its names.
+	Restore(ctx context.Context, id string) (*models.Entry, error)
+	// Purge permanently deletes a trashed entry together with its links and revisions.
+	// Live entries whose [[wiki-links]] named it keep them as pending links.
+	Purge(ctx context.Context, id string) error
+	// PurgeTrashedBefore purges every entry trashed before cutoff and returns how many there were.
+	PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	// Retag rewrites the #tags named in from, and the tags nested under them, to
+	// to in all of userID's live entries, merging them if to is already in use.
+	// It returns how many entries were rewritten.
+	Retag(ctx context.Context, userID string, from []string, to string) (int64, error)
+	// ListRevisions returns an entry's revisions, newest first.
+	ListRevisions(ctx context.Context, entryID string) ([]*models.Revision, error)
+	GetRevision(ctx context.Context, entryID string, number int32) (*models.Revision, error)
 }
 
 type repository struct {
+	conn    *sql.DB
 	queries *db.Queries
 }
 
 func NewRepository(dbConn *sql.DB) Repository {
 	return &repository{
+		conn:    dbConn,
 		queries: db.New(dbConn),
 	}
 }
 
-func (r *repository) Create(ctx context.Context, e *models.Entry) (*models.Entry, error) {
+func (r *repository) Create(ctx context.Context, e *models.Entry, idempotencyKey string, createPlaceholders bool) (*models.Entry, error) {
 	now := time.Now().UTC()
 	e.CreatedAt = now
 	e.UpdatedAt = now
+	e.Aliases = models.NormalizeAliases(e.Aliases)
+
+	var entry db.Entry
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		var err error
+		entry, err = q.CreateEntry(ctx, db.CreateEntryParams{
+			ID:          e.ID,
+			UserID:      e.UserID,
+			Title:       e.Title,
+			Content:     e.Content,
+			GrowthStage: string(e.GrowthStage),
+			CreatedAt:   e.CreatedAt,
+			UpdatedAt:   e.UpdatedAt,
+			Aliases:     e.Aliases,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEntryExists
+			}
+			return err
+		}
+
+		if idempotencyKey != "" {
+			_, err = q.ClaimIdempotencyKey(ctx, db.ClaimIdempotencyKeyParams{
+				UserID:         e.UserID,
+				IdempotencyKey: idempotencyKey,
+				EntryID:        entry.ID,
+				CreatedAt:      now,
+				ExpiredBefore:  now.Add(-idempotencyKeyTTL),
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return errIdempotentReplay
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := q.CreateEntryRevision(ctx, entry.ID); err != nil {
+			return err
+		}
 
-	entry, err := r.queries.CreateEntry(ctx, db.CreateEntryParams{
-		ID:          e.ID,
-		UserID:      e.UserID,
-		Title:       e.Title,
-		Content:     e.Content,
-		GrowthStage: string(e.GrowthStage),
-		CreatedAt:   e.CreatedAt,
-		UpdatedAt:   e.UpdatedAt,
+		saved := fromDBEntry(entry)
+		if err := syncAliases(ctx, q, saved); err != nil {
+			return err
+		}
+		if err := rebindPendingLinks(ctx, q, saved); err != nil {
+			return err
+		}
+		if err := syncTags(ctx, q, saved); err != nil {
+			return err
+		}
+		return syncContentLinks(ctx, q, saved, createPlaceholders)
 	})
+	if errors.Is(err, errIdempotentReplay) {
+		return r.getByIdempotencyKey(ctx, e.UserID, idempotencyKey)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +192,21 @@ func (r *repository) Create(ctx context.Context, e *models.Entry) (*models.Entry
 	return fromDBEntry(entry), nil
 }
 
+func (r *repository) getByIdempotencyKey(ctx context.Context, userID string, key string) (*models.Entry, error) {
+	entryID, err := r.queries.GetIdempotencyKeyEntryID(ctx, db.GetIdempotencyKeyEntryIDParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+
+	return r.GetByID(ctx, entryID)
+}
+
 func (r *repository) GetByID(ctx context.Context, id string) (*models.Entry, error) {
 	dbEntry, err := r.queries.GetEntryByID(ctx, id)
 	if err != nil {
@@ -60,6 +215,9 @@ func (r *repository) GetByID(ctx context.Context, id string) (*models.Entry, err
 		}
 		return nil, err
 	}
+	if dbEntry.DeletedAt.Valid {
+		return nil, ErrEntryNotFound
+	}
 
 	return fromDBEntry(dbEntry), nil
 }
@@ -85,46 +243,155 @@ func (r *repository) ListByUserSince(ctx context.Context, userID string, since t
 	return fromDBEntries(dbEntries), nil
 }
 
-func (r *repository) Update(ctx context.Context, e *models.Entry) (*models.Entry, error) {
+func (r *repository) Update(ctx context.Context, e *models.Entry, createPlaceholders bool, transition *models.StageTransition) (*models.Entry, error) {
 	e.UpdatedAt = time.Now().UTC()
+	e.Aliases = models.NormalizeAliases(e.Aliases)
+
+	var entry db.Entry
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		var err error
+		entry, err = q.UpdateEntry(ctx, db.UpdateEntryParams{
+			ID:              e.ID,
+			Title:           e.Title,
+			Content:         e.Content,
+			GrowthStage:     string(e.GrowthStage),
+			UpdatedAt:       e.UpdatedAt,
+			Aliases:         e.Aliases,
+			ExpectedVersion: sql.NullInt64{Int64: e.Version, Valid: e.Version != 0},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				if current, getErr := q.GetEntryByID(ctx, e.ID); getErr == nil && !current.DeletedAt.Valid {
+					return ErrVersionConflict
+				}
+				return ErrEntryNotFound
+			}
+			return err
+		}
+
+		if _, err := q.CreateEntryRevision(ctx, entry.ID); err != nil {
+			return err
+		}
+		if err := recordTransition(ctx, q, transition, entry.UpdatedAt); err != nil {
+			return err
+		}
 
-	entry, err := r.queries.UpdateEntry(ctx, db.UpdateEntryParams{
-		ID:          e.ID,
-		Title:       e.Title,
-		Content:     e.Content,
-		GrowthStage: string(e.GrowthStage),
-		UpdatedAt:   e.UpdatedAt,
+		saved := fromDBEntry(entry)
+		if err := syncAliases(ctx, q, saved); err != nil {
+			return err
+		}
+		if err := rebindPendingLinks(ctx, q, saved); err != nil {
+			return err
+		}
+		if err := syncTags(ctx, q, saved); err != nil {
+			return err
+		}
+		return syncContentLinks(ctx, q, saved, createPlaceholders)
 	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrEntryNotFound
+		return nil, err
+	}
+
+	return fromDBEntry(entry), nil
+}
+
+func (r *repository) Patch(ctx context.Context, id string, p models.Patch, createPlaceholders bool, transition *models.StageTransition) (*models.Entry, error) {
+	params := db.UpdateEntryFieldsParams{
+		ID:              id,
+		UpdatedAt:       time.Now().UTC(),
+		ExpectedVersion: sql.NullInt64{Int64: p.Version, Valid: p.Version != 0},
+	}
+	if p.Title != nil {
+		params.Title = sql.NullString{String: *p.Title, Valid: true}
+	}
+	if p.Content != nil {
+		params.Content = sql.NullString{String: *p.Content, Valid: true}
+	}
+	if p.GrowthStage != nil {
+		params.GrowthStage = sql.NullString{String: string(*p.GrowthStage), Valid: true}
+	}
+	if p.Aliases != nil {
+		params.Aliases = models.NormalizeAliases(*p.Aliases)
+	}
+
+	var entry db.Entry
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		var err error
+		entry, err = q.UpdateEntryFields(ctx, params)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				if current, getErr := q.GetEntryByID(ctx, id); getErr == nil && !current.DeletedAt.Valid {
+					return ErrVersionConflict
+				}
+				return ErrEntryNotFound
+			}
+			return err
+		}
+
+		if _, err := q.CreateEntryRevision(ctx, entry.ID); err != nil {
+			return err
+		}
+		if err := recordTransition(ctx, q, transition, entry.UpdatedAt); err != nil {
+			return err
 		}
+
+		// Names, links and tags only depend on the title, aliases and content;
+		// skip the work for those that did not change.
+		saved := fromDBEntry(entry)
+		if p.Title != nil || p.Aliases != nil {
+			if err := syncAliases(ctx, q, saved); err != nil {
+				return err
+			}
+			if err := rebindPendingLinks(ctx, q, saved); err != nil {
+				return err
+			}
+		}
+		if p.Content != nil {
+			if err := syncTags(ctx, q, saved); err != nil {
+				return err
+			}
+			return syncContentLinks(ctx, q, saved, createPlaceholders)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return fromDBEntry(entry), nil
 }
 
-func (r *repository) Delete(ctx context.Context, id string) error {
-	err := r.queries.DeleteEntry(ctx, id)
+// withTx runs fn inside a transaction, committing if fn returns nil and rolling back otherwise.
+func (r *repository) withTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrEntryNotFound
-		}
 		return err
 	}
-	return nil
+	defer tx.Rollback()
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func fromDBEntry(dbEntry db.Entry) *models.Entry {
+	var deletedAt *time.Time
+	if dbEntry.DeletedAt.Valid {
+		deletedAt = &dbEntry.DeletedAt.Time
+	}
+
 	return &models.Entry{
 		ID:          dbEntry.ID,
 		UserID:      dbEntry.UserID,
 		Title:       dbEntry.Title,
 		Content:     dbEntry.Content,
-		GrowthStage: models.GrowthStage(dbEntry.GrowthStage),
+		GrowthStage: toGrowthStage(dbEntry.GrowthStage),
 		CreatedAt:   dbEntry.CreatedAt,
 		UpdatedAt:   dbEntry.UpdatedAt,
+		Version:     dbEntry.Version,
+		DeletedAt:   deletedAt,
+		Aliases:     dbEntry.Aliases,
 	}
 }
 
@@ -135,3 +402,18 @@ func fromDBEntries(dbEntries []db.Entry) []*models.Entry {
 	}
 	return entries
 }
+
+func fromDBLinks(dbLinks []db.EntryLink) []*linkModels.Link {
+	links := make([]*linkModels.Link, len(dbLinks))
+	for i, l := range dbLinks {
+		links[i] = &linkModels.Link{
+			SourceEntryID: l.SourceEntryID,
+			TargetEntryID: l.TargetEntryID,
+			UserID:        l.UserID,
+			CreatedAt:     l.CreatedAt,
+			Origin:        linkModels.Origin(l.Origin),
+			Relation:      l.Relation,
+		}
+	}
+	return links
+}
@@ -0,0 +1,51 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+func (r *repository) ListRevisions(ctx context.Context, entryID string) ([]*models.Revision, error) {
+	dbRevisions, err := r.queries.ListEntryRevisions(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*models.Revision, len(dbRevisions))
+	for i, dbRevision := range dbRevisions {
+		revisions[i] = fromDBRevision(dbRevision)
+	}
+	return revisions, nil
+}
+
+func (r *repository) GetRevision(ctx context.Context, entryID string, number int32) (*models.Revision, error) {
+	dbRevision, err := r.queries.GetEntryRevision(ctx, db.GetEntryRevisionParams{
+		EntryID:  entryID,
+		Revision: number,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return fromDBRevision(dbRevision), nil
+}
+
+func fromDBRevision(dbRevision db.EntryRevision) *models.Revision {
+	return &models.Revision{
+		EntryID:     dbRevision.EntryID,
+		Number:      dbRevision.Revision,
+		Title:       dbRevision.Title,
+		Content:     dbRevision.Content,
+		GrowthStage: toGrowthStage(dbRevision.GrowthStage),
+		CreatedAt:   dbRevision.CreatedAt,
+	}
+}
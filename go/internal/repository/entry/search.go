@@ -0,0 +1,89 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+	"moss/go/internal/tsquery"
+)
+
+// Highlighted matches are delimited with control characters, which cannot
+// occur in ts_headline's output otherwise, so the rest can be HTML-escaped.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+var highlightOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// Search returns up to q.Limit results after q.After, best match first.
+// One extra row is fetched to tell whether another page follows.
+func (r *repository) Search(ctx context.Context, userID string, q models.SearchQuery) (*models.SearchPage, error) {
+	var tagged []string
+	if q.Tags != "" {
+		var err error
+		tagged, err = r.matchTags(ctx, userID, q.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+	after := q.After
+	if after == nil {
+		after = &models.SearchCursor{}
+	}
+
+	rows, err := r.queries.SearchEntries(ctx, db.SearchEntriesParams{
+		Query:           tsquery.FromSearch(q.Text),
+		HeadlineOptions: highlightOptions,
+		UserID:          userID,
+		GrowthStage:     toNullString(string(q.GrowthStage)),
+		FilterByIds:     q.Tags != "",
+		EntryIds:        tagged,
+		CursorRank:      sql.NullFloat64{Float64: float64(after.Rank), Valid: q.After != nil},
+		CursorID:        after.ID,
+		PageLimit:       int32(q.Limit + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = &models.SearchResult{
+			Entry: fromDBEntry(db.Entry{
+				ID:          row.ID,
+				UserID:      row.UserID,
+				Title:       row.Title,
+				Content:     row.Content,
+				GrowthStage: row.GrowthStage,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Version:     row.Version,
+				DeletedAt:   row.DeletedAt,
+				Aliases:     row.Aliases,
+			}),
+			Rank:           row.Rank,
+			TitleHighlight: highlight(row.TitleHighlight),
+			Snippet:        highlight(row.Snippet),
+		}
+	}
+
+	page := &models.SearchPage{Results: results}
+	if len(results) > q.Limit {
+		page.Results = results[:q.Limit]
+		last := page.Results[q.Limit-1]
+		page.Next = &models.SearchCursor{Rank: last.Rank, ID: last.Entry.ID}
+	}
+	return page, nil
+}
+
+// highlight HTML-escapes a ts_headline result and turns its match delimiters into <mark> tags.
+func highlight(s string) string {
+	return highlightReplacer.Replace(html.EscapeString(s))
+}
@@ -0,0 +1,66 @@
+package entry
+
+import (
+	"context"
+	"time"
+
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+func (r *repository) ListStageTransitions(ctx context.Context, entryID string) ([]*models.StageTransition, error) {
+	dbTransitions, err := r.queries.ListStageTransitions(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions := make([]*models.StageTransition, len(dbTransitions))
+	for i, dbTransition := range dbTransitions {
+		transitions[i] = fromDBStageTransition(dbTransition)
+	}
+	return transitions, nil
+}
+
+// recordTransition stores t, made at the given time, and fills in its ID and
+// CreatedAt. A nil t records nothing.
+func recordTransition(ctx context.Context, q *db.Queries, t *models.StageTransition, at time.Time) error {
+	if t == nil {
+		return nil
+	}
+	saved, err := q.CreateStageTransition(ctx, db.CreateStageTransitionParams{
+		EntryID:   t.EntryID,
+		UserID:    t.UserID,
+		TokenID:   toNullString(t.TokenID),
+		FromStage: string(t.From),
+		ToStage:   string(t.To),
+		Reason:    t.Reason,
+		CreatedAt: at,
+	})
+	if err != nil {
+		return err
+	}
+	t.ID, t.CreatedAt = saved.ID, saved.CreatedAt
+	return nil
+}
+
+func fromDBStageTransition(dbTransition db.StageTransition) *models.StageTransition {
+	return &models.StageTransition{
+		ID:        dbTransition.ID,
+		EntryID:   dbTransition.EntryID,
+		UserID:    dbTransition.UserID,
+		TokenID:   dbTransition.TokenID.String,
+		From:      toGrowthStage(dbTransition.FromStage),
+		To:        toGrowthStage(dbTransition.ToStage),
+		Reason:    dbTransition.Reason,
+		CreatedAt: dbTransition.CreatedAt,
+	}
+}
+
+// toGrowthStage converts a stored growth stage. Entries written before stages
+// were validated may have them in upper case.
+func toGrowthStage(s string) models.GrowthStage {
+	if stage, ok := models.ParseGrowthStage(s); ok {
+		return stage
+	}
+	return models.GrowthStage(s)
+}
@@ -0,0 +1,47 @@
+package entry
+
+import (
+	"context"
+	"time"
+
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+// suggestCandidates is how many of the closest title matches Suggest re-ranks.
+const suggestCandidates = 100
+
+func (r *repository) Suggest(ctx context.Context, userID string, text string, limit int) ([]*models.Suggestion, error) {
+	rows, err := r.queries.SuggestEntries(ctx, db.SuggestEntriesParams{
+		Now:           time.Now().UTC(),
+		Query:         text,
+		UserID:        userID,
+		MaxCandidates: int32(max(limit, suggestCandidates)),
+		MaxResults:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*models.Suggestion, len(rows))
+	for i, row := range rows {
+		suggestions[i] = &models.Suggestion{
+			Entry: fromDBEntry(db.Entry{
+				ID:          row.ID,
+				UserID:      row.UserID,
+				Title:       row.Title,
+				Content:     row.Content,
+				GrowthStage: row.GrowthStage,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Version:     row.Version,
+				DeletedAt:   row.DeletedAt,
+				Aliases:     row.Aliases,
+			}),
+			Score:         row.Score,
+			Similarity:    row.Similarity,
+			BacklinkCount: row.LinkCount,
+		}
+	}
+	return suggestions, nil
+}
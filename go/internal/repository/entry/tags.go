@@ -0,0 +1,158 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"moss/go/internal/hashtag"
+	models "moss/go/internal/models/entry"
+	db "moss/go/internal/repository/db/sqlc"
+	"moss/go/internal/tagexpr"
+)
+
+// syncTags makes e's tags match the #tags in its Markdown, creating missing
+// tags and their ancestors, and deleting tags no entry uses any more.
+func syncTags(ctx context.Context, q *db.Queries, e *models.Entry) error {
+	names := hashtag.Names(e.Content)
+
+	removed, err := q.DeleteStaleEntryTags(ctx, db.DeleteStaleEntryTagsParams{
+		EntryID:   e.ID,
+		KeepNames: names,
+	})
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		if err := q.DeleteUnusedTags(ctx, e.UserID); err != nil {
+			return err
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	var all []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		for _, n := range append(hashtag.Ancestors(name), name) {
+			if !seen[n] {
+				seen[n] = true
+				all = append(all, n)
+			}
+		}
+	}
+	ids := make([]string, len(all))
+	for i := range all {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		ids[i] = id.String()
+	}
+
+	if err := q.CreateTags(ctx, db.CreateTagsParams{
+		UserID: e.UserID,
+		Ids:    ids,
+		Names:  all,
+	}); err != nil {
+		return err
+	}
+	return q.CreateEntryTags(ctx, db.CreateEntryTagsParams{
+		EntryID: e.ID,
+		UserID:  e.UserID,
+		Names:   names,
+	})
+}
+
+// matchTags returns the IDs of userID's live entries whose tags satisfy the tag expression expr.
+func (r *repository) matchTags(ctx context.Context, userID string, expr string) ([]string, error) {
+	parsed, err := tagexpr.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.queries.ListEntryTagNames(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var order []string
+	tags := make(map[string][]string)
+	for _, row := range rows {
+		if _, ok := tags[row.EntryID]; !ok {
+			order = append(order, row.EntryID)
+			tags[row.EntryID] = nil
+		}
+		if row.Name.Valid {
+			tags[row.EntryID] = append(tags[row.EntryID], row.Name.String)
+		}
+	}
+
+	ids := []string{}
+	for _, id := range order {
+		has := func(tag string) bool {
+			for _, name := range tags[id] {
+				if hashtag.Within(name, tag) {
+					return true
+				}
+			}
+			return false
+		}
+		if parsed.Match(has) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *repository) Retag(ctx context.Context, userID string, from []string, to string) (int64, error) {
+	var retagged int64
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		entries, err := q.ListEntriesByTags(ctx, db.ListEntriesByTagsParams{
+			UserID: userID,
+			Names:  from,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			content := hashtag.Rewrite(entry.Content, func(ref hashtag.Ref) (string, bool) {
+				// The longest matching name wins, so "lang/go" beats "lang" for #lang/go/generics.
+				match := ""
+				for _, name := range from {
+					if hashtag.Within(ref.Name, name) && len(name) > len(match) {
+						match = name
+					}
+				}
+				if match == "" {
+					return "", false
+				}
+				return "#" + to + ref.Name[len(match):], true
+			})
+			if content == entry.Content {
+				continue
+			}
+
+			updated, err := q.UpdateEntryFields(ctx, db.UpdateEntryFieldsParams{
+				ID:        entry.ID,
+				Content:   sql.NullString{String: content, Valid: true},
+				UpdatedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := q.CreateEntryRevision(ctx, updated.ID); err != nil {
+				return err
+			}
+			if err := syncTags(ctx, q, fromDBEntry(updated)); err != nil {
+				return err
+			}
+			retagged++
+		}
+		return q.DeleteUnusedTags(ctx, userID)
+	})
+	return retagged, err
+}
@@ -0,0 +1,283 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "moss/go/internal/models/entry"
+	linkModels "moss/go/internal/models/link"
+	db "moss/go/internal/repository/db/sqlc"
+	"moss/go/internal/wikilink"
+)
+
+func (r *repository) Trash(ctx context.Context, id string, policy models.DeletionPolicy) ([]*linkModels.Link, error) {
+	var removed []db.EntryLink
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		n, err := q.TrashEntry(ctx, db.TrashEntryParams{
+			ID:        id,
+			DeletedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrEntryNotFound
+		}
+		// Free the entry's aliases for other entries while it is in the trash.
+		// They are kept in entries.aliases and claimed again on restore.
+		if err := q.DeleteEntryAliases(ctx, id); err != nil {
+			return err
+		}
+
+		if policy != models.DeletionPolicyCascade && policy != models.DeletionPolicyRewrite {
+			return nil
+		}
+		removed, err = q.DeleteLinksByEntry(ctx, id)
+		if err != nil {
+			return err
+		}
+		if policy == models.DeletionPolicyRewrite {
+			return unlinkWikiLinks(ctx, q, id, removed)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDBLinks(removed), nil
+}
+
+// unlinkWikiLinks rewrites the [[wiki-links]] to entry id in the sources of
+// removed content links as plain text: the alias if there is one, else the title.
+// A link may name the entry by its title or by any of its aliases.
+// Sources in the trash are left alone.
+func unlinkWikiLinks(ctx context.Context, q *db.Queries, id string, removed []db.EntryLink) error {
+	target, err := q.GetEntryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	keys := map[string]bool{titleKey(target.Title): true}
+	for _, alias := range target.Aliases {
+		keys[titleKey(alias)] = true
+	}
+
+	rewritten := make(map[string]bool)
+	for _, link := range removed {
+		if link.TargetEntryID != id || link.SourceEntryID == id ||
+			link.Origin != string(linkModels.OriginContent) || rewritten[link.SourceEntryID] {
+			continue
+		}
+		rewritten[link.SourceEntryID] = true
+
+		source, err := q.GetEntryByID(ctx, link.SourceEntryID)
+		if err != nil {
+			return err
+		}
+		if source.DeletedAt.Valid {
+			continue
+		}
+
+		content := wikilink.Rewrite(source.Content, func(ref wikilink.Ref) (string, bool) {
+			if ref.ID != id && (ref.Title == "" || !keys[titleKey(ref.Title)]) {
+				return "", false
+			}
+			if ref.Alias != "" {
+				return ref.Alias, true
+			}
+			if ref.Title != "" {
+				return ref.Title, true
+			}
+			return target.Title, true
+		})
+		if content == source.Content {
+			continue
+		}
+
+		updated, err := q.UpdateEntryFields(ctx, db.UpdateEntryFieldsParams{
+			ID:        source.ID,
+			Content:   sql.NullString{String: content, Valid: true},
+			UpdatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := q.CreateEntryRevision(ctx, updated.ID); err != nil {
+			return err
+		}
+		if err := syncContentLinks(ctx, q, fromDBEntry(updated), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository) ListBacklinks(ctx context.Context, id string) ([]*linkModels.Link, error) {
+	dbLinks, err := r.queries.ListLinksByTarget(ctx, db.ListLinksByTargetParams{TargetEntryID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	var backlinks []db.EntryLink
+	for _, l := range dbLinks {
+		if l.SourceEntryID != id {
+			backlinks = append(backlinks, l)
+		}
+	}
+	return fromDBLinks(backlinks), nil
+}
+
+func (r *repository) GetTrashedByID(ctx context.Context, id string) (*models.Entry, error) {
+	dbEntry, err := r.queries.GetEntryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	if !dbEntry.DeletedAt.Valid {
+		return nil, ErrEntryNotFound
+	}
+
+	return fromDBEntry(dbEntry), nil
+}
+
+func (r *repository) ListTrash(ctx context.Context, userID string) ([]*models.Entry, error) {
+	dbEntries, err := r.queries.ListTrashedEntries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDBEntries(dbEntries), nil
+}
+
+func (r *repository) Restore(ctx context.Context, id string) (*models.Entry, error) {
+	var entry db.Entry
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		var err error
+		entry, err = q.RestoreEntry(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEntryNotFound
+			}
+			return err
+		}
+
+		// Wiki-links written while the entry was in the trash are pending; bind them now.
+		// Its own content links may have been removed when it was trashed, so rebuild them.
+		restored := fromDBEntry(entry)
+		if err := syncAliases(ctx, q, restored); err != nil {
+			return err
+		}
+		if err := rebindPendingLinks(ctx, q, restored); err != nil {
+			return err
+		}
+		if err := syncTags(ctx, q, restored); err != nil {
+			return err
+		}
+		return syncContentLinks(ctx, q, restored, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDBEntry(entry), nil
+}
+
+func (r *repository) Purge(ctx context.Context, id string) error {
+	return r.withTx(ctx, func(q *db.Queries) error {
+		entry, err := q.GetEntryByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEntryNotFound
+			}
+			return err
+		}
+		if err := purgeEntry(ctx, q, entry); err != nil {
+			return err
+		}
+		return q.DeleteUnusedTags(ctx, entry.UserID)
+	})
+}
+
+func (r *repository) PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		entries, err := q.ListEntriesTrashedBefore(ctx, cutoff)
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+		for _, entry := range entries {
+			if err := purgeEntry(ctx, q, entry); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(entries))
+		return q.DeleteUnusedTags(ctx, "")
+	})
+	return purged, err
+}
+
+// purgeEntry deletes entry and its links. Live entries whose content still
+// links to it by name get pending links instead, so the [[wiki-links]] bind
+// again if an entry by that name is created later.
+func purgeEntry(ctx context.Context, q *db.Queries, entry db.Entry) error {
+	removed, err := q.DeleteLinksByEntry(ctx, entry.ID)
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]bool{titleKey(entry.Title): true}
+	for _, alias := range entry.Aliases {
+		keys[titleKey(alias)] = true
+	}
+	seen := make(map[string]bool)
+	for _, link := range removed {
+		if link.TargetEntryID != entry.ID || link.SourceEntryID == entry.ID ||
+			link.Origin != string(linkModels.OriginContent) || seen[link.SourceEntryID] {
+			continue
+		}
+		seen[link.SourceEntryID] = true
+
+		source, err := q.GetEntryByID(ctx, link.SourceEntryID)
+		if err != nil {
+			return err
+		}
+		if source.DeletedAt.Valid {
+			continue
+		}
+		titles := linkedNames(source.Content, keys)
+		if len(titles) == 0 {
+			continue
+		}
+		if err := q.CreatePendingLinks(ctx, db.CreatePendingLinksParams{
+			SourceEntryID: source.ID,
+			UserID:        source.UserID,
+			TargetTitles:  titles,
+			TargetKeys:    titleKeys(titles),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return q.DeleteEntry(ctx, entry.ID)
+}
+
+// linkedNames returns the names content's [[wiki-links]] use for an entry
+// known by the title keys in keys, the first spelling of each.
+// Links by ID are left out: they cannot bind to another entry.
+func linkedNames(content string, keys map[string]bool) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, ref := range wikilink.Parse(content) {
+		key := titleKey(ref.Title)
+		if ref.Title == "" || !keys[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, ref.Title)
+	}
+	return names
+}
@@ -0,0 +1,30 @@
+package entry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkedNames(t *testing.T) {
+	keys := map[string]bool{"moss": true, "bryophyte": true}
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"none", "Plain moss.", nil},
+		{"title", "See [[Moss]].", []string{"Moss"}},
+		{"alias", "A [[Bryophyte|carpet]] and [[Moss#Growth]].", []string{"Bryophyte", "Moss"}},
+		{"first spelling", "[[moss]] then [[MOSS]]", []string{"moss"}},
+		{"other entries", "[[Ferns]] beside [[Moss]]", []string{"Moss"}},
+		{"by id", "[[id:0190a1b2|Moss]]", nil},
+		{"in code", "`[[Moss]]`", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkedNames(tt.content, keys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("linkedNames(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -6,22 +6,37 @@ import (
 	"errors"
 	"time"
 
+	"github.com/lib/pq"
+
 	models "moss/go/internal/models/link"
 	db "moss/go/internal/repository/db/sqlc"
 )
 
 var (
-	ErrLinkNotFound = errors.New("link not found")
-	ErrInvalidLink  = errors.New("invalid link")
+	ErrLinkNotFound     = errors.New("link not found")
+	ErrInvalidLink      = errors.New("invalid link")
+	ErrRelationExists   = errors.New("relation already exists")
+	ErrRelationNotFound = errors.New("relation not found")
 )
 
+// The List and Count methods only consider links with the given relation
+// if relation is non-nil; an empty relation selects plain links.
 type Repository interface {
 	CreateEntryLink(ctx context.Context, l *models.Link) (*models.Link, error)
-	DeleteEntryLink(ctx context.Context, sourceID, targetID string) error
-	ListBySource(ctx context.Context, sourceID string) ([]*models.Link, error)
-	ListByTarget(ctx context.Context, targetID string) ([]*models.Link, error)
-	CountBySource(ctx context.Context, sourceID string) (int64, error)
-	CountByTarget(ctx context.Context, targetID string) (int64, error)
+	DeleteEntryLink(ctx context.Context, sourceID, targetID, relation string) error
+	ListBySource(ctx context.Context, sourceID string, relation *string) ([]*models.Link, error)
+	ListByTarget(ctx context.Context, targetID string, relation *string) ([]*models.Link, error)
+	CountBySource(ctx context.Context, sourceID string, relation *string) (int64, error)
+	CountByTarget(ctx context.Context, targetID string, relation *string) (int64, error)
+	// ListPending lists userID's pending links, only those from sourceID if it is non-empty.
+	ListPending(ctx context.Context, userID, sourceID string) ([]*models.PendingLink, error)
+	// CreateRelation adds r to its user's vocabulary, or returns ErrRelationExists
+	// if the user already has a relation named r.Name.
+	CreateRelation(ctx context.Context, r *models.Relation) (*models.Relation, error)
+	ListRelations(ctx context.Context, userID string) ([]*models.Relation, error)
+	DeleteRelation(ctx context.Context, userID, name string) error
+	// RelationInUse reports whether any of userID's links has one of the given relations.
+	RelationInUse(ctx context.Context, userID string, names []string) (bool, error)
 }
 
 type repository struct {
@@ -45,6 +60,7 @@ func (r *repository) CreateEntryLink(ctx context.Context, l *models.Link) (*mode
 		SourceEntryID: l.SourceEntryID,
 		TargetEntryID: l.TargetEntryID,
 		UserID:        l.UserID,
+		Relation:      l.Relation,
 	})
 	if err != nil {
 		return nil, err
@@ -53,10 +69,11 @@ func (r *repository) CreateEntryLink(ctx context.Context, l *models.Link) (*mode
 	return fromDBLink(created), nil
 }
 
-func (r *repository) DeleteEntryLink(ctx context.Context, sourceID, targetID string) error {
+func (r *repository) DeleteEntryLink(ctx context.Context, sourceID, targetID, relation string) error {
 	err := r.queries.DeleteEntryLink(ctx, db.DeleteEntryLinkParams{
 		SourceEntryID: sourceID,
 		TargetEntryID: targetID,
+		Relation:      relation,
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -67,38 +84,138 @@ func (r *repository) DeleteEntryLink(ctx context.Context, sourceID, targetID str
 	return nil
 }
 
-func (r *repository) ListBySource(ctx context.Context, sourceID string) ([]*models.Link, error) {
-	dbLinks, err := r.queries.ListLinksBySource(ctx, sourceID)
+func (r *repository) ListBySource(ctx context.Context, sourceID string, relation *string) ([]*models.Link, error) {
+	dbLinks, err := r.queries.ListLinksBySource(ctx, db.ListLinksBySourceParams{
+		SourceEntryID: sourceID,
+		Relation:      toNullString(relation),
+	})
 	if err != nil {
 		return nil, err
 	}
 	return fromDBLinks(dbLinks), nil
 }
 
-func (r *repository) ListByTarget(ctx context.Context, targetID string) ([]*models.Link, error) {
-	dbLinks, err := r.queries.ListLinksByTarget(ctx, targetID)
+func (r *repository) ListByTarget(ctx context.Context, targetID string, relation *string) ([]*models.Link, error) {
+	dbLinks, err := r.queries.ListLinksByTarget(ctx, db.ListLinksByTargetParams{
+		TargetEntryID: targetID,
+		Relation:      toNullString(relation),
+	})
 	if err != nil {
 		return nil, err
 	}
 	return fromDBLinks(dbLinks), nil
 }
 
-func (r *repository) CountBySource(ctx context.Context, sourceID string) (int64, error) {
-	count, err := r.queries.CountLinksBySource(ctx, sourceID)
+func (r *repository) CountBySource(ctx context.Context, sourceID string, relation *string) (int64, error) {
+	count, err := r.queries.CountLinksBySource(ctx, db.CountLinksBySourceParams{
+		SourceEntryID: sourceID,
+		Relation:      toNullString(relation),
+	})
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (r *repository) CountByTarget(ctx context.Context, targetID string) (int64, error) {
-	count, err := r.queries.CountLinksByTarget(ctx, targetID)
+func (r *repository) CountByTarget(ctx context.Context, targetID string, relation *string) (int64, error) {
+	count, err := r.queries.CountLinksByTarget(ctx, db.CountLinksByTargetParams{
+		TargetEntryID: targetID,
+		Relation:      toNullString(relation),
+	})
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
+func (r *repository) ListPending(ctx context.Context, userID, sourceID string) ([]*models.PendingLink, error) {
+	dbPending, err := r.queries.ListPendingLinks(ctx, db.ListPendingLinksParams{
+		UserID:        userID,
+		SourceEntryID: sourceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.PendingLink, len(dbPending))
+	for i, p := range dbPending {
+		result[i] = &models.PendingLink{
+			SourceEntryID: p.SourceEntryID,
+			UserID:        p.UserID,
+			TargetTitle:   p.TargetTitle,
+			CreatedAt:     p.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+func (r *repository) CreateRelation(ctx context.Context, rel *models.Relation) (*models.Relation, error) {
+	created, err := r.queries.CreateLinkRelation(ctx, db.CreateLinkRelationParams{
+		UserID:      rel.UserID,
+		Name:        rel.Name,
+		InverseName: rel.InverseName,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrRelationExists
+		}
+		return nil, err
+	}
+	return fromDBRelation(created), nil
+}
+
+func (r *repository) ListRelations(ctx context.Context, userID string) ([]*models.Relation, error) {
+	dbRelations, err := r.queries.ListLinkRelations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Relation, len(dbRelations))
+	for i, rel := range dbRelations {
+		result[i] = fromDBRelation(rel)
+	}
+	return result, nil
+}
+
+func (r *repository) DeleteRelation(ctx context.Context, userID, name string) error {
+	deleted, err := r.queries.DeleteLinkRelation(ctx, db.DeleteLinkRelationParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRelationNotFound
+	}
+	return nil
+}
+
+func (r *repository) RelationInUse(ctx context.Context, userID string, names []string) (bool, error) {
+	return r.queries.LinkRelationInUse(ctx, db.LinkRelationInUseParams{
+		UserID: userID,
+		Names:  names,
+	})
+}
+
+func toNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func fromDBRelation(rel db.LinkRelation) *models.Relation {
+	return &models.Relation{
+		UserID:      rel.UserID,
+		Name:        rel.Name,
+		InverseName: rel.InverseName,
+		CreatedAt:   rel.CreatedAt,
+	}
+}
+
 // fromDBLink converts a SQLC EntryLink row into a domain Link.
 func fromDBLink(dbLink db.EntryLink) *models.Link {
 	return &models.Link{
@@ -106,6 +223,8 @@ func fromDBLink(dbLink db.EntryLink) *models.Link {
 		TargetEntryID: dbLink.TargetEntryID,
 		UserID:        dbLink.UserID,
 		CreatedAt:     dbLink.CreatedAt,
+		Origin:        models.Origin(dbLink.Origin),
+		Relation:      dbLink.Relation,
 	}
 }
 
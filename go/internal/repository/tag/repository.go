@@ -0,0 +1,70 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	models "moss/go/internal/models/tag"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+var ErrTagNotFound = errors.New("tag not found")
+
+// Tags are created and removed as entries are written (see the entry
+// repository); renaming and merging them rewrites entries, so it lives there too.
+type Repository interface {
+	// ListByUser returns userID's tags ordered by name, so nested tags follow their parent.
+	ListByUser(ctx context.Context, userID string) ([]*models.Tag, error)
+	// GetByName returns one of userID's tags. Its EntryCount is not set.
+	GetByName(ctx context.Context, userID, name string) (*models.Tag, error)
+}
+
+type repository struct {
+	queries *db.Queries
+}
+
+func NewRepository(dbConn *sql.DB) Repository {
+	return &repository{
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *repository) ListByUser(ctx context.Context, userID string) ([]*models.Tag, error) {
+	rows, err := r.queries.ListTags(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*models.Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = &models.Tag{
+			ID:         row.ID,
+			UserID:     row.UserID,
+			Name:       row.Name,
+			EntryCount: row.EntryCount,
+			CreatedAt:  row.CreatedAt,
+		}
+	}
+	return tags, nil
+}
+
+func (r *repository) GetByName(ctx context.Context, userID, name string) (*models.Tag, error) {
+	row, err := r.queries.GetTagByName(ctx, db.GetTagByNameParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	return &models.Tag{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Name:      row.Name,
+		CreatedAt: row.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"moss/go/internal/auth"
+	models "moss/go/internal/models/token"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+type Repository interface {
+	Create(ctx context.Context, t *models.Token) (*models.Token, error)
+	GetByHash(ctx context.Context, hash string) (*models.Token, error)
+	ListByUser(ctx context.Context, userID string) ([]*models.Token, error)
+	Revoke(ctx context.Context, id string, userID string) error
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type repository struct {
+	queries *db.Queries
+}
+
+func NewRepository(dbConn *sql.DB) Repository {
+	return &repository{
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *repository) Create(ctx context.Context, t *models.Token) (*models.Token, error) {
+	t.CreatedAt = time.Now().UTC()
+
+	scopes := make([]string, len(t.Scopes))
+	for i, s := range t.Scopes {
+		scopes[i] = string(s)
+	}
+
+	created, err := r.queries.CreateAccessToken(ctx, db.CreateAccessTokenParams{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Name:      t.Name,
+		TokenHash: t.TokenHash,
+		Scopes:    scopes,
+		CreatedAt: t.CreatedAt,
+		ExpiresAt: toNullTime(t.ExpiresAt),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDBToken(created), nil
+}
+
+func (r *repository) GetByHash(ctx context.Context, hash string) (*models.Token, error) {
+	dbToken, err := r.queries.GetAccessTokenByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	return fromDBToken(dbToken), nil
+}
+
+func (r *repository) ListByUser(ctx context.Context, userID string) ([]*models.Token, error) {
+	dbTokens, err := r.queries.ListAccessTokensByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*models.Token, len(dbTokens))
+	for i, t := range dbTokens {
+		tokens[i] = fromDBToken(t)
+	}
+	return tokens, nil
+}
+
+func (r *repository) Revoke(ctx context.Context, id string, userID string) error {
+	rows, err := r.queries.RevokeAccessToken(ctx, db.RevokeAccessTokenParams{
+		ID:        id,
+		UserID:    userID,
+		RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (r *repository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	return r.queries.TouchAccessToken(ctx, db.TouchAccessTokenParams{
+		ID:         id,
+		LastUsedAt: sql.NullTime{Time: usedAt, Valid: true},
+	})
+}
+
+func fromDBToken(dbToken db.PersonalAccessToken) *models.Token {
+	scopes := make([]auth.Scope, len(dbToken.Scopes))
+	for i, s := range dbToken.Scopes {
+		scopes[i] = auth.Scope(s)
+	}
+
+	return &models.Token{
+		ID:         dbToken.ID,
+		UserID:     dbToken.UserID,
+		Name:       dbToken.Name,
+		TokenHash:  dbToken.TokenHash,
+		Scopes:     scopes,
+		CreatedAt:  dbToken.CreatedAt,
+		ExpiresAt:  fromNullTime(dbToken.ExpiresAt),
+		LastUsedAt: fromNullTime(dbToken.LastUsedAt),
+		RevokedAt:  fromNullTime(dbToken.RevokedAt),
+	}
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
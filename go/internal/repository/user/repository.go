@@ -0,0 +1,268 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "moss/go/internal/models/user"
+	db "moss/go/internal/repository/db/sqlc"
+)
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrEmailTaken      = errors.New("email already registered")
+	ErrGardenSlugTaken = errors.New("garden slug already taken")
+)
+
+type Repository interface {
+	Create(ctx context.Context, u *models.User) (*models.User, error)
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdateProfile(ctx context.Context, u *models.User) (*models.User, error)
+
+	CreateSession(ctx context.Context, s *models.Session) (*models.Session, error)
+	GetSessionByID(ctx context.Context, id string) (*models.Session, error)
+	GetSessionByRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error)
+	// GetSessionByRetiredRefreshTokenHash returns the session that used to hold a
+	// refresh token before rotating it away.
+	GetSessionByRetiredRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error)
+	// RotateSession replaces the session's refresh token, retiring oldHash. It returns
+	// ErrSessionNotFound unless oldHash is still current and the session is live.
+	RotateSession(ctx context.Context, id string, oldHash string, newHash string, expiresAt time.Time) (*models.Session, error)
+	RevokeSession(ctx context.Context, id string) error
+	RevokeUserSessions(ctx context.Context, userID string) error
+}
+
+type repository struct {
+	conn    *sql.DB
+	queries *db.Queries
+}
+
+func NewRepository(dbConn *sql.DB) Repository {
+	return &repository{
+		conn:    dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *repository) Create(ctx context.Context, u *models.User) (*models.User, error) {
+	now := time.Now().UTC()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	created, err := r.queries.CreateUser(ctx, db.CreateUserParams{
+		ID:           u.ID,
+		Email:        u.Email,
+		PasswordHash: u.PasswordHash,
+		DisplayName:  u.DisplayName,
+		GardenSlug:   u.GardenSlug,
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
+	})
+	if err != nil {
+		return nil, mapUniqueViolation(err)
+	}
+
+	return fromDBUser(created), nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	dbUser, err := r.queries.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return fromDBUser(dbUser), nil
+}
+
+func (r *repository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	dbUser, err := r.queries.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return fromDBUser(dbUser), nil
+}
+
+func (r *repository) UpdateProfile(ctx context.Context, u *models.User) (*models.User, error) {
+	u.UpdatedAt = time.Now().UTC()
+
+	updated, err := r.queries.UpdateUserProfile(ctx, db.UpdateUserProfileParams{
+		ID:          u.ID,
+		DisplayName: u.DisplayName,
+		GardenSlug:  u.GardenSlug,
+		UpdatedAt:   u.UpdatedAt,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, mapUniqueViolation(err)
+	}
+
+	return fromDBUser(updated), nil
+}
+
+func (r *repository) CreateSession(ctx context.Context, s *models.Session) (*models.Session, error) {
+	s.CreatedAt = time.Now().UTC()
+
+	created, err := r.queries.CreateSession(ctx, db.CreateSessionParams{
+		ID:               s.ID,
+		UserID:           s.UserID,
+		RefreshTokenHash: s.RefreshTokenHash,
+		CreatedAt:        s.CreatedAt,
+		ExpiresAt:        s.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDBSession(created), nil
+}
+
+func (r *repository) GetSessionByID(ctx context.Context, id string) (*models.Session, error) {
+	dbSession, err := r.queries.GetSessionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return fromDBSession(dbSession), nil
+}
+
+func (r *repository) GetSessionByRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	dbSession, err := r.queries.GetSessionByRefreshTokenHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return fromDBSession(dbSession), nil
+}
+
+func (r *repository) GetSessionByRetiredRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	dbSession, err := r.queries.GetSessionByRetiredRefreshTokenHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return fromDBSession(dbSession), nil
+}
+
+func (r *repository) RotateSession(ctx context.Context, id string, oldHash string, newHash string, expiresAt time.Time) (*models.Session, error) {
+	now := time.Now().UTC()
+
+	var dbSession db.Session
+	err := r.withTx(ctx, func(q *db.Queries) error {
+		var err error
+		dbSession, err = q.RotateSessionRefreshToken(ctx, db.RotateSessionRefreshTokenParams{
+			NewHash:   newHash,
+			ExpiresAt: expiresAt,
+			ID:        id,
+			OldHash:   oldHash,
+			Now:       now,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+
+		return q.RetireRefreshToken(ctx, db.RetireRefreshTokenParams{
+			TokenHash: oldHash,
+			SessionID: id,
+			RetiredAt: now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDBSession(dbSession), nil
+}
+
+func (r *repository) RevokeSession(ctx context.Context, id string) error {
+	return r.queries.RevokeSession(ctx, db.RevokeSessionParams{
+		ID:        id,
+		RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+}
+
+func (r *repository) RevokeUserSessions(ctx context.Context, userID string) error {
+	return r.queries.RevokeUserSessions(ctx, db.RevokeUserSessionsParams{
+		UserID:    userID,
+		RevokedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// mapUniqueViolation translates unique constraint violations on users into domain errors.
+func mapUniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		switch pqErr.Constraint {
+		case "users_email_key":
+			return ErrEmailTaken
+		case "users_garden_slug_key":
+			return ErrGardenSlugTaken
+		}
+	}
+	return err
+}
+
+func fromDBUser(dbUser db.User) *models.User {
+	return &models.User{
+		ID:           dbUser.ID,
+		Email:        dbUser.Email,
+		PasswordHash: dbUser.PasswordHash,
+		DisplayName:  dbUser.DisplayName,
+		GardenSlug:   dbUser.GardenSlug,
+		CreatedAt:    dbUser.CreatedAt,
+		UpdatedAt:    dbUser.UpdatedAt,
+	}
+}
+
+func fromDBSession(dbSession db.Session) *models.Session {
+	s := &models.Session{
+		ID:               dbSession.ID,
+		UserID:           dbSession.UserID,
+		RefreshTokenHash: dbSession.RefreshTokenHash,
+		CreatedAt:        dbSession.CreatedAt,
+		ExpiresAt:        dbSession.ExpiresAt,
+	}
+	if dbSession.RevokedAt.Valid {
+		s.RevokedAt = &dbSession.RevokedAt.Time
+	}
+	return s
+}
@@ -0,0 +1,30 @@
+package entry
+
+import (
+	"moss/go/internal/auth"
+	"moss/go/internal/genproto/protobuf/entry/entryconnect"
+)
+
+// ProcedureScopes maps each EntryService procedure to the scope
+// a personal access token needs to call it.
+var ProcedureScopes = map[string]auth.Scope{
+	entryconnect.EntryServiceCreateEntryProcedure:              auth.ScopeEntriesWrite,
+	entryconnect.EntryServiceGetEntryProcedure:                 auth.ScopeEntriesRead,
+	entryconnect.EntryServiceUpdateEntryProcedure:              auth.ScopeEntriesWrite,
+	entryconnect.EntryServiceDeleteEntryProcedure:              auth.ScopeEntriesWrite,
+	entryconnect.EntryServiceListEntriesProcedure:              auth.ScopeEntriesRead,
+	entryconnect.EntryServiceSearchEntriesProcedure:            auth.ScopeEntriesRead,
+	entryconnect.EntryServiceSuggestEntriesProcedure:           auth.ScopeEntriesRead,
+	entryconnect.EntryServiceListBacklinksWithContextProcedure: auth.ScopeEntriesRead,
+	entryconnect.EntryServiceListUnlinkedMentionsProcedure:     auth.ScopeEntriesRead,
+	entryconnect.EntryServiceLinkMentionProcedure:              auth.ScopeEntriesWrite,
+	entryconnect.EntryServiceListRevisionsProcedure:            auth.ScopeEntriesRead,
+	entryconnect.EntryServiceGetRevisionProcedure:              auth.ScopeEntriesRead,
+	entryconnect.EntryServiceDiffRevisionsProcedure:            auth.ScopeEntriesRead,
+	entryconnect.EntryServiceRestoreRevisionProcedure:          auth.ScopeEntriesWrite,
+	entryconnect.EntryServicePromoteEntryProcedure:             auth.ScopeEntriesWrite,
+	entryconnect.EntryServiceListStageTransitionsProcedure:     auth.ScopeEntriesRead,
+	entryconnect.EntryServiceListTrashProcedure:                auth.ScopeEntriesRead,
+	entryconnect.EntryServiceRestoreEntryProcedure:             auth.ScopeEntriesWrite,
+	entryconnect.EntryServicePurgeEntryProcedure:               auth.ScopeEntriesWrite,
+}
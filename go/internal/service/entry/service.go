@@ -4,11 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"connectrpc.com/connect"
 	entryApp "moss/go/internal/app/entry"
+	"moss/go/internal/auth"
+	commonpb "moss/go/internal/genproto/protobuf/common"
 	entrypb "moss/go/internal/genproto/protobuf/entry"
+	linkpb "moss/go/internal/genproto/protobuf/link"
 	models "moss/go/internal/models/entry"
+	linkModels "moss/go/internal/models/link"
+	"moss/go/internal/service/growthstage"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -27,113 +34,747 @@ func NewService(app entryApp.App) *Service {
 // CreateEntry implements the EntryServiceHandler interface
 func (s *Service) CreateEntry(ctx context.Context, req *connect.Request[entrypb.CreateEntryRequest]) (*connect.Response[entrypb.CreateEntryResponse], error) {
 	domainEntry := &models.Entry{
-		UserID:      req.Msg.UserId,
+		ID:          req.Msg.EntryId,
 		Title:       req.Msg.Title,
 		Content:     req.Msg.Content,
-		GrowthStage: models.GrowthStage(req.Msg.GrowthStage.String()),
+		GrowthStage: growthstage.FromProto(req.Msg.GrowthStage),
+		Aliases:     req.Msg.Aliases,
 	}
 
-	created, err := s.app.CreateEntry(ctx, domainEntry)
+	created, err := s.app.CreateEntry(ctx, domainEntry, req.Header().Get("Idempotency-Key"), req.Msg.CreatePlaceholders)
 	if err != nil {
 		if errors.Is(entryApp.ErrInvalidEntry, err) {
 			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid entry: %s", err))
 		}
+		if errors.Is(err, entryApp.ErrInvalidIdempotencyKey) {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		if errors.Is(err, entryApp.ErrEntryIDTaken) {
+			return nil, connect.NewError(connect.CodeAlreadyExists, err)
+		}
+		var taken *entryApp.NameTakenError
+		if errors.As(err, &taken) {
+			return nil, nameTakenError(taken)
+		}
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		}
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create entry: %w", err))
 	}
 
 	return connect.NewResponse(&entrypb.CreateEntryResponse{
-		Entry: toProtoEntry(created, 0),
+		Entry: toProtoEntry(created),
 	}), nil
 }
 
 // GetEntry implements the EntryServiceHandler interface
 func (s *Service) GetEntry(ctx context.Context, req *connect.Request[entrypb.GetEntryRequest]) (*connect.Response[entrypb.GetEntryResponse], error) {
-	// TODO: Replace with real userID from auth context
-	userID := "user-id-from-auth"
-
-	domainEntry, err := s.app.GetEntry(ctx, req.Msg.EntryId, userID)
+	domainEntry, err := s.app.GetEntry(ctx, req.Msg.EntryId)
 	if err != nil {
 		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
 		case entryApp.ErrUnauthorized:
 			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
 		default:
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get entry: %w", err))
 		}
 	}
 
 	return connect.NewResponse(&entrypb.GetEntryResponse{
-		Entry: toProtoEntry(domainEntry, 0),
+		Entry: toProtoEntry(domainEntry),
 	}), nil
 }
 
 // UpdateEntry implements the EntryServiceHandler interface
 func (s *Service) UpdateEntry(ctx context.Context, req *connect.Request[entrypb.UpdateEntryRequest]) (*connect.Response[entrypb.UpdateEntryResponse], error) {
-	domainEntry := &models.Entry{
-		ID:          req.Msg.EntryId,
-		Title:       req.Msg.Title,
-		Content:     req.Msg.Content,
-		GrowthStage: models.GrowthStage(req.Msg.GrowthStage.String()),
+	var updated *models.Entry
+	var err error
+	if len(req.Msg.GetUpdateMask().GetPaths()) == 0 {
+		// growth_stage is left out: without an update mask it would default
+		// to SEED for clients that never set it. Any other stage was meant to
+		// be applied, so refuse it rather than drop it.
+		if req.Msg.GrowthStage != commonpb.GrowthStage_SEED {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("growth_stage is only applied when listed in update_mask"))
+		}
+		domainEntry := &models.Entry{
+			ID:      req.Msg.EntryId,
+			Title:   req.Msg.Title,
+			Content: req.Msg.Content,
+			Aliases: req.Msg.Aliases,
+			Version: req.Msg.ExpectedVersion,
+		}
+		updated, err = s.app.UpdateEntry(ctx, domainEntry, req.Msg.CreatePlaceholders)
+	} else {
+		patch, maskErr := toDomainPatch(req.Msg)
+		if maskErr != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, maskErr)
+		}
+		updated, err = s.app.PatchEntry(ctx, req.Msg.EntryId, patch, req.Msg.CreatePlaceholders)
 	}
-
-	updated, err := s.app.UpdateEntry(ctx, domainEntry)
 	if err != nil {
+		var conflict *entryApp.VersionConflictError
+		if errors.As(err, &conflict) {
+			return nil, versionConflictError(conflict)
+		}
+		var taken *entryApp.NameTakenError
+		if errors.As(err, &taken) {
+			return nil, nameTakenError(taken)
+		}
+		var transition *entryApp.TransitionError
+		if errors.As(err, &transition) {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, transition)
+		}
 		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
 		case entryApp.ErrInvalidEntry:
 			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid entry"))
 		case entryApp.ErrUnauthorized:
 			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
 		default:
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to update entry: %w", err))
 		}
 	}
 
 	return connect.NewResponse(&entrypb.UpdateEntryResponse{
-		Entry: toProtoEntry(updated, 0),
+		Entry: toProtoEntry(updated),
 	}), nil
 }
 
 // DeleteEntry implements the EntryServiceHandler interface
-func (s *Service) DeleteEntry(ctx context.Context, req *connect.Request[entrypb.DeleteEntryRequest]) (*connect.Response[emptypb.Empty], error) {
-	err := s.app.DeleteEntry(ctx, req.Msg.EntryId)
+func (s *Service) DeleteEntry(ctx context.Context, req *connect.Request[entrypb.DeleteEntryRequest]) (*connect.Response[entrypb.DeleteEntryResponse], error) {
+	removed, err := s.app.DeleteEntry(ctx, req.Msg.EntryId, toDomainDeletionPolicy(req.Msg.Policy))
 	if err != nil {
+		var blocked *entryApp.BacklinksError
+		if errors.As(err, &blocked) {
+			return nil, deleteBlockedError(blocked)
+		}
 		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrInvalidDeletionPolicy:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
 		case entryApp.ErrUnauthorized:
 			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
 		default:
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to delete entry: %w", err))
 		}
 	}
+
+	return connect.NewResponse(&entrypb.DeleteEntryResponse{
+		RemovedLinks: toProtoLinks(removed),
+	}), nil
+}
+
+// ListTrash implements the EntryServiceHandler interface
+func (s *Service) ListTrash(ctx context.Context, req *connect.Request[entrypb.ListTrashRequest]) (*connect.Response[entrypb.ListTrashResponse], error) {
+	trashed, err := s.app.ListTrash(ctx)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list trash: %w", err))
+		}
+	}
+
+	protoEntries := make([]*entrypb.Entry, len(trashed))
+	for i, e := range trashed {
+		protoEntries[i] = toProtoEntry(e)
+	}
+	return connect.NewResponse(&entrypb.ListTrashResponse{Entries: protoEntries}), nil
+}
+
+// RestoreEntry implements the EntryServiceHandler interface
+func (s *Service) RestoreEntry(ctx context.Context, req *connect.Request[entrypb.RestoreEntryRequest]) (*connect.Response[entrypb.RestoreEntryResponse], error) {
+	restored, err := s.app.RestoreEntry(ctx, req.Msg.EntryId)
+	if err != nil {
+		var taken *entryApp.NameTakenError
+		if errors.As(err, &taken) {
+			return nil, nameTakenError(taken)
+		}
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrNotInTrash:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to restore entry: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&entrypb.RestoreEntryResponse{
+		Entry: toProtoEntry(restored),
+	}), nil
+}
+
+// PurgeEntry implements the EntryServiceHandler interface
+func (s *Service) PurgeEntry(ctx context.Context, req *connect.Request[entrypb.PurgeEntryRequest]) (*connect.Response[emptypb.Empty], error) {
+	err := s.app.PurgeEntry(ctx, req.Msg.EntryId)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrNotInTrash:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to purge entry: %w", err))
+		}
+	}
 	return connect.NewResponse(&emptypb.Empty{}), nil
 }
 
 // ListEntries implements the EntryServiceHandler interface
 func (s *Service) ListEntries(ctx context.Context, req *connect.Request[entrypb.ListEntriesRequest]) (*connect.Response[entrypb.ListEntriesResponse], error) {
-	domainEntries, err := s.app.ListEntries(ctx, req.Msg.UserId)
+	query := models.ListQuery{
+		Filter:     toDomainFilter(req.Msg.Filter),
+		SortBy:     toDomainSortField(req.Msg.SortBy),
+		Descending: req.Msg.Descending,
+		Limit:      int(req.Msg.PageSize),
+	}
+
+	domainEntries, nextPageToken, err := s.app.ListEntries(ctx, query, req.Msg.PageToken)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list entries: %w", err))
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrInvalidPageToken, entryApp.ErrInvalidPageSize, entryApp.ErrInvalidTagExpression:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list entries: %w", err))
+		}
 	}
 
 	protoEntries := make([]*entrypb.Entry, len(domainEntries))
 	for i, e := range domainEntries {
-		protoEntries[i] = toProtoEntry(e, 0)
+		protoEntries[i] = toProtoEntry(e)
 	}
 
 	return connect.NewResponse(&entrypb.ListEntriesResponse{
-		Entries: protoEntries,
+		Entries:       protoEntries,
+		NextPageToken: nextPageToken,
+	}), nil
+}
+
+// SearchEntries implements the EntryServiceHandler interface
+func (s *Service) SearchEntries(ctx context.Context, req *connect.Request[entrypb.SearchEntriesRequest]) (*connect.Response[entrypb.SearchEntriesResponse], error) {
+	query := models.SearchQuery{
+		Text:  req.Msg.Query,
+		Tags:  req.Msg.Tags,
+		Limit: int(req.Msg.PageSize),
+	}
+	if req.Msg.GrowthStage != nil {
+		query.GrowthStage = growthstage.FromProto(*req.Msg.GrowthStage)
+	}
+
+	results, nextPageToken, err := s.app.SearchEntries(ctx, query, req.Msg.PageToken)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrInvalidSearchQuery, entryApp.ErrInvalidPageToken, entryApp.ErrInvalidPageSize, entryApp.ErrInvalidTagExpression:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to search entries: %w", err))
+		}
+	}
+
+	protoResults := make([]*entrypb.SearchResult, len(results))
+	for i, r := range results {
+		protoResults[i] = &entrypb.SearchResult{
+			Entry:          toProtoEntry(r.Entry),
+			Rank:           r.Rank,
+			TitleHighlight: r.TitleHighlight,
+			Snippet:        r.Snippet,
+		}
+	}
+
+	return connect.NewResponse(&entrypb.SearchEntriesResponse{
+		Results:       protoResults,
+		NextPageToken: nextPageToken,
+	}), nil
+}
+
+// SuggestEntries implements the EntryServiceHandler interface
+func (s *Service) SuggestEntries(ctx context.Context, req *connect.Request[entrypb.SuggestEntriesRequest]) (*connect.Response[entrypb.SuggestEntriesResponse], error) {
+	suggestions, err := s.app.SuggestEntries(ctx, req.Msg.Query, int(req.Msg.Limit))
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrInvalidSearchQuery, entryApp.ErrInvalidLimit:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to suggest entries: %w", err))
+		}
+	}
+
+	protoSuggestions := make([]*entrypb.Suggestion, len(suggestions))
+	for i, s := range suggestions {
+		protoSuggestions[i] = &entrypb.Suggestion{
+			Entry:         toProtoEntry(s.Entry),
+			Score:         s.Score,
+			Similarity:    s.Similarity,
+			BacklinkCount: int32(s.BacklinkCount),
+		}
+	}
+
+	return connect.NewResponse(&entrypb.SuggestEntriesResponse{
+		Suggestions: protoSuggestions,
 	}), nil
 }
 
-// toProtoEntry converts a domain Entry into a proto Entry, injecting linkCount separately.
-// Replace hardcoded 0 with actual lookup when you add entry_links support.
-func toProtoEntry(domain *models.Entry, linkCount int) *entrypb.Entry {
+// ListBacklinksWithContext implements the EntryServiceHandler interface
+func (s *Service) ListBacklinksWithContext(ctx context.Context, req *connect.Request[entrypb.ListBacklinksWithContextRequest]) (*connect.Response[entrypb.ListBacklinksWithContextResponse], error) {
+	backlinks, nextPageToken, err := s.app.ListBacklinks(ctx, req.Msg.EntryId, int(req.Msg.PageSize), req.Msg.PageToken)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrInvalidPageToken, entryApp.ErrInvalidPageSize:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list backlinks: %w", err))
+		}
+	}
+
+	protoBacklinks := make([]*entrypb.Backlink, len(backlinks))
+	for i, b := range backlinks {
+		protoBacklinks[i] = &entrypb.Backlink{
+			SourceEntryId:     b.Source.ID,
+			SourceTitle:       b.Source.Title,
+			SourceGrowthStage: growthstage.ToProto(b.Source.GrowthStage),
+			Contexts:          b.Contexts,
+		}
+	}
+
+	return connect.NewResponse(&entrypb.ListBacklinksWithContextResponse{
+		Backlinks:     protoBacklinks,
+		NextPageToken: nextPageToken,
+	}), nil
+}
+
+// ListUnlinkedMentions implements the EntryServiceHandler interface
+func (s *Service) ListUnlinkedMentions(ctx context.Context, req *connect.Request[entrypb.ListUnlinkedMentionsRequest]) (*connect.Response[entrypb.ListUnlinkedMentionsResponse], error) {
+	mentions, nextPageToken, err := s.app.ListUnlinkedMentions(ctx, req.Msg.EntryId, int(req.Msg.PageSize), req.Msg.PageToken)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrInvalidPageToken, entryApp.ErrInvalidPageSize:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list unlinked mentions: %w", err))
+		}
+	}
+
+	protoMentions := make([]*entrypb.UnlinkedMention, len(mentions))
+	for i, m := range mentions {
+		protoMentions[i] = &entrypb.UnlinkedMention{
+			SourceEntryId:     m.Source.ID,
+			SourceTitle:       m.Source.Title,
+			SourceGrowthStage: growthstage.ToProto(m.Source.GrowthStage),
+			SourceVersion:     m.Source.Version,
+			Start:             int32(m.Start),
+			End:               int32(m.End),
+			Text:              m.Source.Content[m.Start:m.End],
+			Context:           m.Context,
+		}
+	}
+
+	return connect.NewResponse(&entrypb.ListUnlinkedMentionsResponse{
+		Mentions:      protoMentions,
+		NextPageToken: nextPageToken,
+	}), nil
+}
+
+// LinkMention implements the EntryServiceHandler interface
+func (s *Service) LinkMention(ctx context.Context, req *connect.Request[entrypb.LinkMentionRequest]) (*connect.Response[entrypb.LinkMentionResponse], error) {
+	updated, err := s.app.LinkMention(ctx, req.Msg.EntryId, req.Msg.SourceEntryId, int(req.Msg.Start), req.Msg.ExpectedVersion)
+	if err != nil {
+		var conflict *entryApp.VersionConflictError
+		if errors.As(err, &conflict) {
+			return nil, versionConflictError(conflict)
+		}
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrMentionNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to link mention: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&entrypb.LinkMentionResponse{
+		SourceEntry: toProtoEntry(updated),
+	}), nil
+}
+
+// ListRevisions implements the EntryServiceHandler interface
+func (s *Service) ListRevisions(ctx context.Context, req *connect.Request[entrypb.ListRevisionsRequest]) (*connect.Response[entrypb.ListRevisionsResponse], error) {
+	revisions, err := s.app.ListRevisions(ctx, req.Msg.EntryId)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list revisions: %w", err))
+		}
+	}
+
+	protoRevisions := make([]*entrypb.Revision, len(revisions))
+	for i, r := range revisions {
+		protoRevisions[i] = toProtoRevision(r)
+	}
+	return connect.NewResponse(&entrypb.ListRevisionsResponse{Revisions: protoRevisions}), nil
+}
+
+// GetRevision implements the EntryServiceHandler interface
+func (s *Service) GetRevision(ctx context.Context, req *connect.Request[entrypb.GetRevisionRequest]) (*connect.Response[entrypb.GetRevisionResponse], error) {
+	revision, err := s.app.GetRevision(ctx, req.Msg.EntryId, req.Msg.Revision)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrRevisionNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get revision: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&entrypb.GetRevisionResponse{Revision: toProtoRevision(revision)}), nil
+}
+
+// DiffRevisions implements the EntryServiceHandler interface
+func (s *Service) DiffRevisions(ctx context.Context, req *connect.Request[entrypb.DiffRevisionsRequest]) (*connect.Response[entrypb.DiffRevisionsResponse], error) {
+	diff, err := s.app.DiffRevisions(ctx, req.Msg.EntryId, req.Msg.FromRevision, req.Msg.ToRevision)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrRevisionNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to diff revisions: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&entrypb.DiffRevisionsResponse{Diff: diff}), nil
+}
+
+// RestoreRevision implements the EntryServiceHandler interface
+func (s *Service) RestoreRevision(ctx context.Context, req *connect.Request[entrypb.RestoreRevisionRequest]) (*connect.Response[entrypb.RestoreRevisionResponse], error) {
+	restored, err := s.app.RestoreRevision(ctx, req.Msg.EntryId, req.Msg.Revision)
+	if err != nil {
+		var conflict *entryApp.VersionConflictError
+		if errors.As(err, &conflict) {
+			return nil, versionConflictError(conflict)
+		}
+		var transition *entryApp.TransitionError
+		if errors.As(err, &transition) {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, transition)
+		}
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrRevisionNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to restore revision: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&entrypb.RestoreRevisionResponse{
+		Entry: toProtoEntry(restored),
+	}), nil
+}
+
+// PromoteEntry implements the EntryServiceHandler interface
+func (s *Service) PromoteEntry(ctx context.Context, req *connect.Request[entrypb.PromoteEntryRequest]) (*connect.Response[entrypb.PromoteEntryResponse], error) {
+	promoted, transition, err := s.app.PromoteEntry(ctx, req.Msg.EntryId, growthstage.FromProto(req.Msg.GrowthStage), req.Msg.Reason, req.Msg.ExpectedVersion)
+	if err != nil {
+		var conflict *entryApp.VersionConflictError
+		if errors.As(err, &conflict) {
+			return nil, versionConflictError(conflict)
+		}
+		var disallowed *entryApp.TransitionError
+		if errors.As(err, &disallowed) {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, disallowed)
+		}
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case entryApp.ErrInvalidGrowthStage, entryApp.ErrInvalidReason:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		case entryApp.ErrSameGrowthStage:
+			return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to promote entry: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&entrypb.PromoteEntryResponse{
+		Entry:      toProtoEntry(promoted),
+		Transition: toProtoStageTransition(transition),
+	}), nil
+}
+
+// ListStageTransitions implements the EntryServiceHandler interface
+func (s *Service) ListStageTransitions(ctx context.Context, req *connect.Request[entrypb.ListStageTransitionsRequest]) (*connect.Response[entrypb.ListStageTransitionsResponse], error) {
+	transitions, err := s.app.ListStageTransitions(ctx, req.Msg.EntryId)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case entryApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case entryApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list stage transitions: %w", err))
+		}
+	}
+
+	protoTransitions := make([]*entrypb.StageTransition, len(transitions))
+	for i, t := range transitions {
+		protoTransitions[i] = toProtoStageTransition(t)
+	}
+	return connect.NewResponse(&entrypb.ListStageTransitionsResponse{
+		Transitions: protoTransitions,
+	}), nil
+}
+
+// toDomainFilter converts a proto EntryFilter into a domain ListFilter.
+func toDomainFilter(f *entrypb.EntryFilter) models.ListFilter {
+	if f == nil {
+		return models.ListFilter{}
+	}
+
+	filter := models.ListFilter{
+		CreatedAfter:  toTime(f.CreatedAfter),
+		CreatedBefore: toTime(f.CreatedBefore),
+		UpdatedAfter:  toTime(f.UpdatedAfter),
+		UpdatedBefore: toTime(f.UpdatedBefore),
+		TitlePrefix:   f.TitlePrefix,
+		Tags:          f.Tags,
+	}
+	if f.GrowthStage != nil {
+		filter.GrowthStage = growthstage.FromProto(*f.GrowthStage)
+	}
+	return filter
+}
+
+// toDomainPatch converts an UpdateEntryRequest with an update_mask into a domain Patch.
+func toDomainPatch(msg *entrypb.UpdateEntryRequest) (models.Patch, error) {
+	patch := models.Patch{Version: msg.ExpectedVersion}
+	for _, path := range msg.UpdateMask.Paths {
+		switch path {
+		case "title":
+			patch.Title = &msg.Title
+		case "content":
+			patch.Content = &msg.Content
+		case "growth_stage":
+			stage := growthstage.FromProto(msg.GrowthStage)
+			patch.GrowthStage = &stage
+		case "aliases":
+			aliases := msg.Aliases
+			patch.Aliases = &aliases
+		default:
+			return models.Patch{}, fmt.Errorf("unknown update_mask path %q", path)
+		}
+	}
+	return patch, nil
+}
+
+func toDomainDeletionPolicy(policy entrypb.DeletionPolicy) models.DeletionPolicy {
+	switch policy {
+	case entrypb.DeletionPolicy_DELETION_POLICY_KEEP:
+		return models.DeletionPolicyKeep
+	case entrypb.DeletionPolicy_DELETION_POLICY_CASCADE:
+		return models.DeletionPolicyCascade
+	case entrypb.DeletionPolicy_DELETION_POLICY_REJECT:
+		return models.DeletionPolicyReject
+	case entrypb.DeletionPolicy_DELETION_POLICY_REWRITE:
+		return models.DeletionPolicyRewrite
+	default:
+		return models.DeletionPolicy(policy.String())
+	}
+}
+
+func toDomainSortField(sortBy entrypb.SortBy) models.SortField {
+	switch sortBy {
+	case entrypb.SortBy_SORT_BY_UPDATED:
+		return models.SortByUpdated
+	case entrypb.SortBy_SORT_BY_TITLE:
+		return models.SortByTitle
+	case entrypb.SortBy_SORT_BY_LINK_COUNT:
+		return models.SortByLinkCount
+	default:
+		return models.SortByCreated
+	}
+}
+
+func toTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// toProtoEntry converts a domain Entry into a proto Entry.
+func toProtoEntry(domain *models.Entry) *entrypb.Entry {
 	return &entrypb.Entry{
-		Id:          domain.ID,
-		UserId:      domain.UserID,
+		Id:            domain.ID,
+		UserId:        domain.UserID,
+		Title:         domain.Title,
+		Content:       domain.Content,
+		CreatedAt:     timestamppb.New(domain.CreatedAt),
+		UpdatedAt:     timestamppb.New(domain.UpdatedAt),
+		GrowthStage:   growthstage.ToProto(domain.GrowthStage),
+		LinkCount:     int32(domain.LinkCount),
+		BacklinkCount: int32(domain.BacklinkCount),
+		Version:       domain.Version,
+		DeletedAt:     toProtoTimestamp(domain.DeletedAt),
+		Aliases:       domain.Aliases,
+	}
+}
+
+func toProtoTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// nameTakenError builds an ALREADY_EXISTS error carrying the taken name and the
+// entry that has it as a NameTaken detail.
+func nameTakenError(taken *entryApp.NameTakenError) *connect.Error {
+	connectErr := connect.NewError(connect.CodeAlreadyExists, taken)
+	detail, err := connect.NewErrorDetail(&entrypb.NameTaken{Name: taken.Name, EntryId: taken.EntryID})
+	if err == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
+
+// versionConflictError builds an ABORTED error carrying the stored entry as a
+// VersionConflict detail, so the client can merge its edit and retry.
+func versionConflictError(conflict *entryApp.VersionConflictError) *connect.Error {
+	connectErr := connect.NewError(connect.CodeAborted, conflict)
+	detail, err := connect.NewErrorDetail(&entrypb.VersionConflict{Current: toProtoEntry(conflict.Current)})
+	if err == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
+
+// deleteBlockedError builds a FAILED_PRECONDITION error naming the entries that
+// link to the one being deleted, with the links as a DeleteBlocked detail.
+func deleteBlockedError(blocked *entryApp.BacklinksError) *connect.Error {
+	sources := make([]string, len(blocked.Backlinks))
+	for i, l := range blocked.Backlinks {
+		sources[i] = l.SourceEntryID
+	}
+	connectErr := connect.NewError(connect.CodeFailedPrecondition,
+		fmt.Errorf("entry is linked from: %s", strings.Join(sources, ", ")))
+	detail, err := connect.NewErrorDetail(&entrypb.DeleteBlocked{Backlinks: toProtoLinks(blocked.Backlinks)})
+	if err == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
+
+func toProtoLinks(domain []*linkModels.Link) []*linkpb.Link {
+	links := make([]*linkpb.Link, len(domain))
+	for i, l := range domain {
+		origin := linkpb.LinkOrigin_LINK_ORIGIN_MANUAL
+		if l.Origin == linkModels.OriginContent {
+			origin = linkpb.LinkOrigin_LINK_ORIGIN_CONTENT
+		}
+		links[i] = &linkpb.Link{
+			SourceEntryId: l.SourceEntryID,
+			TargetEntryId: l.TargetEntryID,
+			UserId:        l.UserID,
+			CreatedAt:     timestamppb.New(l.CreatedAt),
+			Origin:        origin,
+			Relation:      l.Relation,
+		}
+	}
+	return links
+}
+
+// toProtoStageTransition converts a domain StageTransition into a proto StageTransition.
+func toProtoStageTransition(domain *models.StageTransition) *entrypb.StageTransition {
+	return &entrypb.StageTransition{
+		Id:        domain.ID,
+		EntryId:   domain.EntryID,
+		FromStage: growthstage.ToProto(domain.From),
+		ToStage:   growthstage.ToProto(domain.To),
+		UserId:    domain.UserID,
+		TokenId:   domain.TokenID,
+		Reason:    domain.Reason,
+		CreatedAt: timestamppb.New(domain.CreatedAt),
+	}
+}
+
+// toProtoRevision converts a domain Revision into a proto Revision.
+func toProtoRevision(domain *models.Revision) *entrypb.Revision {
+	return &entrypb.Revision{
+		EntryId:     domain.EntryID,
+		Revision:    domain.Number,
 		Title:       domain.Title,
 		Content:     domain.Content,
+		GrowthStage: growthstage.ToProto(domain.GrowthStage),
 		CreatedAt:   timestamppb.New(domain.CreatedAt),
-		UpdatedAt:   timestamppb.New(domain.UpdatedAt),
-		GrowthStage: entrypb.GrowthStage(entrypb.GrowthStage_value[string(domain.GrowthStage)]),
-		LinkCount:   int32(linkCount),
 	}
 }
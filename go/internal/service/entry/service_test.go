@@ -0,0 +1,71 @@
+package entry
+
+import (
+	"context"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	entryApp "moss/go/internal/app/entry"
+	commonpb "moss/go/internal/genproto/protobuf/common"
+	entrypb "moss/go/internal/genproto/protobuf/entry"
+	models "moss/go/internal/models/entry"
+)
+
+// stubApp records UpdateEntry and PatchEntry calls; other methods are not implemented.
+type stubApp struct {
+	entryApp.App
+	updated *models.Entry
+	patched *models.Patch
+}
+
+func (a *stubApp) UpdateEntry(_ context.Context, e *models.Entry, _ bool) (*models.Entry, error) {
+	a.updated = e
+	return e, nil
+}
+
+func (a *stubApp) PatchEntry(_ context.Context, id string, p models.Patch, _ bool) (*models.Entry, error) {
+	a.patched = &p
+	return &models.Entry{ID: id, GrowthStage: *p.GrowthStage}, nil
+}
+
+func TestUpdateEntryGrowthStage(t *testing.T) {
+	tests := []struct {
+		name      string
+		stage     commonpb.GrowthStage
+		mask      []string
+		wantCode  connect.Code
+		wantPatch bool
+	}{
+		{"no mask, default stage", commonpb.GrowthStage_SEED, nil, 0, false},
+		{"no mask, other stage", commonpb.GrowthStage_BLOOM, nil, connect.CodeInvalidArgument, false},
+		{"stage in mask", commonpb.GrowthStage_BLOOM, []string{"growth_stage"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &stubApp{}
+			req := &entrypb.UpdateEntryRequest{EntryId: "entry-1", Title: "Moss", Content: "Grows.", GrowthStage: tt.stage}
+			if tt.mask != nil {
+				req.UpdateMask = &fieldmaskpb.FieldMask{Paths: tt.mask}
+			}
+
+			_, err := NewService(app).UpdateEntry(context.Background(), connect.NewRequest(req))
+			if tt.wantCode != 0 {
+				if connect.CodeOf(err) != tt.wantCode {
+					t.Errorf("UpdateEntry = %v, want code %v", err, tt.wantCode)
+				}
+				if app.updated != nil || app.patched != nil {
+					t.Error("UpdateEntry reached the app, want it refused first")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateEntry = %v", err)
+			}
+			if got := app.patched != nil; got != tt.wantPatch {
+				t.Errorf("patched = %v, want %v", got, tt.wantPatch)
+			}
+		})
+	}
+}
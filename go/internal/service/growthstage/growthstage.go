@@ -0,0 +1,37 @@
+package growthstage
+
+import (
+	commonpb "moss/go/internal/genproto/protobuf/common"
+	models "moss/go/internal/models/entry"
+)
+
+// FromProto and ToProto are the only mapping between proto and domain growth
+// stages; every service converts through them. Unknown proto values map to
+// invalid domain stages, which the apps reject.
+func FromProto(stage commonpb.GrowthStage) models.GrowthStage {
+	switch stage {
+	case commonpb.GrowthStage_SEED:
+		return models.GrowthStageSeed
+	case commonpb.GrowthStage_SPROUT:
+		return models.GrowthStageSprout
+	case commonpb.GrowthStage_BLOOM:
+		return models.GrowthStageBloom
+	case commonpb.GrowthStage_EVERGREEN:
+		return models.GrowthStageEvergreen
+	default:
+		return models.GrowthStage(stage.String())
+	}
+}
+
+func ToProto(stage models.GrowthStage) commonpb.GrowthStage {
+	switch stage {
+	case models.GrowthStageSprout:
+		return commonpb.GrowthStage_SPROUT
+	case models.GrowthStageBloom:
+		return commonpb.GrowthStage_BLOOM
+	case models.GrowthStageEvergreen:
+		return commonpb.GrowthStage_EVERGREEN
+	default:
+		return commonpb.GrowthStage_SEED
+	}
+}
@@ -0,0 +1,112 @@
+package link
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"connectrpc.com/connect"
+	linkpb "moss/go/internal/genproto/protobuf/link"
+	entryModels "moss/go/internal/models/entry"
+	"moss/go/internal/service/growthstage"
+)
+
+// ExportPath is where ExportHandler is served.
+const ExportPath = "/export/graph"
+
+// ExportHandler serves ExportGraph as a file download for GET requests, so that
+// graph tools and browsers can fetch exports without a Connect client. The
+// request fields are read from the query string, e.g.
+// ?format=graphml&root_entry_id=…&depth=2&direction=out&tags=project. The
+// caller must be authenticated before the handler runs; see
+// interceptors.NewHTTPAuth.
+func (s *Service) ExportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		msg, err := parseExportQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res, err := s.ExportGraph(r.Context(), connect.NewRequest(msg))
+		if err != nil {
+			http.Error(w, err.Error(), httpStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", res.Msg.ContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", res.Msg.Filename))
+		w.Header().Set("X-Graph-Truncated", strconv.FormatBool(res.Msg.Truncated))
+		w.Write(res.Msg.Data)
+	})
+}
+
+// parseExportQuery reads an ExportGraphRequest from r's query string.
+func parseExportQuery(r *http.Request) (*linkpb.ExportGraphRequest, error) {
+	query := r.URL.Query()
+	msg := &linkpb.ExportGraphRequest{
+		RootEntryId: query.Get("root_entry_id"),
+		Tags:        query.Get("tags"),
+	}
+
+	if v := query.Get("growth_stage"); v != "" {
+		stage, ok := entryModels.ParseGrowthStage(v)
+		if !ok {
+			return nil, errors.New("growth_stage must be seed, sprout, bloom or evergreen")
+		}
+		msg.GrowthStage = growthstage.ToProto(stage).Enum()
+	}
+
+	switch query.Get("format") {
+	case "", "dot":
+		msg.Format = linkpb.GraphFormat_GRAPH_FORMAT_DOT
+	case "graphml":
+		msg.Format = linkpb.GraphFormat_GRAPH_FORMAT_GRAPHML
+	case "json":
+		msg.Format = linkpb.GraphFormat_GRAPH_FORMAT_JSON
+	default:
+		return nil, errors.New("format must be dot, graphml or json")
+	}
+
+	switch query.Get("direction") {
+	case "", "both":
+		msg.Direction = linkpb.LinkDirection_LINK_DIRECTION_BOTH
+	case "out":
+		msg.Direction = linkpb.LinkDirection_LINK_DIRECTION_OUT
+	case "in":
+		msg.Direction = linkpb.LinkDirection_LINK_DIRECTION_IN
+	default:
+		return nil, errors.New("direction must be both, out or in")
+	}
+
+	if v := query.Get("depth"); v != "" {
+		depth, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, errors.New("depth must be a number")
+		}
+		msg.Depth = int32(depth)
+	}
+	return msg, nil
+}
+
+// httpStatus returns the HTTP status for an error from a LinkService handler.
+func httpStatus(err error) int {
+	switch connect.CodeOf(err) {
+	case connect.CodeInvalidArgument:
+		return http.StatusBadRequest
+	case connect.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case connect.CodePermissionDenied:
+		return http.StatusForbidden
+	case connect.CodeNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,25 @@
+package link
+
+import (
+	"moss/go/internal/auth"
+	"moss/go/internal/genproto/protobuf/link/linkconnect"
+)
+
+// ProcedureScopes maps each LinkService procedure to the scope
+// a personal access token needs to call it.
+var ProcedureScopes = map[string]auth.Scope{
+	linkconnect.LinkServiceCreateLinkProcedure:         auth.ScopeLinksWrite,
+	linkconnect.LinkServiceDeleteLinkProcedure:         auth.ScopeLinksWrite,
+	linkconnect.LinkServiceListLinksBySourceProcedure:  auth.ScopeLinksRead,
+	linkconnect.LinkServiceListLinksByTargetProcedure:  auth.ScopeLinksRead,
+	linkconnect.LinkServiceCountLinksBySourceProcedure: auth.ScopeLinksRead,
+	linkconnect.LinkServiceCountLinksByTargetProcedure: auth.ScopeLinksRead,
+	linkconnect.LinkServiceListPendingLinksProcedure:   auth.ScopeLinksRead,
+	linkconnect.LinkServiceCreateRelationProcedure:     auth.ScopeLinksWrite,
+	linkconnect.LinkServiceListRelationsProcedure:      auth.ScopeLinksRead,
+	linkconnect.LinkServiceDeleteRelationProcedure:     auth.ScopeLinksWrite,
+	linkconnect.LinkServiceGetNeighborhoodProcedure:    auth.ScopeLinksRead,
+	linkconnect.LinkServiceFindPathsProcedure:          auth.ScopeLinksRead,
+	linkconnect.LinkServiceGardenInsightsProcedure:     auth.ScopeLinksRead,
+	linkconnect.LinkServiceExportGraphProcedure:        auth.ScopeLinksRead,
+}
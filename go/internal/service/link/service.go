@@ -1,13 +1,18 @@
 package link
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
 	"connectrpc.com/connect"
 	linkApp "moss/go/internal/app/link"
+	"moss/go/internal/auth"
 	linkpb "moss/go/internal/genproto/protobuf/link"
+	"moss/go/internal/graphexport"
+	entryModels "moss/go/internal/models/entry"
 	models "moss/go/internal/models/link"
+	"moss/go/internal/service/growthstage"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -26,15 +31,23 @@ func (s *Service) CreateLink(ctx context.Context, req *connect.Request[linkpb.Cr
 	domainLink := &models.Link{
 		SourceEntryID: req.Msg.SourceEntryId,
 		TargetEntryID: req.Msg.TargetEntryId,
-		UserID:        req.Msg.UserId,
-		// CreatedAt is set by the repository/app, so we ignore req.CreatedAt if present.
+		Relation:      req.Msg.Relation,
+		// UserID is taken from the authenticated caller and CreatedAt is set by the repository.
 	}
 
 	created, err := s.app.CreateLink(ctx, domainLink)
 	if err != nil {
 		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
 		case linkApp.ErrInvalidLink:
 			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid link"))
+		case linkApp.ErrInvalidRelation, linkApp.ErrUnknownRelation:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
 		default:
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create link: %w", err))
 		}
@@ -47,13 +60,19 @@ func (s *Service) CreateLink(ctx context.Context, req *connect.Request[linkpb.Cr
 
 // DeleteLink implements the LinkServiceHandler interface
 func (s *Service) DeleteLink(ctx context.Context, req *connect.Request[linkpb.DeleteLinkRequest]) (*connect.Response[emptypb.Empty], error) {
-	err := s.app.DeleteLink(ctx, req.Msg.SourceEntryId, req.Msg.TargetEntryId)
+	err := s.app.DeleteLink(ctx, req.Msg.SourceEntryId, req.Msg.TargetEntryId, req.Msg.Relation)
 	if err != nil {
 		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
 		case linkApp.ErrUnauthorized:
 			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
 		case linkApp.ErrInvalidLink:
 			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("link not found"))
+		case linkApp.ErrInvalidRelation:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
 		default:
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to delete link: %w", err))
 		}
@@ -63,9 +82,20 @@ func (s *Service) DeleteLink(ctx context.Context, req *connect.Request[linkpb.De
 
 // ListLinksBySource implements the LinkServiceHandler interface
 func (s *Service) ListLinksBySource(ctx context.Context, req *connect.Request[linkpb.ListLinksBySourceRequest]) (*connect.Response[linkpb.ListLinksBySourceResponse], error) {
-	links, err := s.app.ListLinksBySource(ctx, req.Msg.SourceEntryId)
+	links, err := s.app.ListLinksBySource(ctx, req.Msg.SourceEntryId, req.Msg.Relation)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list links by source: %w", err))
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrInvalidRelation:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list links by source: %w", err))
+		}
 	}
 
 	protoLinks := make([]*linkpb.Link, len(links))
@@ -77,9 +107,20 @@ func (s *Service) ListLinksBySource(ctx context.Context, req *connect.Request[li
 
 // ListLinksByTarget implements the LinkServiceHandler interface
 func (s *Service) ListLinksByTarget(ctx context.Context, req *connect.Request[linkpb.ListLinksByTargetRequest]) (*connect.Response[linkpb.ListLinksByTargetResponse], error) {
-	links, err := s.app.ListLinksByTarget(ctx, req.Msg.TargetEntryId)
+	links, err := s.app.ListLinksByTarget(ctx, req.Msg.TargetEntryId, req.Msg.Relation)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list links by target: %w", err))
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrInvalidRelation:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list links by target: %w", err))
+		}
 	}
 
 	protoLinks := make([]*linkpb.Link, len(links))
@@ -91,22 +132,353 @@ func (s *Service) ListLinksByTarget(ctx context.Context, req *connect.Request[li
 
 // CountLinksBySource implements the LinkServiceHandler interface
 func (s *Service) CountLinksBySource(ctx context.Context, req *connect.Request[linkpb.CountLinksBySourceRequest]) (*connect.Response[linkpb.CountLinksBySourceResponse], error) {
-	count, err := s.app.CountLinksBySource(ctx, req.Msg.SourceEntryId)
+	count, err := s.app.CountLinksBySource(ctx, req.Msg.SourceEntryId, req.Msg.Relation)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to count links by source: %w", err))
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrInvalidRelation:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to count links by source: %w", err))
+		}
 	}
 	return connect.NewResponse(&linkpb.CountLinksBySourceResponse{Count: count}), nil
 }
 
 // CountLinksByTarget implements the LinkServiceHandler interface
 func (s *Service) CountLinksByTarget(ctx context.Context, req *connect.Request[linkpb.CountLinksByTargetRequest]) (*connect.Response[linkpb.CountLinksByTargetResponse], error) {
-	count, err := s.app.CountLinksByTarget(ctx, req.Msg.TargetEntryId)
+	count, err := s.app.CountLinksByTarget(ctx, req.Msg.TargetEntryId, req.Msg.Relation)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to count links by target: %w", err))
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrInvalidRelation:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to count links by target: %w", err))
+		}
 	}
 	return connect.NewResponse(&linkpb.CountLinksByTargetResponse{Count: count}), nil
 }
 
+// ListPendingLinks implements the LinkServiceHandler interface
+func (s *Service) ListPendingLinks(ctx context.Context, req *connect.Request[linkpb.ListPendingLinksRequest]) (*connect.Response[linkpb.ListPendingLinksResponse], error) {
+	pending, err := s.app.ListPendingLinks(ctx, req.Msg.SourceEntryId)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list pending links: %w", err))
+		}
+	}
+
+	protoPending := make([]*linkpb.PendingLink, len(pending))
+	for i, p := range pending {
+		protoPending[i] = &linkpb.PendingLink{
+			SourceEntryId: p.SourceEntryID,
+			TargetTitle:   p.TargetTitle,
+			CreatedAt:     timestamppb.New(p.CreatedAt),
+		}
+	}
+	return connect.NewResponse(&linkpb.ListPendingLinksResponse{PendingLinks: protoPending}), nil
+}
+
+// CreateRelation implements the LinkServiceHandler interface
+func (s *Service) CreateRelation(ctx context.Context, req *connect.Request[linkpb.CreateRelationRequest]) (*connect.Response[linkpb.CreateRelationResponse], error) {
+	created, err := s.app.CreateRelation(ctx, req.Msg.Name, req.Msg.InverseName)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrInvalidRelation:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		case linkApp.ErrRelationExists:
+			return nil, connect.NewError(connect.CodeAlreadyExists, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create relation: %w", err))
+		}
+	}
+	return connect.NewResponse(&linkpb.CreateRelationResponse{Relation: toProtoRelation(created)}), nil
+}
+
+// ListRelations implements the LinkServiceHandler interface
+func (s *Service) ListRelations(ctx context.Context, req *connect.Request[linkpb.ListRelationsRequest]) (*connect.Response[linkpb.ListRelationsResponse], error) {
+	relations, err := s.app.ListRelations(ctx)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list relations: %w", err))
+		}
+	}
+
+	protoRelations := make([]*linkpb.Relation, len(relations))
+	for i, r := range relations {
+		protoRelations[i] = toProtoRelation(r)
+	}
+	return connect.NewResponse(&linkpb.ListRelationsResponse{Relations: protoRelations}), nil
+}
+
+// DeleteRelation implements the LinkServiceHandler interface
+func (s *Service) DeleteRelation(ctx context.Context, req *connect.Request[linkpb.DeleteRelationRequest]) (*connect.Response[emptypb.Empty], error) {
+	err := s.app.DeleteRelation(ctx, req.Msg.Name)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrRelationNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrRelationInUse:
+			return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to delete relation: %w", err))
+		}
+	}
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+// GetNeighborhood implements the LinkServiceHandler interface
+func (s *Service) GetNeighborhood(ctx context.Context, req *connect.Request[linkpb.GetNeighborhoodRequest]) (*connect.Response[linkpb.GetNeighborhoodResponse], error) {
+	query := models.NeighborhoodQuery{
+		EntryID:   req.Msg.EntryId,
+		Depth:     int(req.Msg.Depth),
+		Direction: fromProtoDirection(req.Msg.Direction),
+		Relation:  req.Msg.Relation,
+		Tags:      req.Msg.Tags,
+		MaxNodes:  int(req.Msg.MaxNodes),
+		MaxEdges:  int(req.Msg.MaxEdges),
+	}
+	if req.Msg.GrowthStage != nil {
+		query.GrowthStage = string(growthstage.FromProto(*req.Msg.GrowthStage))
+	}
+	graph, err := s.app.GetNeighborhood(ctx, query)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrInvalidDepth, linkApp.ErrInvalidDirection, linkApp.ErrInvalidRelation,
+			linkApp.ErrInvalidGrowthStage, linkApp.ErrInvalidTagExpression, linkApp.ErrInvalidLimit:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get neighborhood: %w", err))
+		}
+	}
+
+	nodes := make([]*linkpb.GraphNode, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		nodes[i] = &linkpb.GraphNode{
+			EntryId:     n.EntryID,
+			Title:       n.Title,
+			GrowthStage: growthstage.ToProto(entryModels.GrowthStage(n.GrowthStage)),
+			Depth:       int32(n.Depth),
+		}
+	}
+	edges := make([]*linkpb.Link, len(graph.Edges))
+	for i, l := range graph.Edges {
+		edges[i] = toProtoLink(l)
+	}
+	return connect.NewResponse(&linkpb.GetNeighborhoodResponse{
+		Nodes:     nodes,
+		Edges:     edges,
+		Truncated: graph.Truncated,
+	}), nil
+}
+
+// FindPaths implements the LinkServiceHandler interface
+func (s *Service) FindPaths(ctx context.Context, req *connect.Request[linkpb.FindPathsRequest]) (*connect.Response[linkpb.FindPathsResponse], error) {
+	paths, err := s.app.FindPaths(ctx, models.PathQuery{
+		FromEntryID: req.Msg.FromEntryId,
+		ToEntryID:   req.Msg.ToEntryId,
+		Undirected:  req.Msg.Undirected,
+		MaxDepth:    int(req.Msg.MaxDepth),
+	}, int(req.Msg.Alternatives))
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrInvalidPathDepth, linkApp.ErrInvalidLimit:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		case linkApp.ErrPathSearchTimeout:
+			return nil, connect.NewError(connect.CodeDeadlineExceeded, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to find paths: %w", err))
+		}
+	}
+
+	protoPaths := make([]*linkpb.Path, len(paths))
+	for i, p := range paths {
+		steps := make([]*linkpb.PathStep, len(p.Steps))
+		for j, step := range p.Steps {
+			steps[j] = &linkpb.PathStep{
+				EntryId:  step.EntryID,
+				Title:    step.Title,
+				Relation: step.Relation,
+				Reversed: step.Reversed,
+			}
+		}
+		protoPaths[i] = &linkpb.Path{Steps: steps}
+	}
+	return connect.NewResponse(&linkpb.FindPathsResponse{Paths: protoPaths}), nil
+}
+
+// GardenInsights implements the LinkServiceHandler interface
+func (s *Service) GardenInsights(ctx context.Context, req *connect.Request[linkpb.GardenInsightsRequest]) (*connect.Response[linkpb.GardenInsightsResponse], error) {
+	insights, err := s.app.GardenInsights(ctx, int(req.Msg.Top))
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrInvalidLimit:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to compute garden insights: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&linkpb.GardenInsightsResponse{
+		EntryCount:     int32(insights.EntryCount),
+		LinkCount:      int32(insights.LinkCount),
+		Orphans:        toProtoInsightEntries(insights.Orphans),
+		DeadEnds:       toProtoInsightEntries(insights.DeadEnds),
+		TopByInDegree:  toProtoRankedEntries(insights.TopByInDegree),
+		TopByOutDegree: toProtoRankedEntries(insights.TopByOutDegree),
+		TopByPagerank:  toProtoRankedEntries(insights.TopByPageRank),
+		Components:     toProtoClusters(insights.Components),
+		Communities:    toProtoClusters(insights.Communities),
+		ComputedAt:     timestamppb.New(insights.ComputedAt),
+	}), nil
+}
+
+// ExportGraph implements the LinkServiceHandler interface
+func (s *Service) ExportGraph(ctx context.Context, req *connect.Request[linkpb.ExportGraphRequest]) (*connect.Response[linkpb.ExportGraphResponse], error) {
+	format, ok := fromProtoFormat(req.Msg.Format)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("unknown graph format %v", req.Msg.Format))
+	}
+	query := models.ExportQuery{
+		RootEntryID: req.Msg.RootEntryId,
+		Depth:       int(req.Msg.Depth),
+		Direction:   fromProtoDirection(req.Msg.Direction),
+		Tags:        req.Msg.Tags,
+	}
+	if req.Msg.GrowthStage != nil {
+		query.GrowthStage = string(growthstage.FromProto(*req.Msg.GrowthStage))
+	}
+	graph, err := s.app.ExportGraph(ctx, query)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case linkApp.ErrUnauthorized:
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("unauthorized access"))
+		case linkApp.ErrEntryNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		case linkApp.ErrInvalidDepth, linkApp.ErrInvalidDirection,
+			linkApp.ErrInvalidGrowthStage, linkApp.ErrInvalidTagExpression:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to export graph: %w", err))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := graphexport.Write(&buf, format, graph); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to export graph: %w", err))
+	}
+	return connect.NewResponse(&linkpb.ExportGraphResponse{
+		Data:        buf.Bytes(),
+		ContentType: format.ContentType(),
+		Filename:    "garden" + format.Extension(),
+		Truncated:   graph.Truncated,
+	}), nil
+}
+
+func toProtoInsightEntries(nodes []*models.Node) []*linkpb.InsightEntry {
+	entries := make([]*linkpb.InsightEntry, len(nodes))
+	for i, n := range nodes {
+		entries[i] = &linkpb.InsightEntry{
+			EntryId:     n.EntryID,
+			Title:       n.Title,
+			GrowthStage: growthstage.ToProto(entryModels.GrowthStage(n.GrowthStage)),
+		}
+	}
+	return entries
+}
+
+func toProtoRankedEntries(ranked []*models.RankedNode) []*linkpb.RankedEntry {
+	entries := make([]*linkpb.RankedEntry, len(ranked))
+	for i, r := range ranked {
+		entries[i] = &linkpb.RankedEntry{
+			Entry: toProtoInsightEntries([]*models.Node{r.Node})[0],
+			Score: r.Score,
+		}
+	}
+	return entries
+}
+
+func toProtoClusters(clusters [][]*models.Node) []*linkpb.EntryCluster {
+	protoClusters := make([]*linkpb.EntryCluster, len(clusters))
+	for i, c := range clusters {
+		protoClusters[i] = &linkpb.EntryCluster{Entries: toProtoInsightEntries(c)}
+	}
+	return protoClusters
+}
+
+func fromProtoDirection(direction linkpb.LinkDirection) models.Direction {
+	switch direction {
+	case linkpb.LinkDirection_LINK_DIRECTION_OUT:
+		return models.DirectionOut
+	case linkpb.LinkDirection_LINK_DIRECTION_IN:
+		return models.DirectionIn
+	default:
+		return models.DirectionBoth
+	}
+}
+
+func fromProtoFormat(format linkpb.GraphFormat) (graphexport.Format, bool) {
+	switch format {
+	case linkpb.GraphFormat_GRAPH_FORMAT_DOT:
+		return graphexport.FormatDOT, true
+	case linkpb.GraphFormat_GRAPH_FORMAT_GRAPHML:
+		return graphexport.FormatGraphML, true
+	case linkpb.GraphFormat_GRAPH_FORMAT_JSON:
+		return graphexport.FormatJSON, true
+	default:
+		return "", false
+	}
+}
+
+func toProtoRelation(domain *models.Relation) *linkpb.Relation {
+	return &linkpb.Relation{
+		Name:        domain.Name,
+		InverseName: domain.InverseName,
+		CreatedAt:   timestamppb.New(domain.CreatedAt),
+	}
+}
+
 // toProtoLink converts a domain Link into a proto Link.
 func toProtoLink(domain *models.Link) *linkpb.Link {
 	return &linkpb.Link{
@@ -114,5 +486,14 @@ func toProtoLink(domain *models.Link) *linkpb.Link {
 		TargetEntryId: domain.TargetEntryID,
 		UserId:        domain.UserID,
 		CreatedAt:     timestamppb.New(domain.CreatedAt),
+		Origin:        toProtoOrigin(domain.Origin),
+		Relation:      domain.Relation,
+	}
+}
+
+func toProtoOrigin(origin models.Origin) linkpb.LinkOrigin {
+	if origin == models.OriginContent {
+		return linkpb.LinkOrigin_LINK_ORIGIN_CONTENT
 	}
+	return linkpb.LinkOrigin_LINK_ORIGIN_MANUAL
 }
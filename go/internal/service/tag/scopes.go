@@ -0,0 +1,15 @@
+package tag
+
+import (
+	"moss/go/internal/auth"
+	"moss/go/internal/genproto/protobuf/tag/tagconnect"
+)
+
+// ProcedureScopes maps each TagService procedure to the scope
+// a personal access token needs to call it. Tags live in entry
+// content, so they share the entry scopes.
+var ProcedureScopes = map[string]auth.Scope{
+	tagconnect.TagServiceListTagsProcedure:  auth.ScopeEntriesRead,
+	tagconnect.TagServiceRenameTagProcedure: auth.ScopeEntriesWrite,
+	tagconnect.TagServiceMergeTagsProcedure: auth.ScopeEntriesWrite,
+}
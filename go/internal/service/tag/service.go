@@ -0,0 +1,87 @@
+package tag
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	tagApp "moss/go/internal/app/tag"
+	"moss/go/internal/auth"
+	tagpb "moss/go/internal/genproto/protobuf/tag"
+	models "moss/go/internal/models/tag"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements the TagServiceHandler interface
+type Service struct {
+	app tagApp.App
+}
+
+// NewService constructs a new Connect service for tags.
+func NewService(app tagApp.App) *Service {
+	return &Service{app: app}
+}
+
+// ListTags implements the TagServiceHandler interface
+func (s *Service) ListTags(ctx context.Context, req *connect.Request[tagpb.ListTagsRequest]) (*connect.Response[tagpb.ListTagsResponse], error) {
+	tags, err := s.app.ListTags(ctx)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list tags: %w", err))
+		}
+	}
+
+	protoTags := make([]*tagpb.Tag, len(tags))
+	for i, t := range tags {
+		protoTags[i] = toProtoTag(t)
+	}
+	return connect.NewResponse(&tagpb.ListTagsResponse{Tags: protoTags}), nil
+}
+
+// RenameTag implements the TagServiceHandler interface
+func (s *Service) RenameTag(ctx context.Context, req *connect.Request[tagpb.RenameTagRequest]) (*connect.Response[tagpb.RenameTagResponse], error) {
+	updated, err := s.app.RenameTag(ctx, req.Msg.Name, req.Msg.NewName)
+	if err != nil {
+		return nil, retagError("rename tag", err)
+	}
+	return connect.NewResponse(&tagpb.RenameTagResponse{UpdatedEntryCount: updated}), nil
+}
+
+// MergeTags implements the TagServiceHandler interface
+func (s *Service) MergeTags(ctx context.Context, req *connect.Request[tagpb.MergeTagsRequest]) (*connect.Response[tagpb.MergeTagsResponse], error) {
+	updated, err := s.app.MergeTags(ctx, req.Msg.Names, req.Msg.Into)
+	if err != nil {
+		return nil, retagError("merge tags", err)
+	}
+	return connect.NewResponse(&tagpb.MergeTagsResponse{UpdatedEntryCount: updated}), nil
+}
+
+// retagError maps an error from RenameTag or MergeTags to a Connect error.
+func retagError(action string, err error) *connect.Error {
+	switch err {
+	case auth.ErrUnauthenticated:
+		return connect.NewError(connect.CodeUnauthenticated, err)
+	case tagApp.ErrInvalidTag:
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case tagApp.ErrTagNotFound:
+		return connect.NewError(connect.CodeNotFound, err)
+	case tagApp.ErrTagExists:
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	default:
+		return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to %s: %w", action, err))
+	}
+}
+
+// toProtoTag converts a domain Tag into a proto Tag.
+func toProtoTag(domain *models.Tag) *tagpb.Tag {
+	return &tagpb.Tag{
+		Name:       domain.Name,
+		Parent:     domain.Parent(),
+		EntryCount: domain.EntryCount,
+		CreatedAt:  timestamppb.New(domain.CreatedAt),
+	}
+}
@@ -0,0 +1,124 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+	tokenApp "moss/go/internal/app/token"
+	"moss/go/internal/auth"
+	tokenpb "moss/go/internal/genproto/protobuf/token"
+	models "moss/go/internal/models/token"
+	tokenRepo "moss/go/internal/repository/token"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements the TokenServiceHandler interface
+type Service struct {
+	app tokenApp.App
+}
+
+// NewService constructs a new Connect service for personal access tokens.
+func NewService(app tokenApp.App) *Service {
+	return &Service{app: app}
+}
+
+// CreateToken implements the TokenServiceHandler interface
+func (s *Service) CreateToken(ctx context.Context, req *connect.Request[tokenpb.CreateTokenRequest]) (*connect.Response[tokenpb.CreateTokenResponse], error) {
+	domainToken := &models.Token{
+		Name:   req.Msg.Name,
+		Scopes: make([]auth.Scope, len(req.Msg.Scopes)),
+	}
+	for i, scope := range req.Msg.Scopes {
+		domainToken.Scopes[i] = auth.Scope(scope)
+	}
+	if req.Msg.ExpiresAt != nil {
+		expiresAt := req.Msg.ExpiresAt.AsTime()
+		domainToken.ExpiresAt = &expiresAt
+	}
+
+	created, secret, err := s.app.CreateToken(ctx, domainToken)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case tokenApp.ErrSessionOnly:
+			return nil, connect.NewError(connect.CodePermissionDenied, err)
+		case tokenApp.ErrInvalidToken:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create token: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&tokenpb.CreateTokenResponse{
+		Token:  toProtoToken(created),
+		Secret: secret,
+	}), nil
+}
+
+// ListTokens implements the TokenServiceHandler interface
+func (s *Service) ListTokens(ctx context.Context, req *connect.Request[tokenpb.ListTokensRequest]) (*connect.Response[tokenpb.ListTokensResponse], error) {
+	tokens, err := s.app.ListTokens(ctx)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case tokenApp.ErrSessionOnly:
+			return nil, connect.NewError(connect.CodePermissionDenied, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list tokens: %w", err))
+		}
+	}
+
+	protoTokens := make([]*tokenpb.Token, len(tokens))
+	for i, t := range tokens {
+		protoTokens[i] = toProtoToken(t)
+	}
+	return connect.NewResponse(&tokenpb.ListTokensResponse{Tokens: protoTokens}), nil
+}
+
+// RevokeToken implements the TokenServiceHandler interface
+func (s *Service) RevokeToken(ctx context.Context, req *connect.Request[tokenpb.RevokeTokenRequest]) (*connect.Response[emptypb.Empty], error) {
+	if err := s.app.RevokeToken(ctx, req.Msg.TokenId); err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case tokenApp.ErrSessionOnly:
+			return nil, connect.NewError(connect.CodePermissionDenied, err)
+		case tokenRepo.ErrTokenNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to revoke token: %w", err))
+		}
+	}
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+// toProtoToken converts a domain Token into a proto Token. The hash is never exposed.
+func toProtoToken(domain *models.Token) *tokenpb.Token {
+	scopes := make([]string, len(domain.Scopes))
+	for i, s := range domain.Scopes {
+		scopes[i] = string(s)
+	}
+
+	return &tokenpb.Token{
+		Id:         domain.ID,
+		Name:       domain.Name,
+		Scopes:     scopes,
+		CreatedAt:  timestamppb.New(domain.CreatedAt),
+		ExpiresAt:  toProtoTimestamp(domain.ExpiresAt),
+		LastUsedAt: toProtoTimestamp(domain.LastUsedAt),
+		RevokedAt:  toProtoTimestamp(domain.RevokedAt),
+	}
+}
+
+func toProtoTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
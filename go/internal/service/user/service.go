@@ -0,0 +1,167 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	userApp "moss/go/internal/app/user"
+	"moss/go/internal/auth"
+	userpb "moss/go/internal/genproto/protobuf/user"
+	models "moss/go/internal/models/user"
+	userRepo "moss/go/internal/repository/user"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Service implements the UserServiceHandler interface
+type Service struct {
+	app userApp.App
+}
+
+// NewService constructs a new Connect service for user accounts.
+func NewService(app userApp.App) *Service {
+	return &Service{app: app}
+}
+
+// SignUp implements the UserServiceHandler interface
+func (s *Service) SignUp(ctx context.Context, req *connect.Request[userpb.SignUpRequest]) (*connect.Response[userpb.SignUpResponse], error) {
+	domainUser := &models.User{
+		Email:       req.Msg.Email,
+		DisplayName: req.Msg.DisplayName,
+		GardenSlug:  req.Msg.GardenSlug,
+	}
+
+	created, tokens, err := s.app.SignUp(ctx, domainUser, req.Msg.Password)
+	if err != nil {
+		switch err {
+		case userApp.ErrInvalidUser, userApp.ErrInvalidPassword:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		case userRepo.ErrEmailTaken, userRepo.ErrGardenSlugTaken:
+			return nil, connect.NewError(connect.CodeAlreadyExists, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to sign up: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&userpb.SignUpResponse{
+		User:    toProtoUser(created),
+		Session: toProtoSession(tokens),
+	}), nil
+}
+
+// Login implements the UserServiceHandler interface
+func (s *Service) Login(ctx context.Context, req *connect.Request[userpb.LoginRequest]) (*connect.Response[userpb.LoginResponse], error) {
+	u, tokens, err := s.app.Login(ctx, req.Msg.Email, req.Msg.Password)
+	if err != nil {
+		switch err {
+		case userApp.ErrInvalidCredentials:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to log in: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&userpb.LoginResponse{
+		User:    toProtoUser(u),
+		Session: toProtoSession(tokens),
+	}), nil
+}
+
+// RefreshSession implements the UserServiceHandler interface
+func (s *Service) RefreshSession(ctx context.Context, req *connect.Request[userpb.RefreshSessionRequest]) (*connect.Response[userpb.RefreshSessionResponse], error) {
+	tokens, err := s.app.RefreshSession(ctx, req.Msg.RefreshToken)
+	if err != nil {
+		switch err {
+		case userApp.ErrInvalidSession:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to refresh session: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&userpb.RefreshSessionResponse{
+		Session: toProtoSession(tokens),
+	}), nil
+}
+
+// Logout implements the UserServiceHandler interface
+func (s *Service) Logout(ctx context.Context, req *connect.Request[userpb.LogoutRequest]) (*connect.Response[emptypb.Empty], error) {
+	if err := s.app.Logout(ctx, req.Msg.AllSessions); err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case userApp.ErrSessionOnly:
+			return nil, connect.NewError(connect.CodePermissionDenied, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to log out: %w", err))
+		}
+	}
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+// GetProfile implements the UserServiceHandler interface
+func (s *Service) GetProfile(ctx context.Context, req *connect.Request[userpb.GetProfileRequest]) (*connect.Response[userpb.GetProfileResponse], error) {
+	u, err := s.app.GetProfile(ctx)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case userRepo.ErrUserNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get profile: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&userpb.GetProfileResponse{
+		User: toProtoUser(u),
+	}), nil
+}
+
+// UpdateProfile implements the UserServiceHandler interface
+func (s *Service) UpdateProfile(ctx context.Context, req *connect.Request[userpb.UpdateProfileRequest]) (*connect.Response[userpb.UpdateProfileResponse], error) {
+	u, err := s.app.UpdateProfile(ctx, req.Msg.DisplayName, req.Msg.GardenSlug)
+	if err != nil {
+		switch err {
+		case auth.ErrUnauthenticated:
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		case userApp.ErrInvalidUser:
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		case userRepo.ErrGardenSlugTaken:
+			return nil, connect.NewError(connect.CodeAlreadyExists, err)
+		case userRepo.ErrUserNotFound:
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		default:
+			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to update profile: %w", err))
+		}
+	}
+
+	return connect.NewResponse(&userpb.UpdateProfileResponse{
+		User: toProtoUser(u),
+	}), nil
+}
+
+// toProtoUser converts a domain User into a proto User. The password hash is never exposed.
+func toProtoUser(domain *models.User) *userpb.User {
+	return &userpb.User{
+		Id:          domain.ID,
+		Email:       domain.Email,
+		DisplayName: domain.DisplayName,
+		GardenSlug:  domain.GardenSlug,
+		CreatedAt:   timestamppb.New(domain.CreatedAt),
+		UpdatedAt:   timestamppb.New(domain.UpdatedAt),
+	}
+}
+
+// toProtoSession converts issued session tokens into a proto Session.
+func toProtoSession(tokens *models.SessionTokens) *userpb.Session {
+	return &userpb.Session{
+		SessionId:             tokens.SessionID,
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  timestamppb.New(tokens.AccessTokenExpiresAt),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(tokens.RefreshTokenExpiresAt),
+	}
+}
@@ -0,0 +1,194 @@
+package tagexpr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"moss/go/internal/hashtag"
+)
+
+// Expr is a parsed tag expression such as "lang/go AND (web OR NOT draft)".
+type Expr interface {
+	// Match reports whether an entry satisfies the expression. has reports
+	// whether the entry has a tag, directly or through a tag nested under it.
+	Match(has func(tag string) bool) bool
+}
+
+var ErrEmpty = errors.New("empty tag expression")
+
+// Parse parses a tag expression. Terms are tag names, with or without the
+// leading '#'; they combine with AND, OR and NOT (in decreasing order of
+// precedence: NOT, AND, OR) and parentheses. Adjacent terms are ANDed. The
+// operators are case-insensitive; write a tag named like one as "#and".
+func Parse(s string) (Expr, error) {
+	p := &parser{tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, ErrEmpty
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q in tag expression", tok)
+	}
+	return expr, nil
+}
+
+type tagTerm string
+
+func (t tagTerm) Match(has func(string) bool) bool { return has(string(t)) }
+
+type notExpr struct{ x Expr }
+
+func (n notExpr) Match(has func(string) bool) bool { return !n.x.Match(has) }
+
+type andExpr []Expr
+
+func (a andExpr) Match(has func(string) bool) bool {
+	for _, x := range a {
+		if !x.Match(has) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpr []Expr
+
+func (o orExpr) Match(has func(string) bool) bool {
+	for _, x := range o {
+		if x.Match(has) {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (string, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return tok, ok
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := orExpr{x}
+	for {
+		tok, ok := p.peek()
+		if !ok || !strings.EqualFold(tok, "OR") {
+			break
+		}
+		p.pos++
+		x, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, x)
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	terms := andExpr{x}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok == ")" || strings.EqualFold(tok, "OR") {
+			break
+		}
+		if strings.EqualFold(tok, "AND") {
+			p.pos++
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, x)
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	tok, ok := p.next()
+	switch {
+	case !ok:
+		return nil, errors.New("tag expression ends unexpectedly")
+	case strings.EqualFold(tok, "NOT"):
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	case tok == "(":
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, _ := p.next(); closing != ")" {
+			return nil, errors.New("missing ')' in tag expression")
+		}
+		return x, nil
+	case tok == ")" || strings.EqualFold(tok, "AND") || strings.EqualFold(tok, "OR"):
+		return nil, fmt.Errorf("unexpected %q in tag expression", tok)
+	}
+
+	name, ok := hashtag.Normalize(tok)
+	if !ok {
+		return nil, fmt.Errorf("invalid tag %q in tag expression", tok)
+	}
+	return tagTerm(name), nil
+}
+
+// tokenize splits s into parentheses and whitespace-separated words.
+func tokenize(s string) []string {
+	var tokens []string
+	word := strings.Builder{}
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
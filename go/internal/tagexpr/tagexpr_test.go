@@ -0,0 +1,101 @@
+package tagexpr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMatch(t *testing.T) {
+	tests := []struct {
+		expr  string
+		tags  string // The entry's tags, space-separated
+		match bool
+	}{
+		{"go", "go", true},
+		{"go", "rust", false},
+		{"#Go", "go", true},
+		{"lang/go", "lang/go", true},
+		{"#Lang/Go/", "lang/go", true},
+
+		{"go AND web", "go web", true},
+		{"go AND web", "go", false},
+		{"go web", "go", false}, // Adjacent terms are ANDed
+		{"go web", "go web", true},
+		{"go OR web", "web", true},
+		{"go OR web", "", false},
+		{"NOT draft", "", true},
+		{"NOT draft", "draft", false},
+		{"NOT NOT draft", "draft", true},
+		{"go and web", "go web", true}, // Operators are case-insensitive
+		{"go or web", "web", true},
+		{"not draft", "draft", false},
+		{"#and", "and", true}, // A tag named like an operator
+
+		// AND binds tighter than OR.
+		{"a OR b AND c", "a", true},
+		{"a OR b AND c", "b", false},
+		{"a OR b AND c", "b c", true},
+		{"a AND b OR c", "c", true},
+		{"a AND b OR c", "a", false},
+		{"a b OR c", "c", true},
+		{"(a OR b) AND c", "a", false},
+		{"(a OR b) AND c", "a c", true},
+
+		// NOT binds tighter than AND and OR.
+		{"NOT a AND b", "b", true},
+		{"NOT a AND b", "a b", false},
+		{"NOT a OR b", "a b", true},
+		{"NOT a OR b", "a", false},
+		{"NOT (a AND b)", "a", true},
+		{"NOT (a AND b)", "a b", false},
+		{"lang/go AND (web OR NOT draft)", "lang/go draft", false},
+		{"lang/go AND (web OR NOT draft)", "lang/go draft web", true},
+		{"lang/go AND (web OR NOT draft)", "lang/go", true},
+		{"((a))", "a", true},
+		{"a(b)", "a b", true},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", tt.expr, err)
+			continue
+		}
+		tags := make(map[string]bool)
+		for _, tag := range strings.Fields(tt.tags) {
+			tags[tag] = true
+		}
+		if got := expr.Match(func(tag string) bool { return tags[tag] }); got != tt.match {
+			t.Errorf("Parse(%q).Match(%q) = %v, want %v", tt.expr, tt.tags, got, tt.match)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"  \t",
+		"AND",
+		"go AND",
+		"go OR",
+		"OR go",
+		"go OR OR web",
+		"go AND OR web",
+		"NOT",
+		"(go",
+		"go)",
+		"()",
+		"(go OR) web",
+		"#12",     // All-digit tags are issue numbers
+		"go//web", // Empty nested tag
+		"go!",
+		"#",
+	}
+	for _, s := range tests {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", s)
+		}
+	}
+	if _, err := Parse(" "); err != ErrEmpty {
+		t.Errorf("Parse(%q) = %v, want ErrEmpty", " ", err)
+	}
+}
@@ -0,0 +1,110 @@
+package tsquery
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FromSearch converts a search box query into Postgres to_tsquery syntax.
+// Words must all match; "quoted words" must match as a phrase; OR between
+// terms matches either side; a leading '-' excludes a term; a trailing '*'
+// matches any word with that prefix. The last word is always prefix-matched
+// unless the query ends with a space, so results can follow the user's typing.
+// Punctuation separates words. FromSearch returns "" if s has no words.
+func FromSearch(s string) string {
+	var terms []string
+	var ops []string // ops[i] joins terms[i] and terms[i+1]
+	nextOp := " & "
+
+	trailingSpace := len(s) > 0 && isSpace(s[len(s)-1])
+	for i := 0; i < len(s); {
+		if isSpace(s[i]) {
+			i++
+			continue
+		}
+
+		negate := false
+		if s[i] == '-' {
+			negate = true
+			i++
+		}
+
+		var term string
+		if i < len(s) && s[i] == '"' {
+			end := strings.IndexByte(s[i+1:], '"')
+			var phrase string
+			if end < 0 {
+				phrase, i = s[i+1:], len(s)
+			} else {
+				phrase, i = s[i+1:i+1+end], i+2+end
+			}
+			term = joinWords(words(phrase), " <-> ", false)
+		} else {
+			end := i
+			for end < len(s) && !isSpace(s[end]) {
+				end++
+			}
+			word := s[i:end]
+			i = end
+
+			if word == "OR" && len(terms) > 0 {
+				nextOp = " | "
+				continue
+			}
+			prefix := strings.HasSuffix(word, "*") || (i == len(s) && !trailingSpace)
+			term = joinWords(words(word), " & ", prefix)
+		}
+		if term == "" {
+			continue
+		}
+
+		if negate {
+			term = "!" + term
+		}
+		if len(terms) > 0 {
+			ops = append(ops, nextOp)
+		}
+		terms = append(terms, term)
+		nextOp = " & "
+	}
+
+	var buf strings.Builder
+	for i, term := range terms {
+		if i > 0 {
+			buf.WriteString(ops[i-1])
+		}
+		buf.WriteString(term)
+	}
+	return buf.String()
+}
+
+// isSpace reports whether b is ASCII whitespace, which separates search terms.
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\v'
+}
+
+// words splits s into runs of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// joinWords quotes each word as a lexeme and joins them with op, parenthesized
+// if there are several. If prefix is set, the last word matches as a prefix.
+func joinWords(ws []string, op string, prefix bool) string {
+	if len(ws) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(ws))
+	for i, w := range ws {
+		quoted[i] = "'" + w + "'"
+	}
+	if prefix {
+		quoted[len(quoted)-1] += ":*"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return "(" + strings.Join(quoted, op) + ")"
+}
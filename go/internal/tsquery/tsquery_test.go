@@ -0,0 +1,45 @@
+package tsquery
+
+import "testing"
+
+func TestFromSearch(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"empty", "", ""},
+		{"blank", " \t\n", ""},
+		{"punctuation only", "!!! -- \"\" ", ""},
+		{"last word is a prefix", "garden", "'garden':*"},
+		{"trailing space ends the word", "garden ", "'garden'"},
+		{"words are ANDed", "digital garden", "'digital' & 'garden':*"},
+		{"explicit prefix", "gard* notes ", "'gard':* & 'notes'"},
+		{"OR", "go OR rust ", "'go' | 'rust'"},
+		{"OR is case-sensitive", "go or rust ", "'go' & 'or' & 'rust'"},
+		{"leading OR is a word", "OR go ", "'OR' & 'go'"},
+		{"trailing OR is dropped", "go OR", "'go'"},
+		{"repeated OR", "go OR OR rust ", "'go' | 'rust'"},
+		{"exclude", "-draft notes ", "!'draft' & 'notes'"},
+		{"OR with exclude", "go OR -rust ", "'go' | !'rust'"},
+		{"lone minus", "- go ", "'go'"},
+		{"phrase", "\"digital garden\" ", "('digital' <-> 'garden')"},
+		{"phrase is never a prefix", "\"digital garden\"", "('digital' <-> 'garden')"},
+		{"unclosed phrase", "\"digital garden", "('digital' <-> 'garden')"},
+		{"excluded phrase", "notes -\"to do\" ", "'notes' & !('to' <-> 'do')"},
+		{"punctuation splits words", "e-mail ", "('e' & 'mail')"},
+		{"split last word is a prefix", "e-mail", "('e' & 'mail':*)"},
+		{"quotes cannot escape", "a' | 'b", "'a' & 'b':*"},
+		{"operators are not passed through", "a & !b | (c) <-> d:* ", "'a' & 'b' & 'c' & 'd':*"},
+		{"backslashes", `a\'b `, "('a' & 'b')"},
+		{"unicode", "café crème", "'café' & 'crème':*"},
+		{"digits", "2024 ", "'2024'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromSearch(tt.s); got != tt.want {
+				t.Errorf("FromSearch(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package wikilink
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mention is a plain-text occurrence of an entry's name in Markdown content.
+type Mention struct {
+	Start int // Byte offset of the first character
+	End   int // Byte offset just past the last character
+}
+
+// Mentions returns the occurrences of any of names in content that are not
+// linked yet, in order of appearance. Names match case-insensitively and as
+// whole words; where several match at one place, the longest wins. Text inside
+// wiki-links, fenced code blocks and inline code spans is ignored.
+func Mentions(content string, names []string) []Mention {
+	var sorted []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			sorted = append(sorted, name)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	var mentions []Mention
+	inFence := false
+	fence := ""
+
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+			} else if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		mentions = append(mentions, mentionsInLine(line, lineStart, sorted)...)
+	}
+	return mentions
+}
+
+// mentionsInLine finds the mentions of names, longest first, in one line outside a fenced code block.
+func mentionsInLine(line string, lineStart int, names []string) []Mention {
+	var mentions []Mention
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == '`':
+			i = skipCodeSpan(line, i)
+			continue
+
+		case strings.HasPrefix(line[i:], "[["):
+			end := strings.Index(line[i+2:], "]]")
+			if end < 0 {
+				return mentions
+			}
+			i += 2 + end + 2
+			continue
+		}
+
+		if i == 0 || !isWordRune(lastRune(line[:i])) {
+			if n := matchName(line[i:], names); n > 0 {
+				mentions = append(mentions, Mention{Start: lineStart + i, End: lineStart + i + n})
+				i += n
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+	}
+	return mentions
+}
+
+// matchName returns the length in bytes of the first of names that s starts
+// with, ignoring case and followed by a word boundary, or 0 if there is none.
+func matchName(s string, names []string) int {
+	for _, name := range names {
+		n, ok := hasPrefixFold(s, name)
+		if !ok {
+			continue
+		}
+		if next, _ := utf8.DecodeRuneInString(s[n:]); n < len(s) && isWordRune(next) {
+			continue
+		}
+		return n
+	}
+	return 0
+}
+
+// hasPrefixFold reports whether s starts with prefix under Unicode case folding,
+// and how many bytes of s the match covers.
+func hasPrefixFold(s, prefix string) (int, bool) {
+	n := 0
+	for _, want := range prefix {
+		if n >= len(s) {
+			return 0, false
+		}
+		got, size := utf8.DecodeRuneInString(s[n:])
+		if got != want && unicode.ToLower(got) != unicode.ToLower(want) {
+			return 0, false
+		}
+		n += size
+	}
+	return n, true
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,42 @@
+package wikilink
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		names   []string
+		want    []Mention
+	}{
+		{"no names", "Go here", nil, nil},
+		{"blank names", "Go here", []string{"", "  "}, nil},
+		{"none", "plain text", []string{"Go"}, nil},
+		{"name", "I like Go.", []string{"Go"}, []Mention{{Start: 7, End: 9}}},
+		{"name trimmed", "I like Go.", []string{" Go "}, []Mention{{Start: 7, End: 9}}},
+		{"case-insensitive", "go GO gO", []string{"Go"}, []Mention{{Start: 0, End: 2}, {Start: 3, End: 5}, {Start: 6, End: 8}}},
+		{"whole words only", "Gopher ago go_lang Go2 Go", []string{"Go"}, []Mention{{Start: 23, End: 25}}},
+		{"punctuation is a boundary", "(Go), Go!", []string{"Go"}, []Mention{{Start: 1, End: 3}, {Start: 6, End: 8}}},
+		{"longest first", "Go Modules and Go", []string{"Go", "Go Modules"}, []Mention{{Start: 0, End: 10}, {Start: 15, End: 17}}},
+		{"several names", "Rust and Go", []string{"Go", "Rust"}, []Mention{{Start: 0, End: 4}, {Start: 9, End: 11}}},
+		{"multi-byte", "über Thé, thé", []string{"Thé"}, []Mention{{Start: 6, End: 10}, {Start: 12, End: 16}}},
+		{"multi-byte word boundary", "éGo Goé Go", []string{"Go"}, []Mention{{Start: 10, End: 12}}},
+		{"links are skipped", "[[Go]] and [[Rust|Go]] Go", []string{"Go"}, []Mention{{Start: 23, End: 25}}},
+		{"unclosed link ends the line", "[[Go and Go\nGo", []string{"Go"}, []Mention{{Start: 12, End: 14}}},
+		{"code spans are skipped", "`Go` ``a ` Go`` Go", []string{"Go"}, []Mention{{Start: 16, End: 18}}},
+		{"fences are skipped", "```\nGo\n```\nGo", []string{"Go"}, []Mention{{Start: 11, End: 13}}},
+		{"fence needs its own marker to close", "~~~\n```\nGo\n~~~\nGo", []string{"Go"}, []Mention{{Start: 15, End: 17}}},
+		{"unclosed fence", "```\nGo", []string{"Go"}, nil},
+		{"later line", "one\nGo", []string{"Go"}, []Mention{{Start: 4, End: 6}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mentions(tt.content, tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mentions(%q, %q) = %+v, want %+v", tt.content, tt.names, got, tt.want)
+			}
+		})
+	}
+}
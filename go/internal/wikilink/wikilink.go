@@ -0,0 +1,146 @@
+package wikilink
+
+import (
+	"strings"
+)
+
+// Ref is a single wiki-link reference found in Markdown content.
+// Exactly one of Title and ID is set.
+type Ref struct {
+	Title string // Referenced entry title, for [[Title]] and [[Title|alias]]
+	ID    string // Referenced entry ID, for [[id:<uuid>]]
+	Alias string // Display text after '|', if any
+	Start int    // Byte offset of the opening "[["
+	End   int    // Byte offset just past the closing "]]"
+}
+
+// Parse returns every wiki-link in content, in order of appearance.
+// Links inside fenced code blocks and inline code spans are ignored,
+// as are links to a heading within a note ([[Title#Heading]] refers to Title).
+func Parse(content string) []Ref {
+	var refs []Ref
+	inFence := false
+	fence := ""
+
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+			} else if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		refs = append(refs, parseLine(line, lineStart)...)
+	}
+	return refs
+}
+
+// fenceMarker returns the ``` or ~~~ run that opens a fenced code block, if line starts with one.
+func fenceMarker(line string) string {
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == c {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+func parseLine(line string, lineStart int) []Ref {
+	var refs []Ref
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == '`':
+			i = skipCodeSpan(line, i)
+
+		case strings.HasPrefix(line[i:], "[["):
+			end := strings.Index(line[i+2:], "]]")
+			if end < 0 {
+				return refs
+			}
+			inner := line[i+2 : i+2+end]
+			if ref, ok := parseInner(inner); ok {
+				ref.Start = lineStart + i
+				ref.End = lineStart + i + 2 + end + 2
+				refs = append(refs, ref)
+				i += 2 + end + 2
+				continue
+			}
+			i += 2
+
+		default:
+			i++
+		}
+	}
+	return refs
+}
+
+// skipCodeSpan returns the offset just past the inline code span opening at
+// line[i]: a backtick run up to the next run of equal length. If there is no
+// closing run, only the opening backticks are skipped.
+func skipCodeSpan(line string, i int) int {
+	n := 1
+	for i+n < len(line) && line[i+n] == '`' {
+		n++
+	}
+	closing := strings.Index(line[i+n:], line[i:i+n])
+	if closing < 0 {
+		return i + n
+	}
+	return i + n + closing + n
+}
+
+// parseInner parses the text between "[[" and "]]".
+func parseInner(inner string) (Ref, bool) {
+	if strings.Contains(inner, "[[") {
+		return Ref{}, false
+	}
+
+	target, alias, _ := strings.Cut(inner, "|")
+	target = strings.TrimSpace(target)
+	alias = strings.TrimSpace(alias)
+
+	if id, ok := strings.CutPrefix(target, "id:"); ok {
+		id = strings.TrimSpace(id)
+		return Ref{ID: id, Alias: alias}, id != ""
+	}
+
+	if title, _, ok := strings.Cut(target, "#"); ok {
+		target = strings.TrimSpace(title)
+	}
+	return Ref{Title: target, Alias: alias}, target != ""
+}
+
+// Rewrite returns content with each wiki-link replaced by replace's result.
+// Links for which replace returns false are left as they are.
+func Rewrite(content string, replace func(Ref) (string, bool)) string {
+	var buf strings.Builder
+	last := 0
+	for _, ref := range Parse(content) {
+		text, ok := replace(ref)
+		if !ok {
+			continue
+		}
+		buf.WriteString(content[last:ref.Start])
+		buf.WriteString(text)
+		last = ref.End
+	}
+	if last == 0 {
+		return content
+	}
+	buf.WriteString(content[last:])
+	return buf.String()
+}
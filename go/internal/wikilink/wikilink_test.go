@@ -0,0 +1,86 @@
+package wikilink
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Ref
+	}{
+		{"none", "plain text", nil},
+		{"title", "see [[Go]]", []Ref{{Title: "Go", Start: 4, End: 10}}},
+		{"spaces trimmed", "[[  Go  ]]", []Ref{{Title: "Go", Start: 0, End: 10}}},
+		{"alias", "[[Kubernetes|k8s]]", []Ref{{Title: "Kubernetes", Alias: "k8s", Start: 0, End: 18}}},
+		{"alias trimmed", "[[Go | the language ]]", []Ref{{Title: "Go", Alias: "the language", Start: 0, End: 22}}},
+		{"empty alias", "[[Go|]]", []Ref{{Title: "Go", Start: 0, End: 7}}},
+		{"only first pipe splits", "[[Go|a|b]]", []Ref{{Title: "Go", Alias: "a|b", Start: 0, End: 10}}},
+		{"id", "[[id:0190a1b2]]", []Ref{{ID: "0190a1b2", Start: 0, End: 15}}},
+		{"id with alias", "[[id: 0190a1b2 |Go]]", []Ref{{ID: "0190a1b2", Alias: "Go", Start: 0, End: 20}}},
+		{"empty id", "[[id:]] [[id: ]]", nil},
+		{"heading", "[[Go#Syntax]]", []Ref{{Title: "Go", Start: 0, End: 13}}},
+		{"heading with alias", "[[Go#Syntax|syntax]]", []Ref{{Title: "Go", Alias: "syntax", Start: 0, End: 20}}},
+		{"heading only", "[[#Syntax]]", nil},
+		{"empty", "[[]] [[ ]] [[|x]]", nil},
+		{"unclosed", "[[Go and more", nil},
+		{"nested opening", "[[a [[b]]", []Ref{{Title: "b", Start: 4, End: 9}}},
+		{"several", "[[A]] and [[B]]", []Ref{{Title: "A", Start: 0, End: 5}, {Title: "B", Start: 10, End: 15}}},
+		{"later line", "one\n[[A]]", []Ref{{Title: "A", Start: 4, End: 9}}},
+		{"multi-byte offsets", "café [[Thé]]", []Ref{{Title: "Thé", Start: 6, End: 14}}},
+		{"inline code", "`[[A]]` [[B]]", []Ref{{Title: "B", Start: 8, End: 13}}},
+		{"double backtick code", "``a ` [[A]]`` [[B]]", []Ref{{Title: "B", Start: 14, End: 19}}},
+		{"unclosed backtick", "` [[A]]", []Ref{{Title: "A", Start: 2, End: 7}}},
+		{"fenced code", "```go\n[[A]]\n```\n[[B]]", []Ref{{Title: "B", Start: 16, End: 21}}},
+		{"tilde fence", "~~~\n[[A]]\n~~~\n[[B]]", []Ref{{Title: "B", Start: 14, End: 19}}},
+		{"indented fence", "  ```\n[[A]]\n  ```\n[[B]]", []Ref{{Title: "B", Start: 18, End: 23}}},
+		{"shorter run does not close", "````\n```\n[[A]]\n````\n[[B]]", []Ref{{Title: "B", Start: 20, End: 25}}},
+		{"other marker does not close", "```\n~~~\n[[A]]\n```\n[[B]]", []Ref{{Title: "B", Start: 18, End: 23}}},
+		{"unclosed fence", "```\n[[A]]", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	// unlinkGo turns links to Go into their display text, as deleting Go does.
+	unlinkGo := func(ref Ref) (string, bool) {
+		if ref.Title != "Go" {
+			return "", false
+		}
+		if ref.Alias != "" {
+			return ref.Alias, true
+		}
+		return ref.Title, true
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no links", "plain text", "plain text"},
+		{"no match", "see [[Rust]]", "see [[Rust]]"},
+		{"title", "see [[Go]].", "see Go."},
+		{"alias", "see [[Go|golang]].", "see golang."},
+		{"heading", "see [[Go#Syntax]]", "see Go"},
+		{"whole content", "[[Go]]", "Go"},
+		{"several", "[[Go]], [[Rust]] and [[Go|it]]", "Go, [[Rust]] and it"},
+		{"code is left alone", "`[[Go]]` [[Go]]\n```\n[[Go]]\n```", "`[[Go]]` Go\n```\n[[Go]]\n```"},
+		{"multi-byte text around", "été [[Go]] ünd", "été Go ünd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rewrite(tt.content, unlinkGo); got != tt.want {
+				t.Errorf("Rewrite(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,19 +1,33 @@
 import {createConnectTransport} from '@connectrpc/connect-web';
-import {createClient} from '@connectrpc/connect';
+import {createClient, Interceptor} from '@connectrpc/connect';
 import {
     CreateEntryRequestSchema,
-    EntryService,
-    GrowthStage
+    EntryService
 } from '../genproto/protobuf/entry/entry_pb';
+import {GrowthStage} from '../genproto/protobuf/common/common_pb';
+import {UserService} from '../genproto/protobuf/user/user_pb';
 import {create} from "@bufbuild/protobuf";
 
+export const AUTH_TOKEN_KEY = 'moss.authToken';
+
+// Attach the stored bearer token to every request.
+const authInterceptor: Interceptor = (next) => async (req) => {
+  const token = localStorage.getItem(AUTH_TOKEN_KEY);
+  if (token) {
+    req.header.set('Authorization', `Bearer ${token}`);
+  }
+  return next(req);
+};
+
 const transport = createConnectTransport({
   baseUrl: process.env.NODE_ENV === 'production'
     ? (process.env.REACT_APP_API_URL || 'https://your-api.com')
     : 'http://localhost:8080',
+  interceptors: [authInterceptor],
 });
 
 export const entryClient = createClient(EntryService, transport);
+export const userClient = createClient(UserService, transport);
 
 // Simple health check using create entry
 // export const healthCheck = async (): Promise<boolean> => {
@@ -1,7 +1,11 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { create } from '@bufbuild/protobuf';
 import { entryClient } from '../api/client';
-import { CreateEntryRequestSchema, GrowthStage } from '../genproto/protobuf/entry/entry_pb';
+import { CreateEntryRequestSchema, SearchResult } from '../genproto/protobuf/entry/entry_pb';
+import { GrowthStage } from '../genproto/protobuf/common/common_pb';
+
+// How long to wait after the last keystroke before searching for similar entries.
+const SEARCH_DEBOUNCE_MS = 250;
 
 interface EntryFormProps {
   onEntryCreated?: (entryId: string) => void;
@@ -17,6 +21,33 @@ const EntryForm: React.FC<EntryFormProps> = ({ onEntryCreated }) => {
   const [isSubmitting, setIsSubmitting] = useState(false);
   const [error, setError] = useState<string>('');
   const [success, setSuccess] = useState<string>('');
+  const [similar, setSimilar] = useState<SearchResult[]>([]);
+
+  // Show existing entries matching the title as it is typed.
+  useEffect(() => {
+    const query = formData.title.trim();
+    if (!query) {
+      setSimilar([]);
+      return;
+    }
+
+    let cancelled = false;
+    const timer = setTimeout(async () => {
+      try {
+        const response = await entryClient.searchEntries({ query, pageSize: 5 });
+        if (!cancelled) {
+          setSimilar(response.results);
+        }
+      } catch (error) {
+        console.error('Failed to search entries:', error);
+      }
+    }, SEARCH_DEBOUNCE_MS);
+
+    return () => {
+      cancelled = true;
+      clearTimeout(timer);
+    };
+  }, [formData.title]);
 
   const handleInputChange = (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement | HTMLSelectElement>) => {
     const { name, value } = e.target;
@@ -104,6 +135,19 @@ const EntryForm: React.FC<EntryFormProps> = ({ onEntryCreated }) => {
             required
             placeholder="Enter entry title"
           />
+          {similar.length > 0 && (
+            <ul className="similar-entries">
+              {similar.map(result => (
+                <li key={result.entry?.id}>
+                  {/* Highlights are escaped HTML with <mark> around the matches. */}
+                  <span dangerouslySetInnerHTML={{ __html: result.titleHighlight }} />
+                  {result.snippet && (
+                    <small dangerouslySetInnerHTML={{ __html: result.snippet }} />
+                  )}
+                </li>
+              ))}
+            </ul>
+          )}
         </div>
 
         <div className="form-group">